@@ -0,0 +1,39 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import "runtime"
+
+// PinThread locks the calling goroutine to its operating system
+// thread and, on platforms that support it, restricts the thread to
+// the specified CPU, reducing the noise introduced by the scheduler
+// migrating a microbenchmark between threads and CPUs.  The returned
+// function undoes the pinning, restoring the thread's original CPU
+// affinity, and must be called from the same goroutine.  If the
+// platform does not support CPU affinity, or the affinity cannot be
+// set, the goroutine remains locked to its thread, and the error is
+// returned along with the function; an error wrapping ErrUnsupported
+// may safely be ignored.
+func PinThread(cpu int) (unpin func(), err error) {
+	runtime.LockOSThread()
+
+	restore, err := setAffinity(cpu)
+	return func() {
+		if restore != nil {
+			restore()
+		}
+		runtime.UnlockOSThread()
+	}, err
+}
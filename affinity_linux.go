@@ -0,0 +1,62 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build linux
+// +build linux
+
+package timeit
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// cpuMask is a CPU affinity mask, large enough for 1024 CPUs.
+type cpuMask [16]uint64
+
+// schedAffinity gets or sets the CPU affinity mask of the calling
+// thread.
+func schedAffinity(trap uintptr, mask *cpuMask) error {
+	_, _, errno := syscall.RawSyscall(trap, 0, unsafe.Sizeof(*mask), uintptr(unsafe.Pointer(mask))) //nolint:gosec
+	if errno != 0 {
+		return errno
+	}
+
+	return nil
+}
+
+// setAffinity restricts the calling thread to the specified CPU,
+// returning a function that restores the original affinity.
+func setAffinity(cpu int) (func(), error) {
+	mask := &cpuMask{}
+	if cpu < 0 || cpu >= len(mask)*64 {
+		return nil, syscall.EINVAL
+	}
+
+	// Save the original affinity
+	old := &cpuMask{}
+	if err := schedAffinity(syscall.SYS_SCHED_GETAFFINITY, old); err != nil {
+		return nil, err
+	}
+
+	// Set the new affinity
+	mask[cpu/64] = 1 << (uint(cpu) % 64)
+	if err := schedAffinity(syscall.SYS_SCHED_SETAFFINITY, mask); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		_ = schedAffinity(syscall.SYS_SCHED_SETAFFINITY, old)
+	}, nil
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPinThreadBase(t *testing.T) {
+	before := &cpuMask{}
+	require.NoError(t, schedAffinity(syscall.SYS_SCHED_GETAFFINITY, before))
+
+	unpin, err := PinThread(0)
+	during := &cpuMask{}
+	duringErr := schedAffinity(syscall.SYS_SCHED_GETAFFINITY, during)
+	unpin()
+
+	assert.NoError(t, err)
+	assert.NoError(t, duringErr)
+	assert.Equal(t, &cpuMask{1}, during)
+	after := &cpuMask{}
+	require.NoError(t, schedAffinity(syscall.SYS_SCHED_GETAFFINITY, after))
+	assert.Equal(t, before, after)
+}
+
+func TestPinThreadOutOfRange(t *testing.T) {
+	unpin, err := PinThread(-1)
+	unpin()
+
+	assert.Equal(t, syscall.EINVAL, err)
+}
+
+func TestSetAffinityBadCPU(t *testing.T) {
+	restore, err := setAffinity(1023)
+
+	if err == nil {
+		restore()
+		t.Skip("CPU 1023 exists")
+	}
+	assert.Nil(t, restore)
+	assert.Equal(t, syscall.EINVAL, err)
+}
+
+func TestSchedAffinityError(t *testing.T) {
+	err := schedAffinity(syscall.SYS_SCHED_SETAFFINITY, &cpuMask{})
+
+	assert.Equal(t, syscall.EINVAL, err)
+}
+
+func TestSetPriority(t *testing.T) {
+	// On Linux, getpriority returns 20 minus the nice value
+	prio, err := syscall.Getpriority(syscall.PRIO_PROCESS, 0)
+	require.NoError(t, err)
+
+	err = SetPriority(20 - prio)
+
+	assert.NoError(t, err)
+}
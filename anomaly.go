@@ -0,0 +1,162 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// Default settings for Detector.
+const (
+	DefaultDetectorWindow    = 100 // Default size of the rolling window
+	DefaultDetectorThreshold = 3.0 // Default z-score threshold
+)
+
+// Anomaly describes a sample that a Detector found to deviate
+// significantly from recent behavior.
+type Anomaly struct {
+	Sample time.Duration // The anomalous sample
+	Mean   time.Duration // Mean of the rolling window
+	StdDev time.Duration // Standard deviation of the rolling window
+	Score  float64       // The z-score of the sample
+}
+
+// AnomalyFunc is the type of an observer callback invoked by a
+// Detector when an anomalous sample is detected.
+type AnomalyFunc func(a Anomaly)
+
+// Detector is a lightweight latency anomaly detector.  Each sample is
+// compared against the mean and standard deviation of a rolling
+// window of the most recent samples, and samples whose z-score
+// exceeds the threshold are reported to the observers.  Panics in
+// the observers are recovered and counted, and passed to OnPanic if
+// it is set.  A Detector may optionally pass each sample on to a
+// Data.
+type Detector struct {
+	Window    int           // Size of the rolling window; 0 for default
+	Threshold float64       // z-score threshold; 0 for default
+	Observers []AnomalyFunc // Callbacks to invoke on anomalies
+	OnPanic   PanicFunc     // Called with recovered panics; may be nil
+	Data      *Data         // Data to update with each sample; may be nil
+	panics    int64
+	samples   []time.Duration
+	next      int
+	mean      float64
+	m2        float64
+}
+
+// Panics returns the number of panics recovered from the observers.
+func (det *Detector) Panics() int64 {
+	return atomic.LoadInt64(&det.panics)
+}
+
+// window returns the size of the rolling window.
+func (det *Detector) window() int {
+	if det.Window <= 0 {
+		return DefaultDetectorWindow
+	}
+
+	return det.Window
+}
+
+// threshold returns the z-score threshold.
+func (det *Detector) threshold() float64 {
+	if det.Threshold <= 0 {
+		return DefaultDetectorThreshold
+	}
+
+	return det.Threshold
+}
+
+// check computes the z-score of the sample against the rolling
+// window.  It returns an Anomaly and true if the sample is anomalous.
+// No detection is performed until the window is full.
+func (det *Detector) check(sample time.Duration) (Anomaly, bool) {
+	n := float64(len(det.samples))
+	if len(det.samples) < det.window() {
+		return Anomaly{}, false
+	}
+
+	// Compute the window statistics
+	variance := det.m2 / n
+	if variance <= 0 {
+		return Anomaly{}, false
+	}
+	stdDev := math.Sqrt(variance)
+
+	// Compute the z-score
+	score := (float64(sample) - det.mean) / stdDev
+	if math.Abs(score) < det.threshold() {
+		return Anomaly{}, false
+	}
+
+	return Anomaly{
+		Sample: sample,
+		Mean:   time.Duration(det.mean),
+		StdDev: time.Duration(stdDev),
+		Score:  score,
+	}, true
+}
+
+// add adds a sample to the rolling window.  The mean and sum of
+// squared differences of the window are maintained with a windowed
+// variant of Welford's algorithm, which avoids the loss of precision
+// of maintaining a sum of squares; since rounding errors can still
+// accumulate, the sum of squared differences is clamped at 0.
+func (det *Detector) add(sample time.Duration) {
+	value := float64(sample)
+
+	// Grow the window if it's not full yet
+	if len(det.samples) < det.window() {
+		det.samples = append(det.samples, sample)
+		delta := value - det.mean
+		det.mean += delta / float64(len(det.samples))
+		det.m2 += delta * (value - det.mean)
+		return
+	}
+
+	// Replace the oldest sample
+	old := float64(det.samples[det.next])
+	det.samples[det.next] = sample
+	det.next = (det.next + 1) % len(det.samples)
+	oldMean := det.mean
+	det.mean += (value - old) / float64(len(det.samples))
+	det.m2 += (value - old) * (value - det.mean + old - oldMean)
+	if det.m2 < 0 {
+		det.m2 = 0
+	}
+}
+
+// Update checks a sample against the rolling window, invoking the
+// observers if it is anomalous, then adds the sample to the window
+// and to the Data, if one is set.  Returns true if the sample was
+// anomalous.
+func (det *Detector) Update(sample time.Duration) bool {
+	a, anomalous := det.check(sample)
+	if anomalous {
+		for _, obs := range det.Observers {
+			callObserver(func() { obs(a) }, &det.panics, det.OnPanic)
+		}
+	}
+
+	det.add(sample)
+	if det.Data != nil {
+		det.Data.Update(sample)
+	}
+
+	return anomalous
+}
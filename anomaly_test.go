@@ -0,0 +1,261 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectorWindowDefault(t *testing.T) {
+	det := &Detector{}
+
+	result := det.window()
+
+	assert.Equal(t, DefaultDetectorWindow, result)
+}
+
+func TestDetectorWindowSet(t *testing.T) {
+	det := &Detector{Window: 5}
+
+	result := det.window()
+
+	assert.Equal(t, 5, result)
+}
+
+func TestDetectorThresholdDefault(t *testing.T) {
+	det := &Detector{}
+
+	result := det.threshold()
+
+	assert.Equal(t, DefaultDetectorThreshold, result)
+}
+
+func TestDetectorThresholdSet(t *testing.T) {
+	det := &Detector{Threshold: 2.0}
+
+	result := det.threshold()
+
+	assert.Equal(t, 2.0, result)
+}
+
+func TestDetectorCheckWindowNotFull(t *testing.T) {
+	det := &Detector{
+		Window:  4,
+		samples: []time.Duration{10, 20, 10},
+		mean:    40.0 / 3,
+		m2:      200.0 / 3,
+	}
+
+	_, ok := det.check(1000)
+
+	assert.False(t, ok)
+}
+
+func TestDetectorCheckNoVariance(t *testing.T) {
+	det := &Detector{
+		Window:  2,
+		samples: []time.Duration{10, 10},
+		mean:    10,
+		m2:      0,
+	}
+
+	_, ok := det.check(1000)
+
+	assert.False(t, ok)
+}
+
+func TestDetectorCheckNormal(t *testing.T) {
+	det := &Detector{
+		Window:  4,
+		samples: []time.Duration{10, 20, 10, 20},
+		mean:    15,
+		m2:      100,
+	}
+
+	_, ok := det.check(25)
+
+	assert.False(t, ok)
+}
+
+func TestDetectorCheckAnomalous(t *testing.T) {
+	det := &Detector{
+		Window:  4,
+		samples: []time.Duration{10, 20, 10, 20},
+		mean:    15,
+		m2:      100,
+	}
+
+	result, ok := det.check(45)
+
+	assert.True(t, ok)
+	assert.Equal(t, Anomaly{
+		Sample: 45,
+		Mean:   15,
+		StdDev: 5,
+		Score:  6.0,
+	}, result)
+}
+
+func TestDetectorCheckAnomalousLow(t *testing.T) {
+	det := &Detector{
+		Window:  4,
+		samples: []time.Duration{10, 20, 10, 20},
+		mean:    15,
+		m2:      100,
+	}
+
+	result, ok := det.check(0)
+
+	assert.True(t, ok)
+	assert.Equal(t, -3.0, result.Score)
+}
+
+func TestDetectorAddGrow(t *testing.T) {
+	det := &Detector{
+		Window:  4,
+		samples: []time.Duration{10},
+		mean:    10,
+		m2:      0,
+	}
+
+	det.add(20)
+
+	assert.Equal(t, &Detector{
+		Window:  4,
+		samples: []time.Duration{10, 20},
+		mean:    15,
+		m2:      50,
+	}, det)
+}
+
+func TestDetectorAddReplace(t *testing.T) {
+	det := &Detector{
+		Window:  2,
+		samples: []time.Duration{10, 20},
+		next:    1,
+		mean:    15,
+		m2:      50,
+	}
+
+	det.add(30)
+	det.add(40)
+
+	assert.Equal(t, &Detector{
+		Window:  2,
+		samples: []time.Duration{40, 30},
+		next:    1,
+		mean:    35,
+		m2:      50,
+	}, det)
+}
+
+func TestDetectorAddClamp(t *testing.T) {
+	det := &Detector{
+		Window:  2,
+		samples: []time.Duration{10, 20},
+		mean:    15,
+	}
+
+	det.add(20)
+
+	assert.Equal(t, &Detector{
+		Window:  2,
+		samples: []time.Duration{20, 20},
+		next:    1,
+		mean:    20,
+		m2:      0,
+	}, det)
+}
+
+func TestDetectorAddLargeSamples(t *testing.T) {
+	det := &Detector{Window: 2}
+	for i := 0; i < 1000; i++ {
+		det.add(time.Hour)
+		det.add(time.Hour + 2)
+	}
+
+	assert.InDelta(t, float64(time.Hour+1), det.mean, 0.01)
+	assert.InDelta(t, 2.0, det.m2, 0.01)
+}
+
+func TestDetectorUpdateNormal(t *testing.T) {
+	var anomalies []Anomaly
+	det := &Detector{
+		Window: 4,
+		Observers: []AnomalyFunc{func(a Anomaly) {
+			anomalies = append(anomalies, a)
+		}},
+	}
+
+	for _, sample := range []time.Duration{10, 20, 10, 20, 25} {
+		assert.False(t, det.Update(sample))
+	}
+
+	assert.Nil(t, anomalies)
+	assert.Len(t, det.samples, 4)
+}
+
+func TestDetectorUpdateAnomalous(t *testing.T) {
+	if !Enabled() {
+		t.Skip("Update discards samples in noop builds")
+	}
+	var anomalies []Anomaly
+	d := &Data{}
+	det := &Detector{
+		Window: 4,
+		Observers: []AnomalyFunc{func(a Anomaly) {
+			anomalies = append(anomalies, a)
+		}},
+		Data: d,
+	}
+	for _, sample := range []time.Duration{10, 20, 10, 20} {
+		det.Update(sample)
+	}
+
+	result := det.Update(45)
+
+	assert.True(t, result)
+	assert.Equal(t, []Anomaly{
+		{Sample: 45, Mean: 15, StdDev: 5, Score: 6.0},
+	}, anomalies)
+	assert.Equal(t, int64(5), d.Samples)
+	assert.Equal(t, time.Duration(45), d.Max)
+}
+
+func TestDetectorUpdateObserverPanic(t *testing.T) {
+	var recovered []interface{}
+	called := false
+	det := &Detector{
+		Window: 4,
+		Observers: []AnomalyFunc{
+			func(a Anomaly) { panic("oops") },
+			func(a Anomaly) { called = true },
+		},
+		OnPanic: func(v interface{}) { recovered = append(recovered, v) },
+	}
+	for _, sample := range []time.Duration{10, 20, 10, 20} {
+		det.Update(sample)
+	}
+
+	result := det.Update(45)
+
+	assert.True(t, result)
+	assert.True(t, called)
+	assert.Equal(t, []interface{}{"oops"}, recovered)
+	assert.Equal(t, int64(1), det.Panics())
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// benchmarkName converts a name into a benchmark name acceptable to
+// the Go benchmark format: it must begin with "Benchmark" followed by
+// a character that is not a lower-case letter, and it must not
+// contain any white space.
+func benchmarkName(name string) string {
+	// Replace white space
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return '_'
+		}
+		return r
+	}, name)
+
+	// Add the prefix and capitalize
+	name = strings.TrimPrefix(name, "Benchmark")
+	if name == "" {
+		return "Benchmark"
+	}
+	r, size := utf8.DecodeRuneInString(name)
+
+	return "Benchmark" + string(unicode.ToUpper(r)) + name[size:]
+}
+
+// validConfigKey tests whether a key is valid for a configuration
+// line in the Go benchmark format: it must begin with a lower-case
+// letter and must not contain upper-case letters, white space, or a
+// colon.
+func validConfigKey(key string) bool {
+	r, _ := utf8.DecodeRuneInString(key)
+	if !unicode.IsLower(r) {
+		return false
+	}
+
+	return strings.IndexFunc(key, func(r rune) bool {
+		return unicode.IsUpper(r) || unicode.IsSpace(r) || r == ':'
+	}) < 0
+}
+
+// WriteBenchmarkConfig writes configuration lines, such as "commit:
+// 1234abcd", to the writer in the Go benchmark format, sorted by key.
+// These lines annotate the benchmark results that follow them, and
+// allow results to be correlated with the build and machine that
+// produced them by tools such as benchstat and the golang.org/x/perf
+// storage format.  An error wrapping ErrBadConfigKey is returned if
+// a key is not valid; new lines in values are replaced with spaces.
+func WriteBenchmarkConfig(w io.Writer, config map[string]string) error {
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		if !validConfigKey(key) {
+			return fmt.Errorf("%w: %q", ErrBadConfigKey, key)
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := strings.ReplaceAll(config[key], "\n", " ")
+		if _, err := fmt.Fprintf(w, "%s: %s\n", key, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteBenchmark writes the data to the writer as a single result
+// line in the standard Go benchmark format, e.g., "BenchmarkName 100
+// 1234 ns/op", allowing the data to be consumed by tools such as
+// benchstat.  The name will be adjusted as required by the format.
+func (d *Data) WriteBenchmark(w io.Writer, name string) error {
+	_, err := fmt.Fprintf(w, "%s\t%d\t%d ns/op\n", benchmarkName(name), d.Samples, d.Mean.Nanoseconds())
+
+	return err
+}
+
+// WriteBenchmarks writes a set of named data to the writer in the
+// standard Go benchmark format, sorted by name.
+func WriteBenchmarks(w io.Writer, dataset map[string]*Data) error {
+	names := make([]string, 0, len(dataset))
+	for name := range dataset {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := dataset[name].WriteBenchmark(w, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBenchmarkName(t *testing.T) {
+	assert.Equal(t, "BenchmarkDb.query", benchmarkName("db.query"))
+	assert.Equal(t, "BenchmarkQuery", benchmarkName("BenchmarkQuery"))
+	assert.Equal(t, "BenchmarkSlow_query", benchmarkName("slow query"))
+	assert.Equal(t, "Benchmark/sub", benchmarkName("/sub"))
+	assert.Equal(t, "Benchmark", benchmarkName(""))
+}
+
+func TestDataWriteBenchmarkBase(t *testing.T) {
+	d := &Data{
+		Samples: 100,
+		Mean:    1234 * time.Nanosecond,
+	}
+	buf := &bytes.Buffer{}
+
+	err := d.WriteBenchmark(buf, "query")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "BenchmarkQuery\t100\t1234 ns/op\n", buf.String())
+}
+
+func TestDataWriteBenchmarkError(t *testing.T) {
+	d := &Data{}
+
+	err := d.WriteBenchmark(errWriter{}, "query")
+
+	assert.Same(t, assert.AnError, err)
+}
+
+func TestWriteBenchmarksBase(t *testing.T) {
+	dataset := map[string]*Data{
+		"b": {Samples: 10, Mean: 20},
+		"a": {Samples: 30, Mean: 40},
+	}
+	buf := &bytes.Buffer{}
+
+	err := WriteBenchmarks(buf, dataset)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "BenchmarkA\t30\t40 ns/op\nBenchmarkB\t10\t20 ns/op\n", buf.String())
+}
+
+func TestWriteBenchmarksError(t *testing.T) {
+	dataset := map[string]*Data{
+		"a": {Samples: 30, Mean: 40},
+	}
+
+	err := WriteBenchmarks(errWriter{}, dataset)
+
+	assert.Same(t, assert.AnError, err)
+}
+
+func TestValidConfigKey(t *testing.T) {
+	assert.True(t, validConfigKey("commit"))
+	assert.True(t, validConfigKey("go-version"))
+	assert.True(t, validConfigKey("cpu.model"))
+	assert.False(t, validConfigKey(""))
+	assert.False(t, validConfigKey("Commit"))
+	assert.False(t, validConfigKey("1commit"))
+	assert.False(t, validConfigKey("commitSHA"))
+	assert.False(t, validConfigKey("git sha"))
+	assert.False(t, validConfigKey("git:sha"))
+}
+
+func TestWriteBenchmarkConfigBase(t *testing.T) {
+	config := map[string]string{
+		"goos":   "linux",
+		"commit": "1234abcd",
+		"note":   "multi\nline",
+	}
+	buf := &bytes.Buffer{}
+
+	err := WriteBenchmarkConfig(buf, config)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "commit: 1234abcd\ngoos: linux\nnote: multi line\n", buf.String())
+}
+
+func TestWriteBenchmarkConfigBadKey(t *testing.T) {
+	config := map[string]string{
+		"Commit": "1234abcd",
+	}
+	buf := &bytes.Buffer{}
+
+	err := WriteBenchmarkConfig(buf, config)
+
+	assert.ErrorIs(t, err, ErrBadConfigKey)
+	assert.Equal(t, "", buf.String())
+}
+
+func TestWriteBenchmarkConfigWriteError(t *testing.T) {
+	config := map[string]string{
+		"commit": "1234abcd",
+	}
+
+	err := WriteBenchmarkConfig(errWriter{}, config)
+
+	assert.Same(t, assert.AnError, err)
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"math"
+	"time"
+)
+
+// niceSteps are the mantissas of the human-aligned bounds generated
+// by NiceBounds.
+var niceSteps = []time.Duration{1, 2, 5}
+
+// DefaultBounds is a set of latency bounds, from 100µs to 10s,
+// suitable for most request latencies; see NiceBounds.
+var DefaultBounds = NiceBounds(100*time.Microsecond, 10*time.Second)
+
+// NiceBounds generates human-aligned latency bounds, following the
+// 1-2-5 sequence (e.g., 1ms, 2ms, 5ms, 10ms, ...), suitable for use
+// as the Bounds of a Heatmap.  The bounds start with the largest
+// value in the sequence not greater than the minimum, and end with
+// the smallest value not less than the maximum, so that the range is
+// covered, as far as a time.Duration allows.  A minimum of less than
+// 1ns is treated as 1ns.
+func NiceBounds(min, max time.Duration) []time.Duration {
+	if min < 1 {
+		min = 1
+	}
+
+	// Find the largest power of 10 not greater than the minimum
+	decade := time.Duration(1)
+	for decade <= min/10 {
+		decade *= 10
+	}
+
+	// Generate the bounds, discarding all but the last bound not
+	// greater than the minimum
+	result := []time.Duration{}
+	for {
+		for _, step := range niceSteps {
+			bound := decade * step
+			if bound <= min {
+				result = result[:0]
+			}
+			result = append(result, bound)
+			if bound >= max && bound >= min {
+				return result
+			}
+		}
+		if decade > math.MaxInt64/10 {
+			return result
+		}
+		decade *= 10
+	}
+}
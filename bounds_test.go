@@ -0,0 +1,72 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNiceBoundsBase(t *testing.T) {
+	result := NiceBounds(3*time.Millisecond, 150*time.Millisecond)
+
+	assert.Equal(t, []time.Duration{
+		2 * time.Millisecond,
+		5 * time.Millisecond,
+		10 * time.Millisecond,
+		20 * time.Millisecond,
+		50 * time.Millisecond,
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+	}, result)
+}
+
+func TestNiceBoundsAligned(t *testing.T) {
+	result := NiceBounds(time.Millisecond, 10*time.Millisecond)
+
+	assert.Equal(t, []time.Duration{
+		time.Millisecond,
+		2 * time.Millisecond,
+		5 * time.Millisecond,
+		10 * time.Millisecond,
+	}, result)
+}
+
+func TestNiceBoundsZeroMin(t *testing.T) {
+	result := NiceBounds(0, 4)
+
+	assert.Equal(t, []time.Duration{1, 2, 5}, result)
+}
+
+func TestNiceBoundsMaxBelowMin(t *testing.T) {
+	result := NiceBounds(50, 10)
+
+	assert.Equal(t, []time.Duration{50}, result)
+}
+
+func TestNiceBoundsHuge(t *testing.T) {
+	result := NiceBounds(1e18, math.MaxInt64)
+
+	assert.Equal(t, []time.Duration{1e18, 2e18, 5e18}, result)
+}
+
+func TestDefaultBounds(t *testing.T) {
+	assert.Len(t, DefaultBounds, 16)
+	assert.Equal(t, 100*time.Microsecond, DefaultBounds[0])
+	assert.Equal(t, 10*time.Second, DefaultBounds[len(DefaultBounds)-1])
+}
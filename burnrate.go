@@ -0,0 +1,149 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// BurnWindow describes a pair of windows over which the burn rate of
+// an error budget is evaluated, following the multi-window burn-rate
+// alerting practice: an alert fires only if the burn rate over both
+// the long and short windows reaches the threshold, so that alerts
+// fire quickly for severe problems and reset quickly once they are
+// resolved.
+type BurnWindow struct {
+	Long      time.Duration // The long window, e.g., 1 hour
+	Short     time.Duration // The short window, e.g., 5 minutes
+	Threshold float64       // Burn rate at which to alert
+}
+
+// DefaultBurnWindows are the burn windows used by a BurnAlerter with
+// no Windows set.  These are the conventional windows for a 30 day
+// budget: a fast burn, consuming 2% of the budget in an hour, and a
+// slow burn, consuming 5% of the budget in 6 hours.
+var DefaultBurnWindows = []BurnWindow{
+	{Long: time.Hour, Short: 5 * time.Minute, Threshold: 14.4},
+	{Long: 6 * time.Hour, Short: 30 * time.Minute, Threshold: 6},
+}
+
+// BurnRate computes the rate at which the samples, such as those
+// returned by Data.Retained, consume the objective's error budget
+// over the window ending at the specified time.  A burn rate of 1
+// consumes the budget exactly; higher rates exhaust it early.
+// Returns 0 if there are no samples in the window, and positive
+// infinity if the objective permits no slow samples and there are
+// some.
+func (o Objective) BurnRate(samples []Sample, now time.Time, window time.Duration) float64 {
+	start := now.Add(-window)
+	total, over := 0, 0
+	for _, s := range samples {
+		if s.At.Before(start) || s.At.After(now) {
+			continue
+		}
+		total++
+		if s.Duration > o.Threshold {
+			over++
+		}
+	}
+	if over == 0 {
+		return 0
+	}
+
+	allowed := (100 - o.Percentile) / 100
+	if allowed <= 0 {
+		return math.Inf(1)
+	}
+
+	return float64(over) / float64(total) / allowed
+}
+
+// BurnAlert describes a burn window whose threshold was reached.
+type BurnAlert struct {
+	Window    BurnWindow // The burn window
+	LongRate  float64    // Burn rate over the long window
+	ShortRate float64    // Burn rate over the short window
+}
+
+// BurnFunc is the type of an observer callback invoked by a
+// BurnAlerter when a burn window's threshold is reached.
+type BurnFunc func(a BurnAlert)
+
+// BurnAlerter checks the burn rate of an objective's error budget
+// over several burn windows, invoking the observers for each window
+// whose threshold is reached.  As with Detector, panics in the
+// observers are recovered and counted, and passed to OnPanic if it
+// is set.  The samples checked are typically those retained by a
+// Data (see Retained); the retention buffer must be large enough to
+// cover the longest window.
+type BurnAlerter struct {
+	Objective Objective    // The objective whose budget is burned
+	Windows   []BurnWindow // Burn windows; nil for DefaultBurnWindows
+	Observers []BurnFunc   // Callbacks to invoke on alerts
+	OnPanic   PanicFunc    // Called with recovered panics; may be nil
+	Clock     Clock        // Source of time; nil for WallClock
+	panics    int64
+}
+
+// Panics returns the number of panics recovered from the observers.
+func (ba *BurnAlerter) Panics() int64 {
+	return atomic.LoadInt64(&ba.panics)
+}
+
+// clock returns the Clock to use for the BurnAlerter.
+func (ba *BurnAlerter) clock() Clock {
+	if ba.Clock == nil {
+		return WallClock
+	}
+
+	return ba.Clock
+}
+
+// windows returns the burn windows to check.
+func (ba *BurnAlerter) windows() []BurnWindow {
+	if ba.Windows == nil {
+		return DefaultBurnWindows
+	}
+
+	return ba.Windows
+}
+
+// Check checks the burn rates of the samples over each burn window
+// ending at the current time, invoking the observers for each window
+// whose threshold is reached.  Returns the alerts.
+func (ba *BurnAlerter) Check(samples []Sample) []BurnAlert {
+	now := ba.clock().Now()
+
+	result := []BurnAlert{}
+	for _, w := range ba.windows() {
+		a := BurnAlert{
+			Window:    w,
+			LongRate:  ba.Objective.BurnRate(samples, now, w.Long),
+			ShortRate: ba.Objective.BurnRate(samples, now, w.Short),
+		}
+		if a.LongRate < w.Threshold || a.ShortRate < w.Threshold {
+			continue
+		}
+
+		for _, obs := range ba.Observers {
+			callObserver(func() { obs(a) }, &ba.panics, ba.OnPanic)
+		}
+		result = append(result, a)
+	}
+
+	return result
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// burnSamples returns one sample per minute for the specified number
+// of minutes before the time; the samples in the last slow minutes
+// are slow.
+func burnSamples(now time.Time, minutes, slow int) []Sample {
+	result := []Sample{}
+	for i := minutes - 1; i >= 0; i-- {
+		s := Sample{At: now.Add(-time.Duration(i) * time.Minute), Duration: 10}
+		if i < slow {
+			s.Duration = 1000
+		}
+		result = append(result, s)
+	}
+
+	return result
+}
+
+func TestObjectiveBurnRateBase(t *testing.T) {
+	now := time.Unix(100000, 0)
+	samples := append(burnSamples(now, 10, 1), Sample{At: now.Add(time.Minute), Duration: 1000})
+	obj := Objective{Percentile: 90, Threshold: 100}
+
+	result := obj.BurnRate(samples, now, 10*time.Minute)
+
+	assert.InDelta(t, 1.0, result, 1e-9)
+}
+
+func TestObjectiveBurnRateNoSlow(t *testing.T) {
+	now := time.Unix(100000, 0)
+	obj := Objective{Percentile: 90, Threshold: 100}
+
+	result := obj.BurnRate(burnSamples(now, 10, 0), now, 10*time.Minute)
+
+	assert.Equal(t, 0.0, result)
+}
+
+func TestObjectiveBurnRateEmpty(t *testing.T) {
+	obj := Objective{Percentile: 90, Threshold: 100}
+
+	result := obj.BurnRate(nil, time.Unix(100000, 0), time.Hour)
+
+	assert.Equal(t, 0.0, result)
+}
+
+func TestObjectiveBurnRateNoBudget(t *testing.T) {
+	now := time.Unix(100000, 0)
+	obj := Objective{Percentile: 100, Threshold: 100}
+
+	result := obj.BurnRate(burnSamples(now, 10, 1), now, 10*time.Minute)
+
+	assert.True(t, math.IsInf(result, 1))
+}
+
+func TestBurnAlerterClockDefault(t *testing.T) {
+	ba := &BurnAlerter{}
+
+	assert.Equal(t, WallClock, ba.clock())
+}
+
+func TestBurnAlerterWindowsDefault(t *testing.T) {
+	ba := &BurnAlerter{}
+
+	assert.Equal(t, DefaultBurnWindows, ba.windows())
+}
+
+func TestBurnAlerterCheckAlert(t *testing.T) {
+	now := time.Unix(100000, 0)
+	alerts := []BurnAlert{}
+	ba := &BurnAlerter{
+		Objective: Objective{Percentile: 99, Threshold: 100},
+		Observers: []BurnFunc{func(a BurnAlert) { alerts = append(alerts, a) }},
+		Clock:     &testClock{now: now},
+	}
+
+	result := ba.Check(burnSamples(now, 60, 10))
+
+	assert.Len(t, result, 2)
+	assert.Equal(t, result, alerts)
+	assert.Equal(t, DefaultBurnWindows[0], result[0].Window)
+	assert.InDelta(t, 10.0/60/0.01, result[0].LongRate, 1e-9)
+	assert.InDelta(t, 100.0, result[0].ShortRate, 1e-9)
+	assert.Equal(t, DefaultBurnWindows[1], result[1].Window)
+	assert.InDelta(t, 10.0/60/0.01, result[1].LongRate, 1e-9)
+	assert.InDelta(t, 10.0/31/0.01, result[1].ShortRate, 1e-9)
+}
+
+func TestBurnAlerterCheckShortRecovered(t *testing.T) {
+	now := time.Unix(100000, 0)
+	samples := burnSamples(now.Add(-10*time.Minute), 50, 20)
+	samples = append(samples, burnSamples(now, 10, 0)...)
+	ba := &BurnAlerter{
+		Objective: Objective{Percentile: 99, Threshold: 100},
+		Windows:   []BurnWindow{{Long: time.Hour, Short: 5 * time.Minute, Threshold: 14.4}},
+		Observers: []BurnFunc{func(a BurnAlert) { t.Error("unexpected alert") }},
+		Clock:     &testClock{now: now},
+	}
+
+	result := ba.Check(samples)
+
+	assert.Equal(t, []BurnAlert{}, result)
+}
+
+func TestBurnAlerterCheckObserverPanic(t *testing.T) {
+	now := time.Unix(100000, 0)
+	called := 0
+	ba := &BurnAlerter{
+		Objective: Objective{Percentile: 99, Threshold: 100},
+		Observers: []BurnFunc{
+			func(a BurnAlert) { panic("oops") },
+			func(a BurnAlert) { called++ },
+		},
+		Clock: &testClock{now: now},
+	}
+
+	result := ba.Check(burnSamples(now, 60, 10))
+
+	assert.Len(t, result, 2)
+	assert.Equal(t, 2, called)
+	assert.Equal(t, int64(2), ba.Panics())
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Default settings for ChangeDetector.
+const (
+	DefaultChangeMinSegment = 10  // Default minimum samples per segment
+	DefaultChangeThreshold  = 5.0 // Default minimum t statistic
+)
+
+// ChangePoint describes a step change in the latency of a series of
+// samples.  The mean and 99th percentile are those of the segments
+// on either side of the change, which extend to the neighboring
+// change points.  If the ChangeDetector was given Events, the event
+// nearest to the change, from among those occurring during the
+// segments on either side, is identified as its likely cause.
+type ChangePoint struct {
+	At         time.Time     // Time of the first sample after the change
+	Index      int           // Index of the first sample after the change
+	MeanBefore time.Duration // Mean of the preceding segment
+	MeanAfter  time.Duration // Mean of the following segment
+	P99Before  time.Duration // 99th percentile of the preceding segment
+	P99After   time.Duration // 99th percentile of the following segment
+	T          float64       // Welch's t statistic of the change
+	Event      *Event        // Likely cause of the change; may be nil
+}
+
+// ChangeDetector locates step changes in the latency of timestamped
+// samples, such as those returned by Data.Retained or replayed from a
+// SampleFile, helping to identify which deployment or configuration
+// change shifted performance.  Change points are found by binary
+// segmentation: the series is split where the difference between the
+// means of the two parts is most significant, as measured by Welch's
+// t statistic, and each part is split again in turn, until no split
+// reaches the threshold.
+type ChangeDetector struct {
+	MinSegment int     // Minimum samples per segment; 0 for default
+	Threshold  float64 // Minimum t statistic to report; 0 for default
+	Events     []Event // Events that may explain changes; may be nil
+}
+
+// minSegment returns the minimum number of samples per segment.  At
+// least two samples are needed to estimate the variance.
+func (cd *ChangeDetector) minSegment() int {
+	switch {
+	case cd.MinSegment <= 0:
+		return DefaultChangeMinSegment
+	case cd.MinSegment < 2:
+		return 2
+	}
+
+	return cd.MinSegment
+}
+
+// threshold returns the t statistic threshold.
+func (cd *ChangeDetector) threshold() float64 {
+	if cd.Threshold <= 0 {
+		return DefaultChangeThreshold
+	}
+
+	return cd.Threshold
+}
+
+// split finds the most significant split of the series, returning
+// the index of the first element after the split and the t
+// statistic, or -1 if the series is too short to split.
+func (cd *ChangeDetector) split(xs []float64) (int, float64) {
+	n := len(xs)
+	minSeg := cd.minSegment()
+	if n < 2*minSeg {
+		return -1, 0
+	}
+
+	// Work with deviations from the mean to preserve precision
+	shift, total, totalSq := 0.0, 0.0, 0.0
+	for _, x := range xs {
+		shift += x
+	}
+	shift /= float64(n)
+	for _, x := range xs {
+		total += x - shift
+		totalSq += (x - shift) * (x - shift)
+	}
+
+	best, bestT := -1, 0.0
+	sum, sumSq := 0.0, 0.0
+	for k := 1; k < n; k++ {
+		dev := xs[k-1] - shift
+		sum += dev
+		sumSq += dev * dev
+		if k < minSeg || n-k < minSeg {
+			continue
+		}
+
+		n1, n2 := float64(k), float64(n-k)
+		mean1, mean2 := sum/n1, (total-sum)/n2
+		var1 := (sumSq - sum*sum/n1) / (n1 - 1)
+		var2 := ((totalSq - sumSq) - (total-sum)*(total-sum)/n2) / (n2 - 1)
+		se := math.Sqrt(math.Max(var1, 0)/n1 + math.Max(var2, 0)/n2)
+
+		t := 0.0
+		switch {
+		case se > 0:
+			t = math.Abs(mean2-mean1) / se
+		case mean1 != mean2:
+			t = math.Inf(1)
+		}
+		if best < 0 || t > bestT {
+			best, bestT = k, t
+		}
+	}
+
+	return best, bestT
+}
+
+// segment recursively splits the series, appending the indexes of
+// the change points, in order, to the result.
+func (cd *ChangeDetector) segment(xs []float64, offset int, result []int, ts map[int]float64) []int {
+	k, t := cd.split(xs)
+	if k < 0 || t < cd.threshold() {
+		return result
+	}
+
+	result = cd.segment(xs[:k], offset, result, ts)
+	result = append(result, offset+k)
+	ts[offset+k] = t
+
+	return cd.segment(xs[k:], offset+k, result, ts)
+}
+
+// segmentStats returns the mean and 99th percentile of the samples.
+func segmentStats(samples []Sample) (mean, p99 time.Duration) {
+	d := &Data{}
+	durations := make([]time.Duration, len(samples))
+	for i, s := range samples {
+		d.updateSample(Sample{Duration: s.Duration})
+		durations[i] = s.Duration
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	return d.Mean, durations[rank(99, len(durations))]
+}
+
+// ChangePoints returns the change points found in the samples, which
+// must be in time order.
+func (cd *ChangeDetector) ChangePoints(samples []Sample) []ChangePoint {
+	xs := make([]float64, len(samples))
+	for i, s := range samples {
+		xs[i] = float64(s.Duration)
+	}
+	ts := map[int]float64{}
+	idxs := cd.segment(xs, 0, nil, ts)
+
+	// Describe the segments on either side of each change point
+	result := make([]ChangePoint, len(idxs))
+	bounds := append(append([]int{0}, idxs...), len(samples))
+	for i, idx := range idxs {
+		cp := ChangePoint{At: samples[idx].At, Index: idx, T: ts[idx]}
+		cp.MeanBefore, cp.P99Before = segmentStats(samples[bounds[i]:idx])
+		cp.MeanAfter, cp.P99After = segmentStats(samples[idx:bounds[i+2]])
+		cp.Event = nearestEvent(cd.Events, cp.At, samples[bounds[i]].At, samples[bounds[i+2]-1].At)
+		result[i] = cp
+	}
+
+	return result
+}
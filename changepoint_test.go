@@ -0,0 +1,162 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stepSamples(means ...time.Duration) []Sample {
+	g := &Generator{Seed: 42, Start: time.Unix(1000, 0), Interval: time.Second}
+	result := []Sample{}
+	for _, mean := range means {
+		g.Dist = NormalDist{Mean: mean, StdDev: time.Millisecond}
+		result = append(result, g.Samples(100)...)
+	}
+
+	return result
+}
+
+func TestChangeDetectorMinSegmentDefault(t *testing.T) {
+	cd := &ChangeDetector{}
+
+	assert.Equal(t, DefaultChangeMinSegment, cd.minSegment())
+}
+
+func TestChangeDetectorMinSegmentSmall(t *testing.T) {
+	cd := &ChangeDetector{MinSegment: 1}
+
+	assert.Equal(t, 2, cd.minSegment())
+}
+
+func TestChangeDetectorMinSegmentSet(t *testing.T) {
+	cd := &ChangeDetector{MinSegment: 5}
+
+	assert.Equal(t, 5, cd.minSegment())
+}
+
+func TestChangeDetectorThresholdDefault(t *testing.T) {
+	cd := &ChangeDetector{}
+
+	assert.Equal(t, DefaultChangeThreshold, cd.threshold())
+}
+
+func TestChangeDetectorThresholdSet(t *testing.T) {
+	cd := &ChangeDetector{Threshold: 3}
+
+	assert.Equal(t, 3.0, cd.threshold())
+}
+
+func TestChangeDetectorSplitShort(t *testing.T) {
+	cd := &ChangeDetector{MinSegment: 2}
+
+	k, tstat := cd.split([]float64{1, 2, 3})
+
+	assert.Equal(t, -1, k)
+	assert.Equal(t, 0.0, tstat)
+}
+
+func TestChangeDetectorSplitConstant(t *testing.T) {
+	cd := &ChangeDetector{MinSegment: 2}
+
+	k, tstat := cd.split([]float64{1, 1, 1, 5, 5, 5})
+
+	assert.Equal(t, 3, k)
+	assert.True(t, math.IsInf(tstat, 1))
+}
+
+func TestChangeDetectorSplitFlat(t *testing.T) {
+	cd := &ChangeDetector{MinSegment: 2}
+
+	_, tstat := cd.split([]float64{1, 1, 1, 1})
+
+	assert.Equal(t, 0.0, tstat)
+}
+
+func TestChangeDetectorChangePointsStep(t *testing.T) {
+	samples := stepSamples(10*time.Millisecond, 20*time.Millisecond)
+	cd := &ChangeDetector{}
+
+	result := cd.ChangePoints(samples)
+
+	require.Len(t, result, 1)
+	assert.Equal(t, 100, result[0].Index)
+	assert.Equal(t, time.Unix(1100, 0), result[0].At)
+	assert.InDelta(t, float64(10*time.Millisecond), float64(result[0].MeanBefore), float64(time.Millisecond/2))
+	assert.InDelta(t, float64(20*time.Millisecond), float64(result[0].MeanAfter), float64(time.Millisecond/2))
+	assert.Greater(t, result[0].P99Before, result[0].MeanBefore)
+	assert.Greater(t, result[0].P99After, result[0].MeanAfter)
+	assert.Greater(t, result[0].T, DefaultChangeThreshold)
+}
+
+func TestChangeDetectorChangePointsMultiple(t *testing.T) {
+	samples := stepSamples(10*time.Millisecond, 20*time.Millisecond, 10*time.Millisecond)
+	cd := &ChangeDetector{}
+
+	result := cd.ChangePoints(samples)
+
+	require.Len(t, result, 2)
+	assert.Equal(t, 100, result[0].Index)
+	assert.Equal(t, 200, result[1].Index)
+	assert.Equal(t, result[0].MeanAfter, result[1].MeanBefore)
+	assert.InDelta(t, float64(10*time.Millisecond), float64(result[1].MeanAfter), float64(time.Millisecond/2))
+}
+
+func TestChangeDetectorChangePointsEvents(t *testing.T) {
+	samples := stepSamples(10*time.Millisecond, 20*time.Millisecond)
+	cd := &ChangeDetector{Events: []Event{
+		{At: time.Unix(900, 0), Name: "too early"},
+		{At: time.Unix(1050, 0), Name: "config change"},
+		{At: time.Unix(1098, 0), Name: "deploy"},
+		{At: time.Unix(1300, 0), Name: "too late"},
+	}}
+
+	result := cd.ChangePoints(samples)
+
+	require.Len(t, result, 1)
+	require.NotNil(t, result[0].Event)
+	assert.Equal(t, "deploy", result[0].Event.Name)
+}
+
+func TestChangeDetectorChangePointsNone(t *testing.T) {
+	samples := stepSamples(10*time.Millisecond, 10*time.Millisecond)
+	cd := &ChangeDetector{}
+
+	result := cd.ChangePoints(samples)
+
+	assert.Empty(t, result)
+}
+
+func TestChangeDetectorChangePointsEmpty(t *testing.T) {
+	cd := &ChangeDetector{}
+
+	result := cd.ChangePoints(nil)
+
+	assert.Empty(t, result)
+}
+
+func TestSegmentStats(t *testing.T) {
+	samples := []Sample{{Duration: 30}, {Duration: 10}, {Duration: 20}}
+
+	mean, p99 := segmentStats(samples)
+
+	assert.Equal(t, time.Duration(20), mean)
+	assert.Equal(t, time.Duration(30), p99)
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"sync"
+	"time"
+)
+
+// Classifier routes samples to a separate Data for each category, as
+// determined by a classification function, keeping the segmentation
+// logic, e.g., by tenant tier or payload size class, out of the call
+// sites.  The classification function is passed the sample, which
+// includes any tag, such as a request type, given by the caller.  The Data
+// for each category is created the first time the category is seen.
+// A Classifier may be shared by multiple goroutines; updates to the
+// Data are serialized by an internal mutex.
+type Classifier struct {
+	Classify func(s Sample) string       // Returns the category of a sample
+	New      func(category string) *Data // Creates the Data for a category; nil for an empty Data
+	Clock    Clock                       // Source of time; nil for WallClock
+	mu       sync.Mutex
+	data     map[string]*Data
+}
+
+// clock returns the Clock to use for the Classifier.
+func (c *Classifier) clock() Clock {
+	if c.Clock == nil {
+		return WallClock
+	}
+
+	return c.Clock
+}
+
+// update routes the sample to the Data for its category.
+func (c *Classifier) update(s Sample) {
+	if !Enabled() {
+		return
+	}
+
+	category := c.Classify(s)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.data == nil {
+		c.data = map[string]*Data{}
+	}
+	d, ok := c.data[category]
+	if !ok {
+		if c.New != nil {
+			d = c.New(category)
+		} else {
+			d = &Data{}
+		}
+		c.data[category] = d
+	}
+	d.updateSample(s)
+}
+
+// Update routes a sample to the Data for its category.
+func (c *Classifier) Update(sample time.Duration) {
+	c.update(Sample{Duration: sample})
+}
+
+// UpdateTagged routes a sample, with an opaque tag that is passed to
+// the classification function, to the Data for its category.
+func (c *Classifier) UpdateTagged(tag interface{}, sample time.Duration) {
+	c.update(Sample{Duration: sample, Tag: tag})
+}
+
+// TimeItTagged runs a function and routes the time it took to the
+// Data for its category, as determined from the tag and the time.
+// It returns the time it took for the function to execute.
+func (c *Classifier) TimeItTagged(tag interface{}, fn func()) (delta time.Duration) {
+	if !Enabled() {
+		fn()
+		return 0
+	}
+
+	// Get the current time and arrange to update the data
+	clock := c.clock()
+	start := clock.Now()
+	defer func() {
+		delta = elapsed(clock, start)
+		c.update(Sample{At: start, Duration: delta, Tag: tag})
+	}()
+
+	// Invoke the function
+	fn()
+
+	return
+}
+
+// Dataset returns copies of the Data for each category seen so far,
+// keyed by category; see Data.Clone.  It is suitable for use as the
+// Source of a Reporter.
+func (c *Classifier) Dataset() map[string]*Data {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := make(map[string]*Data, len(c.data))
+	for category, d := range c.data {
+		result[category] = d.Clone()
+	}
+
+	return result
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build !timeitnoop
+// +build !timeitnoop
+
+package timeit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func bySize(s Sample) string {
+	if s.Duration > 100 {
+		return "slow"
+	}
+
+	return "fast"
+}
+
+func TestClassifierClockDefault(t *testing.T) {
+	c := &Classifier{}
+
+	assert.Equal(t, WallClock, c.clock())
+}
+
+func TestClassifierUpdate(t *testing.T) {
+	c := &Classifier{Classify: bySize}
+
+	for _, s := range []time.Duration{50, 150, 70, 250} {
+		c.Update(s)
+	}
+
+	dataset := c.Dataset()
+	require.Len(t, dataset, 2)
+	assert.Equal(t, int64(2), dataset["fast"].Samples)
+	assert.Equal(t, time.Duration(60), dataset["fast"].Mean)
+	assert.Equal(t, int64(2), dataset["slow"].Samples)
+	assert.Equal(t, time.Duration(200), dataset["slow"].Mean)
+}
+
+func TestClassifierUpdateTagged(t *testing.T) {
+	c := &Classifier{
+		Classify: func(s Sample) string { return s.Tag.(string) },
+	}
+
+	c.UpdateTagged("gold", 10)
+	c.UpdateTagged("silver", 20)
+	c.UpdateTagged("gold", 30)
+
+	dataset := c.Dataset()
+	require.Len(t, dataset, 2)
+	assert.Equal(t, int64(2), dataset["gold"].Samples)
+	assert.Equal(t, int64(1), dataset["silver"].Samples)
+}
+
+func TestClassifierNew(t *testing.T) {
+	created := []string{}
+	c := &Classifier{
+		Classify: bySize,
+		New: func(category string) *Data {
+			created = append(created, category)
+			return &Data{Retain: 10}
+		},
+	}
+
+	c.Update(50)
+	c.Update(60)
+
+	assert.Equal(t, []string{"fast"}, created)
+	assert.Len(t, c.data["fast"].Retained(), 2)
+}
+
+func TestClassifierTimeItTagged(t *testing.T) {
+	var seen Sample
+	c := &Classifier{
+		Classify: func(s Sample) string {
+			seen = s
+			return "a"
+		},
+		Clock: &testClock{now: time.Unix(1000, 0), delta: 5},
+	}
+
+	result := c.TimeItTagged("req1", func() {})
+
+	assert.Equal(t, time.Duration(5), result)
+	assert.Equal(t, Sample{At: time.Unix(1000, 0), Duration: 5, Tag: "req1"}, seen)
+	assert.Equal(t, int64(1), c.Dataset()["a"].Samples)
+}
+
+func TestClassifierDatasetCopies(t *testing.T) {
+	c := &Classifier{Classify: bySize}
+	c.Update(50)
+
+	c.Dataset()["fast"].Update(1000)
+
+	assert.Equal(t, int64(1), c.Dataset()["fast"].Samples)
+}
+
+func TestClassifierDatasetConfig(t *testing.T) {
+	c := &Classifier{
+		Classify: bySize,
+		New: func(category string) *Data {
+			return &Data{Retain: 10, Flags: SampleStdDev}
+		},
+	}
+	c.Update(50)
+	c.Update(60)
+
+	d := c.Dataset()["fast"]
+
+	assert.Equal(t, SampleStdDev, d.Flags)
+	assert.Equal(t, 10, d.Retain)
+	assert.Len(t, d.Retained(), 2)
+}
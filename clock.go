@@ -0,0 +1,140 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Clock describes a source of time.  A Clock may be set on a Data,
+// or passed to Data.TimeItWith, to control how elapsed time is
+// measured; this allows simulations and replay tools to feed virtual
+// time through the same code paths used in production.  Elapsed
+// times are always computed with Since, or by subtracting times
+// returned by Now, so a Clock should return times carrying a
+// monotonic clock reading, as time.Now does, to be immune to steps
+// of the wall clock.  Should a Clock nevertheless report a negative
+// elapsed time, it is counted (see SkewCorrections) and recorded as
+// is, so that the InvalidPolicy of the Data decides how to handle
+// it; functions such as TimeIt return the negative time.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// Since returns the time elapsed since the specified time.
+	Since(t time.Time) time.Duration
+}
+
+// WallClock is the default Clock, which uses the system clock.  The
+// times it returns carry the monotonic clock reading, so elapsed
+// times are not affected by changes to the wall clock.
+var WallClock Clock = wallClock{}
+
+// skewCorrections is the number of negative elapsed times that have
+// been observed.
+var skewCorrections int64
+
+// SkewCorrections returns the number of negative elapsed times, such
+// as may be produced by a Clock without a monotonic clock reading
+// when the wall clock is stepped backwards, that have been observed.
+// Negative elapsed times recorded as samples are handled by the
+// InvalidPolicy of the Data and counted in its InvalidSamples; those
+// used internally, e.g., by a RateLimiter, are replaced with 0.  A
+// nonzero value indicates that some timing data was affected by
+// clock skew.
+func SkewCorrections() int64 {
+	return atomic.LoadInt64(&skewCorrections)
+}
+
+// skewed counts the elapsed time if it is negative, returning it
+// unchanged so that it may be handled by the InvalidPolicy of the
+// Data it is recorded in.
+func skewed(delta time.Duration) time.Duration {
+	if delta < 0 {
+		atomic.AddInt64(&skewCorrections, 1)
+	}
+
+	return delta
+}
+
+// corrected returns the elapsed time, or 0 if it is negative, in
+// which case the correction is counted.  It is used where the elapsed
+// time is not recorded as a sample.
+func corrected(delta time.Duration) time.Duration {
+	if skewed(delta) < 0 {
+		return 0
+	}
+
+	return delta
+}
+
+// elapsed returns the time elapsed since the start time according to
+// the clock, counting negative elapsed times.
+func elapsed(clock Clock, start time.Time) time.Duration {
+	return skewed(clock.Since(start))
+}
+
+// wallClock is an implementation of Clock that uses the system clock.
+type wallClock struct{}
+
+// Now returns the current time.
+func (wallClock) Now() time.Time {
+	return time.Now()
+}
+
+// Since returns the time elapsed since the specified time.
+func (wallClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+// FakeClock is a deterministic Clock intended for use in tests and
+// examples, so that output containing timing data is reproducible.
+// Each call to Now, including the call implied by Since, advances the
+// clock by Step plus a pseudo-random jitter less than Jitter; the
+// jitter is drawn from a sequence seeded by Seed, so it is the same
+// on every run.  A FakeClock is safe for concurrent use.
+type FakeClock struct {
+	Time   time.Time     // The current time of the clock
+	Step   time.Duration // Amount to advance the clock on each call
+	Jitter time.Duration // Bound on additional random advance
+	Seed   int64         // Seed for the jitter sequence
+	mu     sync.Mutex
+	rng    *rand.Rand
+}
+
+// Now returns the current time, then advances the clock.
+func (fc *FakeClock) Now() time.Time {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	now := fc.Time
+	fc.Time = fc.Time.Add(fc.Step)
+	if fc.Jitter > 0 {
+		if fc.rng == nil {
+			fc.rng = rand.New(rand.NewSource(fc.Seed)) //nolint:gosec
+		}
+		fc.Time = fc.Time.Add(time.Duration(fc.rng.Int63n(int64(fc.Jitter))))
+	}
+
+	return now
+}
+
+// Since returns the time elapsed since the specified time.
+func (fc *FakeClock) Since(t time.Time) time.Duration {
+	return fc.Now().Sub(t)
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testClock struct {
+	now   time.Time
+	delta time.Duration
+}
+
+func (tc *testClock) Now() time.Time {
+	return tc.now
+}
+
+func (tc *testClock) Since(t time.Time) time.Duration {
+	return tc.now.Add(tc.delta).Sub(t)
+}
+
+type stepClock struct {
+	now  time.Time
+	step time.Duration
+}
+
+func (sc *stepClock) Now() time.Time {
+	now := sc.now
+	sc.now = sc.now.Add(sc.step)
+	return now
+}
+
+func (sc *stepClock) Since(t time.Time) time.Duration {
+	return sc.Now().Sub(t)
+}
+
+func TestWallClockImplementsClock(t *testing.T) {
+	assert.Implements(t, (*Clock)(nil), WallClock)
+}
+
+func TestWallClockNow(t *testing.T) {
+	before := time.Now()
+
+	result := WallClock.Now()
+
+	assert.False(t, result.Before(before))
+	assert.False(t, result.After(time.Now()))
+}
+
+func TestWallClockSince(t *testing.T) {
+	start := time.Now()
+	time.Sleep(10 * time.Millisecond)
+
+	result := WallClock.Since(start)
+
+	assert.GreaterOrEqual(t, int64(result), int64(10*time.Millisecond))
+}
+
+func TestFakeClockImplementsClock(t *testing.T) {
+	assert.Implements(t, (*Clock)(nil), &FakeClock{})
+}
+
+func TestFakeClockNow(t *testing.T) {
+	fc := &FakeClock{
+		Time: time.Unix(1000, 0),
+		Step: time.Second,
+	}
+
+	assert.Equal(t, time.Unix(1000, 0), fc.Now())
+	assert.Equal(t, time.Unix(1001, 0), fc.Now())
+	assert.Equal(t, time.Unix(1002, 0), fc.Time)
+}
+
+func TestFakeClockNowJitter(t *testing.T) {
+	fc1 := &FakeClock{
+		Time:   time.Unix(1000, 0),
+		Step:   time.Second,
+		Jitter: time.Second,
+		Seed:   42,
+	}
+	fc2 := &FakeClock{
+		Time:   time.Unix(1000, 0),
+		Step:   time.Second,
+		Jitter: time.Second,
+		Seed:   42,
+	}
+
+	for i := 0; i < 5; i++ {
+		t1 := fc1.Now()
+		t2 := fc2.Now()
+		assert.Equal(t, t1, t2)
+		assert.True(t, fc1.Time.Sub(t1) >= time.Second)
+		assert.True(t, fc1.Time.Sub(t1) < 2*time.Second)
+	}
+}
+
+func TestFakeClockSince(t *testing.T) {
+	fc := &FakeClock{
+		Time: time.Unix(1000, 0),
+		Step: time.Second,
+	}
+
+	result := fc.Since(time.Unix(999, 0))
+
+	assert.Equal(t, time.Second, result)
+	assert.Equal(t, time.Unix(1001, 0), fc.Time)
+}
+
+func TestSkewedBase(t *testing.T) {
+	before := SkewCorrections()
+
+	result := skewed(5)
+
+	assert.Equal(t, time.Duration(5), result)
+	assert.Equal(t, before, SkewCorrections())
+}
+
+func TestSkewedNegative(t *testing.T) {
+	before := SkewCorrections()
+
+	result := skewed(-5)
+
+	assert.Equal(t, time.Duration(-5), result)
+	assert.Equal(t, before+1, SkewCorrections())
+}
+
+func TestCorrectedBase(t *testing.T) {
+	before := SkewCorrections()
+
+	result := corrected(5)
+
+	assert.Equal(t, time.Duration(5), result)
+	assert.Equal(t, before, SkewCorrections())
+}
+
+func TestCorrectedNegative(t *testing.T) {
+	before := SkewCorrections()
+
+	result := corrected(-5)
+
+	assert.Equal(t, time.Duration(0), result)
+	assert.Equal(t, before+1, SkewCorrections())
+}
+
+func TestElapsed(t *testing.T) {
+	clock := &testClock{now: time.Unix(1000, 0), delta: 5}
+
+	result := elapsed(clock, time.Unix(1000, 0))
+
+	assert.Equal(t, time.Duration(5), result)
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os/exec"
+	"sync/atomic"
+	"time"
+)
+
+// precisionMinRuns is the number of runs Repeat makes before it
+// checks whether the mean is known to the requested Precision.
+const precisionMinRuns = 5
+
+// runCmd runs the command, measuring the time it took with the
+// specified clock.  The returned boolean is false if the command
+// could not be started, in which case no time should be recorded.
+// If the timeout is non-zero and the command runs longer than the
+// timeout, the command is killed and an error wrapping ErrTimeout is
+// returned.
+func runCmd(clock Clock, cmd *exec.Cmd, timeout time.Duration) (time.Duration, bool, error) {
+	start := clock.Now()
+	if err := cmd.Start(); err != nil {
+		return elapsed(clock, start), false, err
+	}
+
+	// Arrange to kill the command if it hangs
+	var timedOut int32
+	if timeout > 0 {
+		timer := time.AfterFunc(timeout, func() {
+			atomic.StoreInt32(&timedOut, 1)
+			_ = cmd.Process.Kill()
+		})
+		defer timer.Stop()
+	}
+
+	// Only report a timeout if the kill caused Wait to fail; a
+	// command that exits just as the timer fires is not killed
+	err := cmd.Wait()
+	delta := elapsed(clock, start)
+	if err != nil && atomic.LoadInt32(&timedOut) != 0 {
+		err = fmt.Errorf("%w: command exceeded %s", ErrTimeout, timeout)
+	}
+
+	return delta, true, err
+}
+
+// TimeCmd runs the command and updates the data with the wall clock
+// time it took to execute.  The command is timed even if it exits
+// unsuccessfully; it is not timed if it could not be started.  It
+// returns the time the command took and the error returned by
+// cmd.Run.
+func (d *Data) TimeCmd(cmd *exec.Cmd) (time.Duration, error) {
+	delta, ran, err := runCmd(d.clock(), cmd, 0)
+	if ran {
+		d.Update(delta)
+	}
+
+	return delta, err
+}
+
+// CmdData contains the accumulated timing data for executions of an
+// external command.  In addition to the wall clock time, the user and
+// system CPU time consumed by the command are collected from its
+// process state; on operating systems that do not report these, they
+// will be 0.  If a Timeout is set, commands that run longer than
+// the Timeout are killed and counted in Timeouts, rather than being
+// timed.
+//
+// Rather than always making the requested number of runs, Repeat may
+// stop early, so that a benchmark runs just long enough to produce a
+// meaningful result.  If a Precision is set, Repeat stops once the
+// approximate 95% confidence interval of the mean wall clock time is
+// within that fraction of the mean, e.g., 0.05 for 5%; this is not
+// checked until at least 5 runs are complete.  If a MaxTime is set,
+// Repeat stops once that much time has elapsed since it started.
+type CmdData struct {
+	Wall           Data          // Wall clock time taken by the command
+	User           Data          // User CPU time consumed by the command
+	System         Data          // System CPU time consumed by the command
+	Progress       ProgressFunc  // Called after each run by Repeat; may be nil
+	Timeout        time.Duration // Time limit for each run; 0 for none
+	Timeouts       int64         // Number of runs that exceeded the Timeout
+	AbortOnTimeout bool          // Set to have Repeat stop at a timeout
+	Precision      float64       // Relative precision at which Repeat stops; 0 for none
+	MaxTime        time.Duration // Total time after which Repeat stops; 0 for no limit
+}
+
+// precise returns true if the mean wall clock time is known to the
+// requested Precision.
+func (cd *CmdData) precise() bool {
+	if cd.Precision <= 0 || cd.Wall.Samples < precisionMinRuns || cd.Wall.Mean <= 0 {
+		return false
+	}
+
+	halfWidth := confidenceZ * float64(cd.Wall.SampleStdDev()) / math.Sqrt(float64(cd.Wall.Samples))
+
+	return halfWidth <= cd.Precision*float64(cd.Wall.Mean)
+}
+
+// Run runs the command and updates the data.  As with Data.TimeCmd,
+// the command is timed even if it exits unsuccessfully, and the
+// error returned by cmd.Run is returned.  If the command exceeds the
+// Timeout, an error wrapping ErrTimeout is returned.
+func (cd *CmdData) Run(cmd *exec.Cmd) error {
+	delta, ran, err := runCmd(cd.Wall.clock(), cmd, cd.Timeout)
+	if errors.Is(err, ErrTimeout) {
+		cd.Timeouts++
+		return err
+	}
+	if ran {
+		cd.Wall.Update(delta)
+		cd.User.Update(cmd.ProcessState.UserTime())
+		cd.System.Update(cmd.ProcessState.SystemTime())
+	}
+
+	return err
+}
+
+// Repeat runs a command n times, updating the data for each run.
+// Since an exec.Cmd cannot be reused, the factory is called to
+// construct the command for each run.  Repeat stops at the first
+// error, other than a timeout; runs that exceed the Timeout are
+// skipped unless AbortOnTimeout is set.  If a Progress callback is
+// set, it is called with the wall clock data after each run.  If a
+// Precision or MaxTime is set, Repeat may stop successfully before n
+// runs are made.
+func (cd *CmdData) Repeat(n int, factory func() *exec.Cmd) error {
+	return cd.RepeatContext(context.Background(), n, factory)
+}
+
+// RepeatContext is similar to Repeat, but stops cleanly if the
+// context is canceled before all runs are complete, e.g., by
+// signal.NotifyContext when a long run is interrupted.  The data
+// accumulated by the completed runs is retained and flagged as
+// Partial, and an error wrapping ErrPartial is returned.  A run in
+// progress is not interrupted unless the factory constructs the
+// command with exec.CommandContext.
+func (cd *CmdData) RepeatContext(ctx context.Context, n int, factory func() *exec.Cmd) error {
+	clock := cd.Wall.clock()
+	start := clock.Now()
+	for i := 0; i < n; i++ {
+		if err := ctx.Err(); err != nil {
+			cd.Wall.Partial = true
+			cd.User.Partial = true
+			cd.System.Partial = true
+			return fmt.Errorf("%w: %d of %d runs completed: %s", ErrPartial, i, n, err)
+		}
+
+		if err := cd.Run(factory()); err != nil && (cd.AbortOnTimeout || !errors.Is(err, ErrTimeout)) {
+			return err
+		}
+		if cd.Progress != nil {
+			cd.Progress(i+1, n, &cd.Wall)
+		}
+
+		// Stop if the result is good enough or time is up
+		if cd.precise() || (cd.MaxTime > 0 && elapsed(clock, start) >= cd.MaxTime) {
+			return nil
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,264 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build !timeitnoop
+// +build !timeitnoop
+
+package timeit
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunCmdBase(t *testing.T) {
+	clock := &testClock{delta: 50}
+
+	delta, ran, err := runCmd(clock, exec.Command("true"), 0)
+
+	assert.NoError(t, err)
+	assert.True(t, ran)
+	assert.Equal(t, time.Duration(50), delta)
+}
+
+func TestRunCmdFailed(t *testing.T) {
+	clock := &testClock{delta: 50}
+
+	_, ran, err := runCmd(clock, exec.Command("false"), 0)
+
+	assert.Error(t, err)
+	assert.True(t, ran)
+}
+
+func TestRunCmdNotStarted(t *testing.T) {
+	clock := &testClock{delta: 50}
+
+	_, ran, err := runCmd(clock, exec.Command("/nonexistent/command"), 0)
+
+	assert.Error(t, err)
+	assert.False(t, ran)
+}
+
+func TestRunCmdTimeout(t *testing.T) {
+	clock := &testClock{delta: 50}
+
+	_, ran, err := runCmd(clock, exec.Command("sleep", "10"), 50*time.Millisecond)
+
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.EqualError(t, err, "timed out: command exceeded 50ms")
+	assert.True(t, ran)
+}
+
+func TestRunCmdWithinTimeout(t *testing.T) {
+	clock := &testClock{delta: 50}
+
+	_, ran, err := runCmd(clock, exec.Command("true"), 10*time.Second)
+
+	assert.NoError(t, err)
+	assert.True(t, ran)
+}
+
+func TestDataTimeCmdBase(t *testing.T) {
+	d := &Data{Clock: &testClock{delta: 50}}
+
+	result, err := d.TimeCmd(exec.Command("true"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(50), result)
+	assert.Equal(t, int64(1), d.Samples)
+}
+
+func TestDataTimeCmdNotStarted(t *testing.T) {
+	d := &Data{}
+
+	_, err := d.TimeCmd(exec.Command("/nonexistent/command"))
+
+	assert.Error(t, err)
+	assert.Equal(t, int64(0), d.Samples)
+}
+
+func TestCmdDataRunBase(t *testing.T) {
+	cd := &CmdData{}
+
+	err := cd.Run(exec.Command("true"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), cd.Wall.Samples)
+	assert.Equal(t, int64(1), cd.User.Samples)
+	assert.Equal(t, int64(1), cd.System.Samples)
+}
+
+func TestCmdDataRunNotStarted(t *testing.T) {
+	cd := &CmdData{}
+
+	err := cd.Run(exec.Command("/nonexistent/command"))
+
+	assert.Error(t, err)
+	assert.Equal(t, &CmdData{}, cd)
+}
+
+func TestCmdDataRepeatBase(t *testing.T) {
+	cd := &CmdData{}
+
+	err := cd.Repeat(3, func() *exec.Cmd { return exec.Command("true") })
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), cd.Wall.Samples)
+	assert.False(t, cd.Wall.Partial)
+}
+
+func TestCmdDataRepeatProgress(t *testing.T) {
+	calls := [][2]int{}
+	cd := &CmdData{}
+	cd.Progress = func(done, total int, d *Data) {
+		assert.Same(t, &cd.Wall, d)
+		assert.Equal(t, int64(done), d.Samples)
+		calls = append(calls, [2]int{done, total})
+	}
+
+	err := cd.Repeat(3, func() *exec.Cmd { return exec.Command("true") })
+
+	assert.NoError(t, err)
+	assert.Equal(t, [][2]int{{1, 3}, {2, 3}, {3, 3}}, calls)
+}
+
+func TestCmdDataRepeatError(t *testing.T) {
+	cd := &CmdData{}
+	count := 0
+
+	err := cd.Repeat(3, func() *exec.Cmd {
+		count++
+		return exec.Command("false")
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, int64(1), cd.Wall.Samples)
+}
+
+func TestCmdDataRepeatContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cd := &CmdData{}
+	cd.Progress = func(done, total int, d *Data) {
+		if done == 2 {
+			cancel()
+		}
+	}
+
+	err := cd.RepeatContext(ctx, 5, func() *exec.Cmd { return exec.Command("true") })
+
+	assert.ErrorIs(t, err, ErrPartial)
+	assert.EqualError(t, err, "measurement incomplete: 2 of 5 runs completed: context canceled")
+	assert.Equal(t, int64(2), cd.Wall.Samples)
+	assert.True(t, cd.Wall.Partial)
+	assert.True(t, cd.User.Partial)
+	assert.True(t, cd.System.Partial)
+}
+
+func TestCmdDataPreciseUnset(t *testing.T) {
+	cd := &CmdData{Wall: Data{Samples: 10, Mean: 100}}
+
+	assert.False(t, cd.precise())
+}
+
+func TestCmdDataPreciseTooFewRuns(t *testing.T) {
+	cd := &CmdData{Wall: Data{Samples: 4, Mean: 100}, Precision: 0.05}
+
+	assert.False(t, cd.precise())
+}
+
+func TestCmdDataPreciseWithin(t *testing.T) {
+	// Standard deviation of 10 over 16 runs gives a half-width of 4.9
+	cd := &CmdData{Wall: Data{Samples: 16, Mean: 100, m2: 1500}, Precision: 0.05}
+
+	assert.True(t, cd.precise())
+}
+
+func TestCmdDataPreciseOutside(t *testing.T) {
+	// Standard deviation of 20 over 16 runs gives a half-width of 9.8
+	cd := &CmdData{Wall: Data{Samples: 16, Mean: 100, m2: 6000}, Precision: 0.05}
+
+	assert.False(t, cd.precise())
+}
+
+func TestCmdDataRepeatPrecision(t *testing.T) {
+	cd := &CmdData{
+		Wall:      Data{Clock: &testClock{now: time.Unix(1000, 0), delta: time.Millisecond}},
+		Precision: 0.05,
+	}
+
+	err := cd.Repeat(20, func() *exec.Cmd { return exec.Command("true") })
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(precisionMinRuns), cd.Wall.Samples)
+}
+
+func TestCmdDataRepeatMaxTime(t *testing.T) {
+	cd := &CmdData{
+		Wall:    Data{Clock: &stepClock{now: time.Unix(1000, 0), step: time.Second}},
+		MaxTime: 5 * time.Second,
+	}
+
+	err := cd.Repeat(20, func() *exec.Cmd { return exec.Command("true") })
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), cd.Wall.Samples)
+}
+
+func TestCmdDataRunTimeout(t *testing.T) {
+	cd := &CmdData{Timeout: 50 * time.Millisecond}
+
+	err := cd.Run(exec.Command("sleep", "10"))
+
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.Equal(t, int64(1), cd.Timeouts)
+	assert.Equal(t, int64(0), cd.Wall.Samples)
+}
+
+func TestCmdDataRepeatTimeoutContinue(t *testing.T) {
+	cd := &CmdData{Timeout: 50 * time.Millisecond}
+	count := 0
+
+	err := cd.Repeat(3, func() *exec.Cmd {
+		count++
+		if count == 2 {
+			return exec.Command("sleep", "10")
+		}
+		return exec.Command("true")
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.Equal(t, int64(1), cd.Timeouts)
+	assert.Equal(t, int64(2), cd.Wall.Samples)
+}
+
+func TestCmdDataRepeatTimeoutAbort(t *testing.T) {
+	cd := &CmdData{Timeout: 50 * time.Millisecond, AbortOnTimeout: true}
+	count := 0
+
+	err := cd.Repeat(3, func() *exec.Cmd {
+		count++
+		return exec.Command("sleep", "10")
+	})
+
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, int64(1), cd.Timeouts)
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"io"
+	"math"
+	"os"
+	"time"
+)
+
+// ANSI escape sequences used to color terminal output.
+const (
+	ansiReset   = "\x1b[0m"
+	ansiBoldRed = "\x1b[1;31m"
+	ansiGreen   = "\x1b[32m"
+	ansiDim     = "\x1b[2m"
+)
+
+// UseColor tests whether colored output should be written to the
+// writer.  Color is used only if the writer is a terminal, and may be
+// disabled by setting the NO_COLOR environment variable (see
+// https://no-color.org) or by setting TERM to "dumb".
+func UseColor(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// deltaColor returns the ANSI escape sequence used to color the change
+// from one duration to another: regressions are bold red and
+// improvements green, while changes no larger than the Tolerance are
+// dimmed.  Returns an empty string if color is disabled or the change
+// is undefined.
+func (nf *NumberFormat) deltaColor(from, to time.Duration) string {
+	if !nf.Color || from == 0 {
+		return ""
+	}
+
+	change := float64(to-from) / float64(from)
+	switch {
+	case math.Abs(change) <= nf.Tolerance:
+		return ansiDim
+	case change > 0:
+		return ansiBoldRed
+	}
+
+	return ansiGreen
+}
+
+// dim returns the ANSI escape sequence used to dim changes which are
+// considered noise, or an empty string if color is disabled.
+func (nf *NumberFormat) dim() string {
+	if !nf.Color {
+		return ""
+	}
+
+	return ansiDim
+}
+
+// colorize wraps text in an ANSI escape sequence, if one is given.
+func colorize(color, text string) string {
+	if color == "" {
+		return text
+	}
+
+	return color + text + ansiReset
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUseColorTerminal(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("TERM", "xterm")
+	f, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	require.NoError(t, err)
+	defer f.Close()
+
+	result := UseColor(f)
+
+	assert.True(t, result)
+}
+
+func TestUseColorNoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("TERM", "xterm")
+	f, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	require.NoError(t, err)
+	defer f.Close()
+
+	result := UseColor(f)
+
+	assert.False(t, result)
+}
+
+func TestUseColorDumb(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("TERM", "dumb")
+	f, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	require.NoError(t, err)
+	defer f.Close()
+
+	result := UseColor(f)
+
+	assert.False(t, result)
+}
+
+func TestUseColorNotFile(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("TERM", "xterm")
+
+	result := UseColor(&bytes.Buffer{})
+
+	assert.False(t, result)
+}
+
+func TestUseColorRegularFile(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("TERM", "xterm")
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	result := UseColor(f)
+
+	assert.False(t, result)
+}
+
+func TestUseColorStatError(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("TERM", "xterm")
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	result := UseColor(f)
+
+	assert.False(t, result)
+}
+
+func TestNumberFormatDeltaColorDisabled(t *testing.T) {
+	nf := &NumberFormat{}
+
+	result := nf.deltaColor(100, 200)
+
+	assert.Equal(t, "", result)
+}
+
+func TestNumberFormatDeltaColorUndefined(t *testing.T) {
+	nf := &NumberFormat{Color: true}
+
+	result := nf.deltaColor(0, 200)
+
+	assert.Equal(t, "", result)
+}
+
+func TestNumberFormatDeltaColorRegression(t *testing.T) {
+	nf := &NumberFormat{Color: true, Tolerance: 0.05}
+
+	result := nf.deltaColor(100, 110)
+
+	assert.Equal(t, ansiBoldRed, result)
+}
+
+func TestNumberFormatDeltaColorImprovement(t *testing.T) {
+	nf := &NumberFormat{Color: true, Tolerance: 0.05}
+
+	result := nf.deltaColor(100, 90)
+
+	assert.Equal(t, ansiGreen, result)
+}
+
+func TestNumberFormatDeltaColorNoise(t *testing.T) {
+	nf := &NumberFormat{Color: true, Tolerance: 0.05}
+
+	result := nf.deltaColor(100, 97)
+
+	assert.Equal(t, ansiDim, result)
+}
+
+func TestNumberFormatDimBase(t *testing.T) {
+	nf := &NumberFormat{Color: true}
+
+	result := nf.dim()
+
+	assert.Equal(t, ansiDim, result)
+}
+
+func TestNumberFormatDimDisabled(t *testing.T) {
+	nf := &NumberFormat{}
+
+	result := nf.dim()
+
+	assert.Equal(t, "", result)
+}
+
+func TestColorizeBase(t *testing.T) {
+	result := colorize(ansiGreen, "text")
+
+	assert.Equal(t, "\x1b[32mtext\x1b[0m", result)
+}
+
+func TestColorizeNone(t *testing.T) {
+	result := colorize("", "text")
+
+	assert.Equal(t, "text", result)
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Complexity identifies a candidate model of how the time taken by a
+// workload grows with the size of its input.
+type Complexity int
+
+// Recognized complexity models.
+const (
+	Constant     Complexity = iota // O(1)
+	Linear                         // O(n)
+	Linearithmic                   // O(n log n)
+	Quadratic                      // O(n²)
+)
+
+// complexityNames maps the complexity models to their names.
+var complexityNames = map[Complexity]string{
+	Constant:     "O(1)",
+	Linear:       "O(n)",
+	Linearithmic: "O(n log n)",
+	Quadratic:    "O(n²)",
+}
+
+// complexityFuncs maps the complexity models to the functions of the
+// input size which they predict the time to be proportional to.
+var complexityFuncs = map[Complexity]func(n float64) float64{
+	Constant:     func(n float64) float64 { return 1 },
+	Linear:       func(n float64) float64 { return n },
+	Linearithmic: func(n float64) float64 { return n * math.Log2(n) },
+	Quadratic:    func(n float64) float64 { return n * n },
+}
+
+// String returns the name of the complexity model in big-O notation.
+func (c Complexity) String() string {
+	if name, ok := complexityNames[c]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("Complexity(%d)", int(c))
+}
+
+// MarshalText marshals the complexity model as its name.
+func (c Complexity) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// ComplexityFit describes how well a complexity model fits a set of
+// timing data.  The model predicts that the mean time for an input of
+// size n is Coefficient times the model function of n, in
+// nanoseconds; RMS is the root mean square of the difference between
+// the predicted and actual mean times, relative to the average of the
+// actual mean times, so that a smaller value indicates a better fit.
+type ComplexityFit struct {
+	Model       Complexity `json:"model" yaml:"model"`             // The complexity model
+	Coefficient float64    `json:"coefficient" yaml:"coefficient"` // Nanoseconds per unit of the model
+	RMS         float64    `json:"rms" yaml:"rms"`                 // Relative error of the fit
+}
+
+// String returns a description of the fit, e.g., "O(n) (rms 2.3%)".
+func (cf ComplexityFit) String() string {
+	return fmt.Sprintf("%s (rms %.1f%%)", cf.Model, 100*cf.RMS)
+}
+
+// fit fits a complexity model to the mean times, using least squares
+// through the origin.
+func fit(model Complexity, sizes []int64, dataset map[int64]*Data) ComplexityFit {
+	f := complexityFuncs[model]
+
+	// Compute the coefficient
+	var sumTF, sumFF, sumT float64
+	for _, n := range sizes {
+		fn, t := f(float64(n)), float64(dataset[n].Mean)
+		sumTF += t * fn
+		sumFF += fn * fn
+		sumT += t
+	}
+	coeff := sumTF / sumFF
+
+	// Compute the relative RMS error
+	var sumSq float64
+	for _, n := range sizes {
+		diff := float64(dataset[n].Mean) - coeff*f(float64(n))
+		sumSq += diff * diff
+	}
+	rms := 0.0
+	if mean := sumT / float64(len(sizes)); mean != 0 {
+		rms = math.Sqrt(sumSq/float64(len(sizes))) / mean
+	}
+
+	return ComplexityFit{
+		Model:       model,
+		Coefficient: coeff,
+		RMS:         rms,
+	}
+}
+
+// FitComplexity fits each of the complexity models to a set of timing
+// data, keyed by input size, and returns the fits ordered from best
+// to worst; the first element is thus the best estimate of the
+// empirical complexity of the workload.  Input sizes less than 1 and
+// Data without samples are ignored; nil is returned if fewer than two
+// input sizes remain, as no meaningful fit is possible.
+func FitComplexity(dataset map[int64]*Data) []ComplexityFit {
+	sizes := []int64{}
+	for n, d := range dataset {
+		if n >= 1 && d.Samples > 0 {
+			sizes = append(sizes, n)
+		}
+	}
+	if len(sizes) < 2 {
+		return nil
+	}
+	sort.Slice(sizes, func(i, j int) bool { return sizes[i] < sizes[j] })
+
+	result := make([]ComplexityFit, 0, len(complexityFuncs))
+	for model := Constant; model <= Quadratic; model++ {
+		result = append(result, fit(model, sizes, dataset))
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].RMS < result[j].RMS })
+
+	return result
+}
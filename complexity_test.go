@@ -0,0 +1,108 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComplexityString(t *testing.T) {
+	assert.Equal(t, "O(1)", Constant.String())
+	assert.Equal(t, "O(n)", Linear.String())
+	assert.Equal(t, "O(n log n)", Linearithmic.String())
+	assert.Equal(t, "O(n²)", Quadratic.String())
+	assert.Equal(t, "Complexity(42)", Complexity(42).String())
+}
+
+func TestComplexityMarshalText(t *testing.T) {
+	result, err := json.Marshal(ComplexityFit{Model: Linear, Coefficient: 2, RMS: 0.5})
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"model": "O(n)", "coefficient": 2, "rms": 0.5}`, string(result))
+}
+
+func TestComplexityFitString(t *testing.T) {
+	cf := ComplexityFit{Model: Linear, Coefficient: 2, RMS: 0.023}
+
+	assert.Equal(t, "O(n) (rms 2.3%)", cf.String())
+}
+
+func complexityDataset(f func(n float64) float64) map[int64]*Data {
+	dataset := map[int64]*Data{}
+	for _, n := range []int64{10, 100, 1000, 10000} {
+		mean := time.Duration(f(float64(n)))
+		dataset[n] = &Data{Samples: 1, Mean: mean, Max: mean, Min: mean}
+	}
+
+	return dataset
+}
+
+func TestFitComplexityConstant(t *testing.T) {
+	result := FitComplexity(complexityDataset(func(n float64) float64 { return 500 }))
+
+	require.Len(t, result, 4)
+	assert.Equal(t, Constant, result[0].Model)
+	assert.InDelta(t, 500, result[0].Coefficient, 0.001)
+	assert.InDelta(t, 0, result[0].RMS, 0.001)
+}
+
+func TestFitComplexityLinear(t *testing.T) {
+	result := FitComplexity(complexityDataset(func(n float64) float64 { return 3 * n }))
+
+	require.Len(t, result, 4)
+	assert.Equal(t, Linear, result[0].Model)
+	assert.InDelta(t, 3, result[0].Coefficient, 0.001)
+	assert.InDelta(t, 0, result[0].RMS, 0.001)
+}
+
+func TestFitComplexityLinearithmic(t *testing.T) {
+	result := FitComplexity(complexityDataset(func(n float64) float64 { return 5 * n * math.Log2(n) }))
+
+	require.Len(t, result, 4)
+	assert.Equal(t, Linearithmic, result[0].Model)
+}
+
+func TestFitComplexityQuadratic(t *testing.T) {
+	result := FitComplexity(complexityDataset(func(n float64) float64 { return n * n }))
+
+	require.Len(t, result, 4)
+	assert.Equal(t, Quadratic, result[0].Model)
+	assert.InDelta(t, 1, result[0].Coefficient, 0.001)
+}
+
+func TestFitComplexityZero(t *testing.T) {
+	result := FitComplexity(complexityDataset(func(n float64) float64 { return 0 }))
+
+	require.Len(t, result, 4)
+	for _, cf := range result {
+		assert.Equal(t, 0.0, cf.RMS)
+	}
+}
+
+func TestFitComplexityInsufficient(t *testing.T) {
+	result := FitComplexity(map[int64]*Data{
+		0:   {Samples: 1, Mean: 10},
+		10:  {Samples: 1, Mean: 10},
+		100: {},
+	})
+
+	assert.Nil(t, result)
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+// Derivation computes a derived value, such as the ratio of two
+// means or an error rate, from the other entries of a snapshot.  It
+// returns false if the value cannot be computed, e.g., because an
+// entry it depends on is missing or a denominator is zero; in that
+// case, the derived value is omitted from the snapshot.
+type Derivation func(snap *Snapshot) (float64, bool)
+
+// MeanRatio returns a Derivation computing the ratio of the mean of
+// the Data named by num to the mean of the Data named by den.
+func MeanRatio(num, den string) Derivation {
+	return func(snap *Snapshot) (float64, bool) {
+		n, ok := snap.Data[num]
+		if !ok || n.Samples <= 0 {
+			return 0, false
+		}
+		d, ok := snap.Data[den]
+		if !ok || d.Samples <= 0 || d.Mean == 0 {
+			return 0, false
+		}
+
+		return float64(n.Mean) / float64(d.Mean), true
+	}
+}
+
+// CounterRatio returns a Derivation computing the ratio of the
+// counter named by num to the counter named by den, e.g., the
+// fraction of requests that failed.
+func CounterRatio(num, den string) Derivation {
+	return func(snap *Snapshot) (float64, bool) {
+		n, ok := snap.Counters[num]
+		if !ok {
+			return 0, false
+		}
+		d, ok := snap.Counters[den]
+		if !ok || d == 0 {
+			return 0, false
+		}
+
+		return float64(n) / float64(d), true
+	}
+}
+
+// derivedValues computes the derived values for a snapshot, or
+// returns nil if there are none.
+func derivedValues(snap *Snapshot, derived map[string]Derivation) map[string]float64 {
+	if len(derived) == 0 {
+		return nil
+	}
+
+	result := make(map[string]float64, len(derived))
+	for name, fn := range derived {
+		if v, ok := fn(snap); ok {
+			result[name] = v
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testSnapshot = &Snapshot{
+	Data: map[string]*Data{
+		"hit":   {Samples: 2, Mean: 10},
+		"miss":  {Samples: 3, Mean: 40},
+		"empty": {},
+		"zero":  {Samples: 1},
+	},
+	Counters: map[string]int64{
+		"errors":   5,
+		"requests": 20,
+		"none":     0,
+	},
+}
+
+func TestMeanRatioBase(t *testing.T) {
+	result, ok := MeanRatio("hit", "miss")(testSnapshot)
+
+	assert.True(t, ok)
+	assert.Equal(t, 0.25, result)
+}
+
+func TestMeanRatioMissingNumerator(t *testing.T) {
+	_, ok := MeanRatio("missing", "miss")(testSnapshot)
+
+	assert.False(t, ok)
+}
+
+func TestMeanRatioEmptyNumerator(t *testing.T) {
+	_, ok := MeanRatio("empty", "miss")(testSnapshot)
+
+	assert.False(t, ok)
+}
+
+func TestMeanRatioMissingDenominator(t *testing.T) {
+	_, ok := MeanRatio("hit", "missing")(testSnapshot)
+
+	assert.False(t, ok)
+}
+
+func TestMeanRatioZeroDenominator(t *testing.T) {
+	_, ok := MeanRatio("hit", "zero")(testSnapshot)
+
+	assert.False(t, ok)
+}
+
+func TestCounterRatioBase(t *testing.T) {
+	result, ok := CounterRatio("errors", "requests")(testSnapshot)
+
+	assert.True(t, ok)
+	assert.Equal(t, 0.25, result)
+}
+
+func TestCounterRatioMissingNumerator(t *testing.T) {
+	_, ok := CounterRatio("missing", "requests")(testSnapshot)
+
+	assert.False(t, ok)
+}
+
+func TestCounterRatioZeroDenominator(t *testing.T) {
+	_, ok := CounterRatio("errors", "none")(testSnapshot)
+
+	assert.False(t, ok)
+}
+
+func TestDerivedValuesBase(t *testing.T) {
+	result := derivedValues(testSnapshot, map[string]Derivation{
+		"error_rate": CounterRatio("errors", "requests"),
+		"hit_ratio":  MeanRatio("hit", "miss"),
+		"bad":        MeanRatio("hit", "zero"),
+	})
+
+	assert.Equal(t, map[string]float64{
+		"error_rate": 0.25,
+		"hit_ratio":  0.25,
+	}, result)
+}
+
+func TestDerivedValuesEmpty(t *testing.T) {
+	result := derivedValues(testSnapshot, nil)
+
+	assert.Nil(t, result)
+}
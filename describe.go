@@ -0,0 +1,125 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// tailPercentile is the percentile used to describe the tail of a
+// distribution when samples are retained.
+const tailPercentile = 99
+
+// statChange describes the change in a single statistic between two
+// Results.
+type statChange struct {
+	name     string
+	from, to time.Duration
+}
+
+// relative returns the relative change in the statistic, or 0 if the
+// statistic was 0 before the change.
+func (sc statChange) relative() float64 {
+	if sc.from == 0 {
+		return 0
+	}
+
+	return float64(sc.to-sc.from) / float64(sc.from)
+}
+
+// tail returns a description of the tail of the Data and its value:
+// the 99th percentile if samples are retained, or the maximum.
+func tail(d *Data) (string, time.Duration) {
+	if len(d.retained) > 0 {
+		return fmt.Sprintf("tail (p%d)", tailPercentile), d.Percentile(tailPercentile)
+	}
+
+	return "tail (max)", d.Max
+}
+
+// DescribeChange writes a narrative summary of the change from one
+// Result to another using the default NumberFormat; see the
+// NumberFormat.DescribeChange method.
+func DescribeChange(w io.Writer, from, to *Result) error {
+	return (&NumberFormat{}).DescribeChange(w, from, to)
+}
+
+// DescribeChange writes a narrative summary of the change from one
+// Result to another, intended to accompany a table such as that
+// written by WriteComparison.  The summary gives the sample counts,
+// the changes in the mean, the tail, and the minimum, and names the
+// statistic which changed most.  It then states whether the
+// distribution shifted, meaning that the change in the mean is
+// statistically significant, and whether it widened or narrowed,
+// meaning that the standard deviation changed by more than the
+// Tolerance; neither is stated if either Result has fewer than two
+// samples.  The tail is the 99th percentile if both Results retain
+// samples (see Retained), and the maximum otherwise.
+func (nf *NumberFormat) DescribeChange(w io.Writer, from, to *Result) error {
+	tailName, fromTail := tail(from.Data)
+	toTailName, toTail := tail(to.Data)
+	if toTailName != tailName {
+		tailName, fromTail, toTail = "tail (max)", from.Data.Max, to.Data.Max
+	}
+	changes := []statChange{
+		{"mean", from.Data.Mean, to.Data.Mean},
+		{tailName, fromTail, toTail},
+		{"min", from.Data.Min, to.Data.Min},
+	}
+
+	// Describe the samples and the individual statistics
+	text := fmt.Sprintf("%s has %s samples and %s has %s samples.\n",
+		from.Name, nf.Int(from.Data.Samples), to.Name, nf.Int(to.Data.Samples))
+	largest := -1
+	for i, sc := range changes {
+		text += fmt.Sprintf("The %s changed from %s to %s (%s).\n",
+			sc.name, nf.Duration(sc.from), nf.Duration(sc.to), nf.Percent(sc.from, sc.to))
+		if sc.from != 0 && (largest < 0 || math.Abs(sc.relative()) > math.Abs(changes[largest].relative())) {
+			largest = i
+		}
+	}
+	if largest >= 0 && changes[largest].relative() != 0 {
+		text += fmt.Sprintf("The %s changed most.\n", changes[largest].name)
+	}
+
+	// Describe the shape of the change
+	sig, ok := significant(from.Data, to.Data)
+	switch {
+	case !ok:
+		text += "There are too few samples to tell whether the distribution shifted.\n"
+	case !sig:
+		text += "The distribution did not shift significantly.\n"
+	case to.Data.Mean > from.Data.Mean:
+		text += "The distribution shifted slower.\n"
+	default:
+		text += "The distribution shifted faster.\n"
+	}
+	spread := statChange{"standard deviation", from.Data.SampleStdDev(), to.Data.SampleStdDev()}
+	if ok && spread.from != 0 && math.Abs(spread.relative()) > nf.Tolerance {
+		shape := "widened"
+		if spread.to < spread.from {
+			shape = "narrowed"
+		}
+		text += fmt.Sprintf("The distribution %s; the %s changed from %s to %s (%s).\n",
+			shape, spread.name, nf.Duration(spread.from), nf.Duration(spread.to), nf.Percent(spread.from, spread.to))
+	}
+
+	_, err := io.WriteString(w, text)
+
+	return err
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribeChange(t *testing.T) {
+	from := &Result{Name: "old", Data: objectiveData(10, 20, 30, 40, 50)}
+	to := &Result{Name: "new", Data: objectiveData(10, 20, 30, 40, 150)}
+	buf := &bytes.Buffer{}
+
+	err := DescribeChange(buf, from, to)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `old has 5 samples and new has 5 samples.
+The mean changed from 30ns to 50ns (+66.7%).
+The tail (p99) changed from 50ns to 150ns (+200.0%).
+The min changed from 10ns to 10ns (+0.0%).
+The tail (p99) changed most.
+The distribution did not shift significantly.
+The distribution widened; the standard deviation changed from 15ns to 57ns (+280.0%).
+`, buf.String())
+}
+
+func TestNumberFormatDescribeChangeShifted(t *testing.T) {
+	from := &Result{Name: "old", Data: &Data{Samples: 10, Mean: 200, Max: 300, Min: 100, m2: 900}}
+	to := &Result{Name: "new", Data: &Data{Samples: 10, Mean: 100, Max: 150, Min: 50, m2: 900}}
+	nf := &NumberFormat{Tolerance: 0.05}
+	buf := &bytes.Buffer{}
+
+	err := nf.DescribeChange(buf, from, to)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `old has 10 samples and new has 10 samples.
+The mean changed from 200ns to 100ns (-50.0%).
+The tail (max) changed from 300ns to 150ns (-50.0%).
+The min changed from 100ns to 50ns (-50.0%).
+The mean changed most.
+The distribution shifted faster.
+`, buf.String())
+}
+
+func TestNumberFormatDescribeChangeSlower(t *testing.T) {
+	from := &Result{Name: "old", Data: &Data{Samples: 10, Mean: 100, Max: 150, Min: 50, m2: 3600}}
+	to := &Result{Name: "new", Data: &Data{Samples: 10, Mean: 200, Max: 200, Min: 150, m2: 900}}
+	nf := &NumberFormat{}
+	buf := &bytes.Buffer{}
+
+	err := nf.DescribeChange(buf, from, to)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `old has 10 samples and new has 10 samples.
+The mean changed from 100ns to 200ns (+100.0%).
+The tail (max) changed from 150ns to 200ns (+33.3%).
+The min changed from 50ns to 150ns (+200.0%).
+The min changed most.
+The distribution shifted slower.
+The distribution narrowed; the standard deviation changed from 20ns to 10ns (-50.0%).
+`, buf.String())
+}
+
+func TestNumberFormatDescribeChangeMixedTail(t *testing.T) {
+	from := &Result{Name: "old", Data: objectiveData(10, 20, 30)}
+	to := &Result{Name: "new", Data: &Data{Samples: 1, Mean: 20, Max: 20, Min: 20}}
+	nf := &NumberFormat{}
+	buf := &bytes.Buffer{}
+
+	err := nf.DescribeChange(buf, from, to)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `old has 3 samples and new has 1 samples.
+The mean changed from 20ns to 20ns (+0.0%).
+The tail (max) changed from 30ns to 20ns (-33.3%).
+The min changed from 10ns to 20ns (+100.0%).
+The min changed most.
+There are too few samples to tell whether the distribution shifted.
+`, buf.String())
+}
+
+func TestNumberFormatDescribeChangeEmpty(t *testing.T) {
+	from := &Result{Name: "old", Data: &Data{}}
+	to := &Result{Name: "new", Data: &Data{}}
+	nf := &NumberFormat{}
+	buf := &bytes.Buffer{}
+
+	err := nf.DescribeChange(buf, from, to)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `old has 0 samples and new has 0 samples.
+The mean changed from 0s to 0s (-).
+The tail (max) changed from 0s to 0s (-).
+The min changed from 0s to 0s (-).
+There are too few samples to tell whether the distribution shifted.
+`, buf.String())
+}
+
+func TestNumberFormatDescribeChangeWriteError(t *testing.T) {
+	from := &Result{Name: "old", Data: &Data{}}
+	to := &Result{Name: "new", Data: &Data{}}
+	nf := &NumberFormat{}
+
+	err := nf.DescribeChange(errWriter{}, from, to)
+
+	assert.ErrorIs(t, err, assert.AnError)
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"math"
+	"sort"
+)
+
+// Settings for the incomplete gamma function computation.
+const (
+	gammaEpsilon  = 1e-14 // Relative accuracy
+	gammaMaxIters = 1000  // Maximum iterations
+	gammaTiny     = 1e-300
+)
+
+// BimodalThreshold is the bimodality coefficient above which a
+// distribution is considered bimodal; it is the coefficient of the
+// uniform distribution.
+const BimodalThreshold = 5.0 / 9.0
+
+// LognormalFit describes a lognormal distribution fitted to a set of
+// samples: the logarithms of the samples, in nanoseconds, are
+// normally distributed with mean Mu and standard deviation Sigma.
+type LognormalFit struct {
+	Mu    float64 `json:"mu" yaml:"mu"`       // Mean of the log of the samples
+	Sigma float64 `json:"sigma" yaml:"sigma"` // Standard deviation of the log
+	KS    float64 `json:"ks" yaml:"ks"`       // Kolmogorov-Smirnov statistic
+}
+
+// GammaFit describes a gamma distribution fitted to a set of samples,
+// with the scale in nanoseconds.
+type GammaFit struct {
+	Shape float64 `json:"shape" yaml:"shape"` // Shape parameter
+	Scale float64 `json:"scale" yaml:"scale"` // Scale parameter, in nanoseconds
+	KS    float64 `json:"ks" yaml:"ks"`       // Kolmogorov-Smirnov statistic
+}
+
+// DistributionSummary describes the distributions fitted to a set of
+// samples by FitDistribution.  The quality of each fit is given by
+// the Kolmogorov-Smirnov statistic, the largest difference between
+// the fitted and empirical cumulative distributions; smaller is
+// better, and Best names the better fit.  Bimodality is the sample
+// bimodality coefficient, which exceeds BimodalThreshold for
+// distributions with two distinct modes, such as those caused by a
+// cache that sometimes misses; a mean hides such behavior.
+type DistributionSummary struct {
+	Samples    int          `json:"samples" yaml:"samples"`       // Number of samples fitted
+	Lognormal  LognormalFit `json:"lognormal" yaml:"lognormal"`   // Lognormal fit
+	Gamma      GammaFit     `json:"gamma" yaml:"gamma"`           // Gamma fit
+	Best       string       `json:"best" yaml:"best"`             // "lognormal" or "gamma"
+	Bimodality float64      `json:"bimodality" yaml:"bimodality"` // Bimodality coefficient
+	Bimodal    bool         `json:"bimodal" yaml:"bimodal"`       // Set if likely bimodal
+}
+
+// lowerGamma computes the regularized lower incomplete gamma function
+// P(a, x), which is the cumulative distribution function of the gamma
+// distribution with shape a and unit scale.
+func lowerGamma(a, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	lg, _ := math.Lgamma(a)
+	prefix := math.Exp(-x + a*math.Log(x) - lg)
+
+	// Use the series expansion for small x
+	if x < a+1 {
+		ap, sum := a, 1/a
+		del := sum
+		for i := 0; i < gammaMaxIters && math.Abs(del) > math.Abs(sum)*gammaEpsilon; i++ {
+			ap++
+			del *= x / ap
+			sum += del
+		}
+		return sum * prefix
+	}
+
+	// Use the continued fraction for the upper function otherwise
+	b := x + 1 - a
+	c := 1 / gammaTiny
+	d := 1 / b
+	h := d
+	for i := 1; i <= gammaMaxIters; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < gammaTiny {
+			d = gammaTiny
+		}
+		c = b + an/c
+		if math.Abs(c) < gammaTiny {
+			c = gammaTiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < gammaEpsilon {
+			break
+		}
+	}
+
+	return 1 - prefix*h
+}
+
+// ksStatistic computes the Kolmogorov-Smirnov statistic of sorted
+// values against a cumulative distribution function.
+func ksStatistic(values []float64, cdf func(x float64) float64) float64 {
+	n := float64(len(values))
+	result := 0.0
+	for i, x := range values {
+		f := cdf(x)
+		result = math.Max(result, math.Max(f-float64(i)/n, float64(i+1)/n-f))
+	}
+
+	return result
+}
+
+// FitDistribution fits lognormal and gamma distributions to the
+// samples, such as those returned by Data.Retained, and computes
+// their bimodality coefficient.  Samples that are not positive are
+// ignored; the zero DistributionSummary is returned if fewer than
+// four samples remain, or if they are all equal, as no meaningful
+// fit is possible.
+func FitDistribution(samples []Sample) DistributionSummary {
+	values := make([]float64, 0, len(samples))
+	for _, s := range samples {
+		if s.Duration > 0 {
+			values = append(values, float64(s.Duration))
+		}
+	}
+	if len(values) < 4 {
+		return DistributionSummary{}
+	}
+	sort.Float64s(values)
+	if values[0] == values[len(values)-1] {
+		return DistributionSummary{}
+	}
+	n := float64(len(values))
+
+	// Compute the moments of the values and their logarithms
+	var mean, logMean float64
+	for _, x := range values {
+		mean += x
+		logMean += math.Log(x)
+	}
+	mean /= n
+	logMean /= n
+	var m2, m3, m4, logM2 float64
+	for _, x := range values {
+		dev := x - mean
+		m2 += dev * dev
+		m3 += dev * dev * dev
+		m4 += dev * dev * dev * dev
+		logDev := math.Log(x) - logMean
+		logM2 += logDev * logDev
+	}
+	variance := m2 / (n - 1)
+
+	// Fit the lognormal by maximum likelihood
+	ln := LognormalFit{Mu: logMean, Sigma: math.Sqrt(logM2 / n)}
+	ln.KS = ksStatistic(values, func(x float64) float64 {
+		return math.Erfc(-(math.Log(x)-ln.Mu)/(ln.Sigma*math.Sqrt2)) / 2
+	})
+
+	// Fit the gamma by the method of moments
+	g := GammaFit{Shape: mean * mean / variance, Scale: variance / mean}
+	g.KS = ksStatistic(values, func(x float64) float64 {
+		return lowerGamma(g.Shape, x/g.Scale)
+	})
+
+	// Compute the bimodality coefficient from the sample skewness
+	// and excess kurtosis
+	skew := math.Sqrt(n*(n-1)) / (n - 2) * (m3 / n) / math.Pow(m2/n, 1.5)
+	kurt := (n - 1) / ((n - 2) * (n - 3)) * ((n+1)*(m4/n)/((m2/n)*(m2/n)) - 3*(n-1))
+	bc := (skew*skew + 1) / (kurt + 3*(n-1)*(n-1)/((n-2)*(n-3)))
+
+	result := DistributionSummary{
+		Samples:    len(values),
+		Lognormal:  ln,
+		Gamma:      g,
+		Best:       "lognormal",
+		Bimodality: bc,
+		Bimodal:    bc > BimodalThreshold,
+	}
+	if g.KS < ln.KS {
+		result.Best = "gamma"
+	}
+
+	return result
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLowerGamma(t *testing.T) {
+	assert.Equal(t, 0.0, lowerGamma(2, 0))
+	assert.InDelta(t, 1-math.Exp(-0.5), lowerGamma(1, 0.5), 1e-12)
+	assert.InDelta(t, 1-math.Exp(-5), lowerGamma(1, 5), 1e-12)
+	assert.InDelta(t, 1-4*math.Exp(-3), lowerGamma(2, 3), 1e-12)
+	assert.InDelta(t, 1-5*math.Exp(-2), lowerGamma(3, 2), 1e-12)
+}
+
+func TestKSStatistic(t *testing.T) {
+	result := ksStatistic([]float64{0.25, 0.5, 0.75}, func(x float64) float64 { return x })
+
+	assert.InDelta(t, 0.25, result, 1e-12)
+}
+
+func distSamples(gen func(rng *rand.Rand) float64) []Sample {
+	rng := rand.New(rand.NewSource(42)) //nolint:gosec
+	result := make([]Sample, 2000)
+	for i := range result {
+		result[i] = Sample{Duration: time.Duration(gen(rng))}
+	}
+
+	return result
+}
+
+func TestFitDistributionLognormal(t *testing.T) {
+	samples := distSamples(func(rng *rand.Rand) float64 {
+		return math.Exp(math.Log(1e6) + 0.5*rng.NormFloat64())
+	})
+
+	result := FitDistribution(samples)
+
+	assert.Equal(t, 2000, result.Samples)
+	assert.Equal(t, "lognormal", result.Best)
+	assert.InDelta(t, math.Log(1e6), result.Lognormal.Mu, 0.05)
+	assert.InDelta(t, 0.5, result.Lognormal.Sigma, 0.05)
+	assert.Less(t, result.Lognormal.KS, 0.05)
+	assert.False(t, result.Bimodal)
+}
+
+func TestFitDistributionGamma(t *testing.T) {
+	samples := distSamples(func(rng *rand.Rand) float64 {
+		return 1e6 * (rng.ExpFloat64() + rng.ExpFloat64() + rng.ExpFloat64())
+	})
+
+	result := FitDistribution(samples)
+
+	assert.Equal(t, "gamma", result.Best)
+	assert.InDelta(t, 3, result.Gamma.Shape, 0.3)
+	assert.InDelta(t, 1e6, result.Gamma.Scale, 1e5)
+	assert.Less(t, result.Gamma.KS, 0.05)
+	assert.False(t, result.Bimodal)
+}
+
+func TestFitDistributionBimodal(t *testing.T) {
+	samples := distSamples(func(rng *rand.Rand) float64 {
+		if rng.Intn(2) == 0 {
+			return 1e6 + 1e5*rng.NormFloat64()
+		}
+		return 1e7 + 1e6*rng.NormFloat64()
+	})
+
+	result := FitDistribution(samples)
+
+	assert.Greater(t, result.Bimodality, BimodalThreshold)
+	assert.True(t, result.Bimodal)
+}
+
+func TestFitDistributionTooFew(t *testing.T) {
+	samples := []Sample{{Duration: 1}, {Duration: 2}, {Duration: 3}, {Duration: 0}, {Duration: -1}}
+
+	result := FitDistribution(samples)
+
+	assert.Equal(t, DistributionSummary{}, result)
+}
+
+func TestFitDistributionConstant(t *testing.T) {
+	samples := []Sample{{Duration: 5}, {Duration: 5}, {Duration: 5}, {Duration: 5}}
+
+	result := FitDistribution(samples)
+
+	assert.Equal(t, DistributionSummary{}, result)
+}
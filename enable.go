@@ -0,0 +1,47 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build !timeitnoop
+// +build !timeitnoop
+
+package timeit
+
+import "sync/atomic"
+
+// disabled is set to a non-zero value when instrumentation is
+// disabled; see Enable.
+var disabled int32
+
+// Enable enables or disables instrumentation for the whole package.
+// While instrumentation is disabled, TimeIt and its variants simply
+// call the function and return 0, and Update and its variants discard
+// the sample, so that instrumentation can be left in production code
+// paths and toggled off with negligible overhead.  Samples read by
+// the importers and replay functions, and the Data built internally
+// by analyses such as ChangeDetector, are recorded regardless.
+// Instrumentation is enabled by default.  To compile the
+// instrumentation away entirely, build with the "timeitnoop" build
+// tag; Enable then has no effect.
+func Enable(enable bool) {
+	if enable {
+		atomic.StoreInt32(&disabled, 0)
+	} else {
+		atomic.StoreInt32(&disabled, 1)
+	}
+}
+
+// Enabled returns true if instrumentation is enabled.
+func Enabled() bool {
+	return atomic.LoadInt32(&disabled) == 0
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build timeitnoop
+// +build timeitnoop
+
+package timeit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnableNoop(t *testing.T) {
+	Enable(true)
+
+	assert.False(t, Enabled())
+}
+
+func TestDataTimeItNoop(t *testing.T) {
+	d := &Data{}
+	called := false
+
+	result := d.TimeIt(func() { called = true })
+
+	assert.Equal(t, 0, int(result))
+	assert.True(t, called)
+	assert.Equal(t, int64(0), d.Samples)
+}
+
+func TestDataUpdateNoop(t *testing.T) {
+	d := &Data{}
+
+	d.Update(10)
+
+	assert.Equal(t, int64(0), d.Samples)
+}
+
+func TestRecordReplayNoop(t *testing.T) {
+	dataset := map[string]*Data{}
+	rec := &Record{Duration: 50, Name: "timer"}
+
+	rec.replay(dataset)
+
+	assert.Equal(t, int64(1), dataset["timer"].Samples)
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build !timeitnoop
+// +build !timeitnoop
+
+package timeit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnable(t *testing.T) {
+	defer Enable(true)
+
+	Enable(false)
+	disabledResult := Enabled()
+	Enable(true)
+	enabledResult := Enabled()
+
+	assert.False(t, disabledResult)
+	assert.True(t, enabledResult)
+}
+
+func TestDataUpdateDisabled(t *testing.T) {
+	defer Enable(true)
+	next := &Data{}
+	d := &Data{Next: next}
+	Enable(false)
+
+	d.Update(10)
+
+	assert.Equal(t, int64(0), d.Samples)
+	assert.Equal(t, int64(0), next.Samples)
+}
+
+func TestDataUpdateCheckedDisabled(t *testing.T) {
+	defer Enable(true)
+	d := &Data{}
+	d.Freeze()
+	Enable(false)
+
+	err := d.UpdateChecked(10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), d.Samples)
+}
+
+func TestDataTimeItDisabled(t *testing.T) {
+	defer Enable(true)
+	d := &Data{Clock: &testClock{now: time.Unix(1000, 0), delta: 5}}
+	called := false
+	Enable(false)
+
+	result := d.TimeIt(func() { called = true })
+
+	assert.Equal(t, time.Duration(0), result)
+	assert.True(t, called)
+	assert.Equal(t, int64(0), d.Samples)
+}
+
+func TestDataTimeItContextDisabled(t *testing.T) {
+	defer Enable(true)
+	d := &Data{Clock: &testClock{now: time.Unix(1000, 0), delta: 5}}
+	ctx := context.Background()
+	var inner context.Context
+	Enable(false)
+
+	result := d.TimeItContext(ctx, func(ctx context.Context) { inner = ctx })
+
+	assert.Equal(t, time.Duration(0), result)
+	assert.Equal(t, ctx, inner)
+	assert.Equal(t, int64(0), d.Samples)
+}
+
+func TestDataMeasureDisabled(t *testing.T) {
+	defer Enable(true)
+	d := &Data{Clock: &testClock{now: time.Unix(1000, 0), delta: 5}}
+	Enable(false)
+
+	result := d.Measure()()
+
+	assert.Equal(t, time.Duration(0), result)
+	assert.Equal(t, int64(0), d.Samples)
+}
+
+func TestClassifierTimeItTaggedDisabled(t *testing.T) {
+	defer Enable(true)
+	c := &Classifier{Classify: func(s Sample) string { return "a" }}
+	called := false
+	Enable(false)
+
+	result := c.TimeItTagged(nil, func() { called = true })
+
+	assert.Equal(t, time.Duration(0), result)
+	assert.True(t, called)
+	assert.Empty(t, c.Dataset())
+}
+
+func TestClassifierUpdateDisabled(t *testing.T) {
+	defer Enable(true)
+	c := &Classifier{Classify: func(s Sample) string { return "a" }}
+	Enable(false)
+
+	c.Update(10)
+
+	assert.Empty(t, c.Dataset())
+}
+
+func TestRouterTimeItDisabled(t *testing.T) {
+	defer Enable(true)
+	r := &Router{Route: RouteLabel("tenant")}
+	called := false
+	Enable(false)
+
+	result := r.TimeIt(nil, "a", func() { called = true })
+
+	assert.Equal(t, time.Duration(0), result)
+	assert.True(t, called)
+	assert.Empty(t, r.Partitions())
+}
+
+func TestRouterUpdateDisabled(t *testing.T) {
+	defer Enable(true)
+	r := &Router{Route: RouteLabel("tenant")}
+	Enable(false)
+
+	r.Update(nil, "a", 10)
+
+	assert.Empty(t, r.Partitions())
+}
+
+func TestDataUpdateTaggedDisabled(t *testing.T) {
+	defer Enable(true)
+	d := &Data{}
+	Enable(false)
+
+	d.UpdateTagged("tag", 10)
+
+	assert.Equal(t, int64(0), d.Samples)
+}
+
+func TestDataUpdateThroughputDisabled(t *testing.T) {
+	defer Enable(true)
+	d := &Data{}
+	Enable(false)
+
+	d.UpdateThroughput(10, 5, 100)
+
+	assert.Equal(t, int64(0), d.Samples)
+	assert.Equal(t, int64(0), d.ItemRate.Samples)
+}
+
+func TestRecordReplayDisabled(t *testing.T) {
+	defer Enable(true)
+	dataset := map[string]*Data{}
+	rec := &Record{Duration: 50, Name: "timer"}
+	Enable(false)
+
+	rec.replay(dataset)
+
+	assert.Equal(t, int64(1), dataset["timer"].Samples)
+}
+
+func TestGeneratorFillDisabled(t *testing.T) {
+	defer Enable(true)
+	g := &Generator{Dist: ConstantDist(100)}
+	d := &Data{}
+	Enable(false)
+
+	g.Fill(d, 3)
+
+	assert.Equal(t, int64(3), d.Samples)
+}
+
+func TestSegmentStatsDisabled(t *testing.T) {
+	defer Enable(true)
+	samples := []Sample{{Duration: 30}, {Duration: 10}, {Duration: 20}}
+	Enable(false)
+
+	mean, _ := segmentStats(samples)
+
+	assert.Equal(t, time.Duration(20), mean)
+}
+
+func TestBurstDetectorThresholdDisabled(t *testing.T) {
+	defer Enable(true)
+	bd := &BurstDetector{}
+	Enable(false)
+
+	result := bd.threshold(burstSamples(10, 20, 30))
+
+	assert.Equal(t, time.Duration(50), result)
+}
+
+func TestRunInfoCalibrateDisabled(t *testing.T) {
+	defer Enable(true)
+	ri := &RunInfo{}
+	Enable(false)
+
+	ri.Calibrate()
+
+	assert.Greater(t, int64(ri.Reference), int64(0))
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Encoder describes an output format for a set of named Data.
+type Encoder interface {
+	// Encode writes the dataset to the writer.
+	Encode(w io.Writer, dataset map[string]*Data) error
+}
+
+// EncoderFunc is an adaptor allowing an ordinary function to be used
+// as an Encoder.
+type EncoderFunc func(w io.Writer, dataset map[string]*Data) error
+
+// Encode writes the dataset to the writer.
+func (f EncoderFunc) Encode(w io.Writer, dataset map[string]*Data) error {
+	return f(w, dataset)
+}
+
+// ResultEncoder is implemented by Encoders that can also encode a
+// Result, including its metadata, rather than only its Data; see
+// Result.Report.  The default "json", "yaml", and "benchmark" formats
+// implement ResultEncoder.
+type ResultEncoder interface {
+	// EncodeResult writes the Result to the writer.
+	EncodeResult(w io.Writer, r *Result) error
+}
+
+// resultFormat is an Encoder for one of the default output formats,
+// which also implements ResultEncoder.
+type resultFormat struct {
+	encode       EncoderFunc
+	encodeResult func(w io.Writer, r *Result) error
+}
+
+// Encode writes the dataset to the writer.
+func (rf resultFormat) Encode(w io.Writer, dataset map[string]*Data) error {
+	return rf.encode(w, dataset)
+}
+
+// EncodeResult writes the Result to the writer.
+func (rf resultFormat) EncodeResult(w io.Writer, r *Result) error {
+	return rf.encodeResult(w, r)
+}
+
+// encodeJSON encodes the dataset as a JSON object.
+func encodeJSON(w io.Writer, dataset map[string]*Data) error {
+	return json.NewEncoder(w).Encode(dataset)
+}
+
+// encodeYAML encodes the dataset as a YAML document.
+func encodeYAML(w io.Writer, dataset map[string]*Data) error {
+	enc := yaml.NewEncoder(w)
+	defer enc.Close()
+
+	return enc.Encode(dataset)
+}
+
+// formats is the registry of output formats.
+var (
+	formatsMu sync.RWMutex
+	formats   = map[string]Encoder{
+		"json":      resultFormat{encodeJSON, encodeResultJSON},
+		"yaml":      resultFormat{encodeYAML, encodeResultYAML},
+		"benchmark": resultFormat{WriteBenchmarks, encodeResultBenchmark},
+	}
+)
+
+// RegisterFormat registers an Encoder for an output format, allowing
+// it to be selected by name.  The "json", "yaml", and "benchmark"
+// formats are registered by default; registering a format with an
+// existing name replaces it.
+func RegisterFormat(name string, enc Encoder) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	formats[name] = enc
+}
+
+// LookupFormat looks up the Encoder for the named output format.  An
+// error wrapping ErrUnknownFormat is returned if the format has not
+// been registered.
+func LookupFormat(name string) (Encoder, error) {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	enc, ok := formats[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, name)
+	}
+
+	return enc, nil
+}
+
+// Formats returns a sorted list of the names of the registered output
+// formats.
+func Formats() []string {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
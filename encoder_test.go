@@ -0,0 +1,131 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testDataset = map[string]*Data{
+	"a": {
+		Samples: 3,
+		Mean:    50,
+		Max:     75,
+		Min:     25,
+		Flags:   StdDev,
+		m2:      1250,
+	},
+}
+
+func TestEncoderFuncImplementsEncoder(t *testing.T) {
+	assert.Implements(t, (*Encoder)(nil), EncoderFunc(encodeJSON))
+}
+
+func TestEncoderFuncEncode(t *testing.T) {
+	buf := &bytes.Buffer{}
+	called := false
+	f := EncoderFunc(func(w io.Writer, dataset map[string]*Data) error {
+		assert.Same(t, buf, w)
+		assert.Equal(t, testDataset, dataset)
+		called = true
+		return assert.AnError
+	})
+
+	err := f.Encode(buf, testDataset)
+
+	assert.Same(t, assert.AnError, err)
+	assert.True(t, called)
+}
+
+func TestEncodeJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	err := encodeJSON(buf, testDataset)
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"a": {"samples": 3, "mean": 50, "max": 75, "min": 25, "std_dev": 20}}`, buf.String())
+}
+
+func TestEncodeYAMLBase(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	err := encodeYAML(buf, testDataset)
+
+	require.NoError(t, err)
+	assert.Equal(t, `a:
+  samples: 3
+  mean: 50ns
+  max: 75ns
+  min: 25ns
+  std_dev: 20ns
+`, buf.String())
+}
+
+func TestEncodeYAMLWriteError(t *testing.T) {
+	err := encodeYAML(errWriter{}, testDataset)
+
+	assert.ErrorContains(t, err, assert.AnError.Error())
+}
+
+func TestRegisterFormat(t *testing.T) {
+	defer func() {
+		formatsMu.Lock()
+		defer formatsMu.Unlock()
+		delete(formats, "test")
+	}()
+	enc := EncoderFunc(encodeJSON)
+
+	RegisterFormat("test", enc)
+
+	result, err := LookupFormat("test")
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+}
+
+func TestLookupFormatBase(t *testing.T) {
+	result, err := LookupFormat("json")
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+}
+
+func TestLookupFormatUnknown(t *testing.T) {
+	result, err := LookupFormat("unknown")
+
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+	assert.Nil(t, result)
+}
+
+func TestFormats(t *testing.T) {
+	result := Formats()
+
+	assert.Equal(t, []string{"benchmark", "json", "yaml"}, result)
+}
+
+func TestResultFormatEncode(t *testing.T) {
+	buf := &bytes.Buffer{}
+	enc, err := LookupFormat("json")
+	require.NoError(t, err)
+
+	err = enc.Encode(buf, testDataset)
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"a": {"samples": 3, "mean": 50, "max": 75, "min": 25, "std_dev": 20}}`, buf.String())
+}
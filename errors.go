@@ -0,0 +1,39 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import "errors"
+
+// Errors that may be returned by various functions.
+var (
+	ErrBadRecord     = errors.New("invalid sample record")
+	ErrBadConfigKey  = errors.New("invalid benchmark configuration key")
+	ErrUnknownFormat = errors.New("unknown output format")
+	ErrInvalidSample = errors.New("invalid sample")
+	ErrFrozen        = errors.New("data is frozen")
+	ErrOverflow      = errors.New("statistics overflowed")
+	ErrBadImport     = errors.New("invalid imported data")
+	ErrUnknownGroup  = errors.New("unknown group")
+	ErrPartial       = errors.New("measurement incomplete")
+	ErrTimeout       = errors.New("timed out")
+	ErrUnsupported   = errors.New("not supported on this platform")
+	ErrUnknownFlag   = errors.New("unknown marshal flag")
+	ErrBadEnv        = errors.New("invalid environment variable")
+	ErrInvariant     = errors.New("invariant violated")
+	ErrBadRule       = errors.New("invalid alert rule")
+	ErrBadRate       = errors.New("invalid load rate")
+	ErrBadValue      = errors.New("malformed value")
+	ErrBadSchedule   = errors.New("invalid schedule")
+)
@@ -0,0 +1,50 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import "time"
+
+// Event is a named occurrence, such as a deployment or a
+// configuration change, which may explain a change in performance.
+// Events may be given to a ChangeDetector, which attributes change
+// points to them, and to a Heatmap, which marks them when rendered.
+type Event struct {
+	At   time.Time `json:"at" yaml:"at"`     // When the event occurred
+	Name string    `json:"name" yaml:"name"` // Description, e.g., a deployed revision
+}
+
+// nearestEvent returns the event nearest to the specified time, from
+// among those occurring between from and to, inclusive, or nil if
+// there are none.
+func nearestEvent(events []Event, at, from, to time.Time) *Event {
+	var result *Event
+	var best time.Duration
+	for i := range events {
+		e := &events[i]
+		if e.At.Before(from) || e.At.After(to) {
+			continue
+		}
+
+		dist := e.At.Sub(at)
+		if dist < 0 {
+			dist = -dist
+		}
+		if result == nil || dist < best {
+			result, best = e, dist
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testEvents = []Event{
+	{At: time.Unix(1000, 0), Name: "deploy v1"},
+	{At: time.Unix(1100, 0), Name: "deploy v2"},
+	{At: time.Unix(1300, 0), Name: "config change"},
+}
+
+func TestNearestEventBase(t *testing.T) {
+	result := nearestEvent(testEvents, time.Unix(1090, 0), time.Unix(900, 0), time.Unix(1400, 0))
+
+	assert.Equal(t, &testEvents[1], result)
+}
+
+func TestNearestEventRange(t *testing.T) {
+	result := nearestEvent(testEvents, time.Unix(1090, 0), time.Unix(1200, 0), time.Unix(1400, 0))
+
+	assert.Equal(t, &testEvents[2], result)
+}
+
+func TestNearestEventNone(t *testing.T) {
+	result := nearestEvent(testEvents, time.Unix(1500, 0), time.Unix(1400, 0), time.Unix(1600, 0))
+
+	assert.Nil(t, result)
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build !timeitnoop
+// +build !timeitnoop
+
+package timeit_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/klmitch/timeit"
+)
+
+func ExampleData_TimeIt() {
+	d := &timeit.Data{
+		Flags: timeit.SampleStdDev,
+		Clock: &timeit.FakeClock{
+			Step:   10 * time.Millisecond,
+			Jitter: 5 * time.Millisecond,
+			Seed:   1,
+		},
+	}
+
+	for i := 0; i < 5; i++ {
+		d.TimeIt(func() {})
+	}
+
+	text, _ := json.Marshal(d)
+	fmt.Println(string(text))
+	// Output:
+	// {"samples":5,"mean":12425228,"max":13117216,"min":11145821,"sample_std_dev":811571}
+}
+
+func ExampleData_WriteBenchmark() {
+	d := &timeit.Data{
+		Clock: &timeit.FakeClock{Step: time.Millisecond},
+	}
+
+	for i := 0; i < 100; i++ {
+		d.TimeIt(func() {})
+	}
+
+	_ = d.WriteBenchmark(os.Stdout, "noop")
+	// Output:
+	// BenchmarkNoop	100	1000000 ns/op
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"math"
+	"time"
+)
+
+// Explanation describes how much of the latency of an outer operation
+// is explained by a nested inner operation, such as a database query
+// made by a request handler.
+type Explanation struct {
+	Matched     int           // Number of operations present in both
+	Outer       time.Duration // Total latency of the matched outer operations
+	Inner       time.Duration // Total latency of the matched inner operations
+	Fraction    float64       // Fraction of the outer latency spent in the inner
+	Correlation float64       // Correlation between inner and outer latency
+}
+
+// Explain joins the latencies of an outer and an inner operation,
+// each keyed by a shared identifier such as a request ID, and
+// computes what fraction of the outer latency is spent in the inner
+// operation.  If an outer operation performs the inner operation
+// more than once, the inner latencies for that identifier should be
+// summed.  Identifiers present in only one of the maps are ignored.
+// The Correlation indicates whether the inner operation also
+// explains the variation in the outer latency: a high fraction with
+// a low correlation suggests the inner operation is a constant cost,
+// while a high correlation suggests that it is responsible for slow
+// outer operations.
+func Explain(outer, inner map[string]time.Duration) Explanation {
+	result := Explanation{}
+
+	// Join the operations
+	var sumX, sumY, sumXX, sumYY, sumXY float64
+	for id, o := range outer {
+		i, ok := inner[id]
+		if !ok {
+			continue
+		}
+
+		result.Matched++
+		result.Outer += o
+		result.Inner += i
+
+		x, y := float64(i), float64(o)
+		sumX += x
+		sumY += y
+		sumXX += x * x
+		sumYY += y * y
+		sumXY += x * y
+	}
+
+	// Compute the fraction
+	if result.Outer > 0 {
+		result.Fraction = float64(result.Inner) / float64(result.Outer)
+	}
+
+	// Compute the Pearson correlation coefficient
+	n := float64(result.Matched)
+	denom := math.Sqrt(n*sumXX-sumX*sumX) * math.Sqrt(n*sumYY-sumY*sumY)
+	if denom > 0 {
+		result.Correlation = (n*sumXY - sumX*sumY) / denom
+	}
+
+	return result
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplainBase(t *testing.T) {
+	result := Explain(map[string]time.Duration{
+		"req1": 100,
+		"req2": 200,
+		"req3": 300,
+		"req4": 400,
+	}, map[string]time.Duration{
+		"req1": 50,
+		"req2": 150,
+		"req3": 250,
+		"req5": 1000,
+	})
+
+	assert.Equal(t, 3, result.Matched)
+	assert.Equal(t, time.Duration(600), result.Outer)
+	assert.Equal(t, time.Duration(450), result.Inner)
+	assert.InDelta(t, 0.75, result.Fraction, 0.0001)
+	assert.InDelta(t, 1.0, result.Correlation, 0.0001)
+}
+
+func TestExplainUncorrelated(t *testing.T) {
+	result := Explain(map[string]time.Duration{
+		"req1": 100,
+		"req2": 200,
+	}, map[string]time.Duration{
+		"req1": 50,
+		"req2": 50,
+	})
+
+	assert.Equal(t, Explanation{
+		Matched:  2,
+		Outer:    300,
+		Inner:    100,
+		Fraction: float64(100) / float64(300),
+	}, result)
+}
+
+func TestExplainEmpty(t *testing.T) {
+	result := Explain(map[string]time.Duration{
+		"req1": 100,
+	}, map[string]time.Duration{
+		"req2": 50,
+	})
+
+	assert.Equal(t, Explanation{}, result)
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+// extremes records the time at which a new minimum or maximum sample
+// was taken, if Extremes is set, and keeps the previous maxima if
+// requested.  It must be called before the sample is added to the
+// statistics.  If the sample's timestamp is the zero time, the Data's
+// Clock is consulted; since new extremes are rare, this adds little
+// to the cost of most updates.  Knowing when the extremes were taken
+// makes them actionable, e.g., a maximum can be correlated with a
+// deployment.
+func (d *Data) extremes(s Sample) {
+	if !d.Extremes {
+		return
+	}
+
+	newMin := d.Samples == 0 || s.Duration < d.Min
+	newMax := d.Samples == 0 || s.Duration > d.Max
+	if !newMin && !newMax {
+		return
+	}
+	if s.At.IsZero() {
+		s.At = d.clock().Now()
+	}
+
+	if newMin {
+		d.MinAt = s.At
+	}
+	if newMax {
+		if d.Samples > 0 && d.Maxima > 0 {
+			if len(d.maxima) >= d.Maxima {
+				d.maxima = append(d.maxima[:0], d.maxima[len(d.maxima)-d.Maxima+1:]...)
+			}
+			d.maxima = append(d.maxima, Sample{At: d.MaxAt, Duration: d.Max})
+		}
+		d.MaxAt = s.At
+	}
+}
+
+// PreviousMaxima returns the samples that were the maximum before
+// being superseded by a larger sample, oldest first.  Previous maxima
+// are only kept if Extremes is set, and only the most recent Maxima
+// are kept; only the timestamps and durations of the samples are
+// recorded.  Together with Max and MaxAt, these show when the worst
+// samples were taken.  Previous maxima are not merged by Merge.
+func (d *Data) PreviousMaxima() []Sample {
+	result := make([]Sample, len(d.maxima))
+	copy(result, d.maxima)
+
+	return result
+}
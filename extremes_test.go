@@ -0,0 +1,145 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build !timeitnoop
+// +build !timeitnoop
+
+package timeit
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataExtremesDisabled(t *testing.T) {
+	d := &Data{Clock: &testClock{now: time.Unix(1000, 0)}}
+
+	d.Update(10)
+
+	assert.True(t, d.MinAt.IsZero())
+	assert.True(t, d.MaxAt.IsZero())
+}
+
+func TestDataExtremesBase(t *testing.T) {
+	clock := &FakeClock{Time: time.Unix(1000, 0), Step: time.Second}
+	d := &Data{Clock: clock, Extremes: true}
+
+	d.Update(20)
+	d.Update(30)
+	d.Update(25)
+	d.Update(10)
+
+	assert.Equal(t, time.Unix(1002, 0), d.MinAt)
+	assert.Equal(t, time.Unix(1001, 0), d.MaxAt)
+	assert.Equal(t, time.Unix(1003, 0), clock.Time)
+	assert.Empty(t, d.PreviousMaxima())
+}
+
+func TestDataExtremesSampleTime(t *testing.T) {
+	d := &Data{Clock: &testClock{now: time.Unix(1000, 0)}, Extremes: true}
+
+	d.UpdateAt(time.Unix(500, 0), 10)
+	d.UpdateAt(time.Unix(600, 0), 20)
+
+	assert.Equal(t, time.Unix(500, 0), d.MinAt)
+	assert.Equal(t, time.Unix(600, 0), d.MaxAt)
+}
+
+func TestDataExtremesMaxima(t *testing.T) {
+	d := &Data{Extremes: true, Maxima: 2}
+
+	for i, s := range []time.Duration{10, 20, 15, 30, 40} {
+		d.UpdateAt(time.Unix(int64(1000+i), 0), s)
+	}
+
+	assert.Equal(t, time.Duration(40), d.Max)
+	assert.Equal(t, time.Unix(1004, 0), d.MaxAt)
+	assert.Equal(t, []Sample{
+		{At: time.Unix(1001, 0), Duration: 20},
+		{At: time.Unix(1003, 0), Duration: 30},
+	}, d.PreviousMaxima())
+}
+
+func TestDataExtremesUpdateChecked(t *testing.T) {
+	d := &Data{Clock: &testClock{now: time.Unix(1000, 0)}, Extremes: true}
+
+	err := d.UpdateChecked(10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1000, 0), d.MinAt)
+	assert.Equal(t, time.Unix(1000, 0), d.MaxAt)
+}
+
+func TestDataExtremesMarshalJSON(t *testing.T) {
+	d := &Data{Extremes: true, Flags: StdDev}
+	d.UpdateAt(time.Unix(1000, 0).UTC(), 10)
+	d.UpdateAt(time.Unix(1001, 0).UTC(), 20)
+
+	text, err := json.Marshal(d)
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+	"samples": 2,
+	"mean": 15,
+	"max": 20,
+	"max_at": "1970-01-01T00:16:41Z",
+	"min": 10,
+	"min_at": "1970-01-01T00:16:40Z",
+	"std_dev": 5
+}`, string(text))
+	result := &Data{}
+	err = json.Unmarshal(text, result)
+	require.NoError(t, err)
+	assert.Equal(t, d.MinAt, result.MinAt)
+	assert.Equal(t, d.MaxAt, result.MaxAt)
+}
+
+func TestDataMergeExtremes(t *testing.T) {
+	d := &Data{Extremes: true}
+	d.UpdateAt(time.Unix(1000, 0), 20)
+	other := &Data{Extremes: true}
+	other.UpdateAt(time.Unix(1001, 0), 10)
+	other.UpdateAt(time.Unix(1002, 0), 30)
+
+	d.Merge(other)
+
+	assert.Equal(t, time.Unix(1001, 0), d.MinAt)
+	assert.Equal(t, time.Unix(1002, 0), d.MaxAt)
+}
+
+func TestDataMergeExtremesEmpty(t *testing.T) {
+	d := &Data{}
+	other := &Data{Extremes: true}
+	other.UpdateAt(time.Unix(1001, 0), 10)
+
+	d.Merge(other)
+
+	assert.Equal(t, time.Unix(1001, 0), d.MinAt)
+	assert.Equal(t, time.Unix(1001, 0), d.MaxAt)
+}
+
+func TestRunInfoScaleDataExtremes(t *testing.T) {
+	d := &Data{Extremes: true}
+	d.UpdateAt(time.Unix(1001, 0), 10)
+	ri := &RunInfo{}
+
+	result := ri.ScaleData(d, &RunInfo{})
+
+	assert.Equal(t, time.Unix(1001, 0), result.MinAt)
+	assert.Equal(t, time.Unix(1001, 0), result.MaxAt)
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+// Freeze marks the Data as read-only.  Subsequent samples passed to
+// Update are discarded, although they are still passed on to Next,
+// and attempts to unmarshal into the Data fail with ErrFrozen.  This
+// prevents accidental mutation of results that are being compared
+// against or have already been serialized.  When the package is built
+// with the "timeitdebug" build tag, Update panics instead of
+// discarding the sample, to help locate the offending code.
+func (d *Data) Freeze() {
+	d.frozen = true
+}
+
+// Frozen returns true if the Data has been frozen.
+func (d *Data) Frozen() bool {
+	return d.frozen
+}
+
+// checkFrozen is called by Update to check whether the Data is
+// frozen.  It returns true if the sample should be discarded, and
+// panics in debug builds.
+func (d *Data) checkFrozen() bool {
+	if d.frozen && debugBuild {
+		panic(ErrFrozen)
+	}
+
+	return d.frozen
+}
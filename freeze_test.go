@@ -0,0 +1,88 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestDataFreeze(t *testing.T) {
+	d := &Data{}
+
+	d.Freeze()
+
+	assert.True(t, d.frozen)
+	assert.True(t, d.Frozen())
+}
+
+func TestDataFrozenFalse(t *testing.T) {
+	d := &Data{}
+
+	assert.False(t, d.Frozen())
+}
+
+func TestDataCheckFrozenFalse(t *testing.T) {
+	d := &Data{}
+
+	assert.False(t, d.checkFrozen())
+}
+
+func TestDataUpdateFrozen(t *testing.T) {
+	if debugBuild {
+		t.Skip("Update panics in debug builds")
+	}
+	if !Enabled() {
+		t.Skip("Update discards samples in noop builds")
+	}
+	d := &Data{
+		Next:   &Data{},
+		frozen: true,
+	}
+
+	d.Update(time.Duration(50))
+
+	assert.Equal(t, &Data{
+		Next: &Data{
+			Samples: 1,
+			Mean:    time.Duration(50),
+			Max:     time.Duration(50),
+			Min:     time.Duration(50),
+		},
+		frozen: true,
+	}, d)
+}
+
+func TestDataUnmarshalJSONFrozen(t *testing.T) {
+	d := &Data{frozen: true}
+
+	err := json.Unmarshal([]byte(`{"samples": 3}`), d)
+
+	assert.ErrorIs(t, err, ErrFrozen)
+	assert.Equal(t, &Data{frozen: true}, d)
+}
+
+func TestDataUnmarshalYAMLFrozen(t *testing.T) {
+	d := &Data{frozen: true}
+
+	err := yaml.Unmarshal([]byte(`samples: 3`), d)
+
+	assert.ErrorIs(t, err, ErrFrozen)
+	assert.Equal(t, &Data{frozen: true}, d)
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"fmt"
+	"strconv"
+	"text/template"
+	"time"
+)
+
+// humanDuration formats a duration with three significant digits in
+// the most appropriate unit, e.g., "1.23ms", rather than with the
+// full precision used by time.Duration.String.  Durations of a minute
+// or more are rounded to the second.
+func humanDuration(d time.Duration) string {
+	abs := d
+	if abs < 0 {
+		abs = -abs
+	}
+
+	// Select the unit
+	var unit time.Duration
+	var suffix string
+	switch {
+	case abs < time.Microsecond:
+		return d.String()
+	case abs < time.Millisecond:
+		unit, suffix = time.Microsecond, "µs"
+	case abs < time.Second:
+		unit, suffix = time.Millisecond, "ms"
+	case abs < time.Minute:
+		unit, suffix = time.Second, "s"
+	default:
+		return d.Round(time.Second).String()
+	}
+
+	// Select the precision
+	v := float64(d) / float64(unit)
+	prec := 0
+	if abs < 10*unit {
+		prec = 2
+	} else if abs < 100*unit {
+		prec = 1
+	}
+
+	return strconv.FormatFloat(v, 'f', prec, 64) + suffix
+}
+
+// percentChange formats the change from one duration to another as a
+// signed percentage, e.g., "+12.3%".  If the original duration is
+// zero, the change is undefined, and "-" is returned.
+func percentChange(from, to time.Duration) string {
+	if from == 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf("%+.1f%%", 100*float64(to-from)/float64(from))
+}
+
+// stars formats a p-value as the conventional significance stars:
+// "***" for p < 0.001, "**" for p < 0.01, "*" for p < 0.05, and an
+// empty string otherwise.
+func stars(p float64) string {
+	switch {
+	case p < 0.001:
+		return "***"
+	case p < 0.01:
+		return "**"
+	case p < 0.05:
+		return "*"
+	}
+
+	return ""
+}
+
+// FuncMap returns a set of formatting functions for use in report
+// templates, suitable for passing to the Funcs method of
+// text/template, or of html/template after conversion to
+// html/template.FuncMap.  The functions are:
+//
+//	duration        Formats a duration with three significant digits
+//	percentChange   Formats the change between two durations
+//	stars           Formats a p-value as significance stars
+//
+// A new map is returned on each call, so callers may add their own
+// functions to it.
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"duration":      humanDuration,
+		"percentChange": percentChange,
+		"stars":         stars,
+	}
+}
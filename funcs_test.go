@@ -0,0 +1,86 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHumanDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                                     "0s",
+		999:                                   "999ns",
+		1234:                                  "1.23µs",
+		12345:                                 "12.3µs",
+		123456:                                "123µs",
+		1234567:                               "1.23ms",
+		-1234567:                              "-1.23ms",
+		12345678:                              "12.3ms",
+		123456789:                             "123ms",
+		1234567890:                            "1.23s",
+		12345678900:                           "12.3s",
+		59 * time.Second:                      "59.0s",
+		83*time.Second + 600*time.Millisecond: "1m24s",
+	}
+	for d, expected := range tests {
+		assert.Equal(t, expected, humanDuration(d), "duration %d", int64(d))
+	}
+}
+
+func TestPercentChangeBase(t *testing.T) {
+	assert.Equal(t, "+12.5%", percentChange(800, 900))
+	assert.Equal(t, "-50.0%", percentChange(800, 400))
+	assert.Equal(t, "+0.0%", percentChange(800, 800))
+}
+
+func TestPercentChangeZero(t *testing.T) {
+	result := percentChange(0, 800)
+
+	assert.Equal(t, "-", result)
+}
+
+func TestStars(t *testing.T) {
+	assert.Equal(t, "***", stars(0.0005))
+	assert.Equal(t, "**", stars(0.005))
+	assert.Equal(t, "*", stars(0.03))
+	assert.Equal(t, "", stars(0.05))
+}
+
+func TestFuncMap(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tmpl, err := template.New("test").Funcs(FuncMap()).Parse(
+		`{{duration .Mean}} {{percentChange 1000 .Mean}} {{stars 0.01}}`)
+	require.NoError(t, err)
+
+	err = tmpl.Execute(buf, &Data{Mean: 1234567})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1.23ms +123356.7% *", buf.String())
+}
+
+func TestFuncMapFresh(t *testing.T) {
+	fm := FuncMap()
+	fm["extra"] = func() string { return "" }
+
+	result := FuncMap()
+
+	assert.NotContains(t, result, "extra")
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Distribution describes a distribution from which synthetic samples
+// may be drawn; see Generator.
+type Distribution interface {
+	// Draw draws a sample from the distribution using the random
+	// number generator.
+	Draw(rng *rand.Rand) time.Duration
+}
+
+// ConstantDist is a Distribution that always yields the same sample.
+type ConstantDist time.Duration
+
+// Draw draws a sample from the distribution.
+func (cd ConstantDist) Draw(rng *rand.Rand) time.Duration {
+	return time.Duration(cd)
+}
+
+// NormalDist is a normal Distribution.  Since durations measured by
+// timers cannot be negative, negative samples are clamped to 0.
+type NormalDist struct {
+	Mean   time.Duration // Mean of the distribution
+	StdDev time.Duration // Standard deviation of the distribution
+}
+
+// Draw draws a sample from the distribution.
+func (nd NormalDist) Draw(rng *rand.Rand) time.Duration {
+	sample := time.Duration(float64(nd.Mean) + float64(nd.StdDev)*rng.NormFloat64())
+	if sample < 0 {
+		return 0
+	}
+
+	return sample
+}
+
+// LognormalDist is a lognormal Distribution, the typical shape of
+// request latencies: the logarithm of the samples is normally
+// distributed, with mean log(Median) and standard deviation Sigma.
+type LognormalDist struct {
+	Median time.Duration // Median of the distribution
+	Sigma  float64       // Standard deviation of the log of the samples
+}
+
+// Draw draws a sample from the distribution.
+func (ld LognormalDist) Draw(rng *rand.Rand) time.Duration {
+	return time.Duration(float64(ld.Median) * math.Exp(ld.Sigma*rng.NormFloat64()))
+}
+
+// MixtureDist is a Distribution that draws from one of two other
+// distributions, such as for a bimodal distribution with a fast path
+// and a slow path; Fraction of the samples are drawn from Second.
+// With a ConstantDist as Second and a small Fraction, it produces
+// occasional spikes.
+type MixtureDist struct {
+	First    Distribution // Distribution for most samples
+	Second   Distribution // Distribution for the remainder
+	Fraction float64      // Fraction of samples drawn from Second
+}
+
+// Draw draws a sample from the distribution.
+func (md MixtureDist) Draw(rng *rand.Rand) time.Duration {
+	if rng.Float64() < md.Fraction {
+		return md.Second.Draw(rng)
+	}
+
+	return md.First.Draw(rng)
+}
+
+// Generator produces a deterministic stream of synthetic samples from
+// a Distribution, for feeding into Data and the analyses in tests and
+// demos.  The samples are drawn from a sequence seeded by Seed, so
+// they are the same on every run, and are timestamped Interval apart
+// starting at Start.
+type Generator struct {
+	Dist     Distribution  // Distribution to draw samples from
+	Seed     int64         // Seed for the random sequence
+	Start    time.Time     // Timestamp of the first sample
+	Interval time.Duration // Interval between samples
+	rng      *rand.Rand
+	count    int64
+}
+
+// Next returns the next sample.
+func (g *Generator) Next() Sample {
+	if g.rng == nil {
+		g.rng = rand.New(rand.NewSource(g.Seed)) //nolint:gosec
+	}
+
+	s := Sample{
+		At:       g.Start.Add(time.Duration(g.count) * g.Interval),
+		Duration: g.Dist.Draw(g.rng),
+	}
+	g.count++
+
+	return s
+}
+
+// Samples returns the next n samples.
+func (g *Generator) Samples(n int) []Sample {
+	result := make([]Sample, n)
+	for i := range result {
+		result[i] = g.Next()
+	}
+
+	return result
+}
+
+// Fill updates the Data with the next n samples, as UpdateAt would,
+// but even if instrumentation is disabled (see Enable).
+func (g *Generator) Fill(d *Data, n int) {
+	for i := 0; i < n; i++ {
+		s := g.Next()
+		d.updateSample(Sample{At: s.At, Duration: s.Duration})
+	}
+}
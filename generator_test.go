@@ -0,0 +1,112 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstantDistDraw(t *testing.T) {
+	result := ConstantDist(5).Draw(nil)
+
+	assert.Equal(t, time.Duration(5), result)
+}
+
+func TestNormalDistDrawBase(t *testing.T) {
+	rng := rand.New(rand.NewSource(1)) //nolint:gosec
+	d := &Data{}
+	nd := NormalDist{Mean: time.Millisecond, StdDev: 10 * time.Microsecond}
+
+	for i := 0; i < 1000; i++ {
+		d.updateSample(Sample{Duration: nd.Draw(rng)})
+	}
+
+	assert.InDelta(t, float64(time.Millisecond), float64(d.Mean), float64(time.Microsecond))
+	assert.InDelta(t, float64(10*time.Microsecond), float64(d.SampleStdDev()), float64(time.Microsecond))
+}
+
+func TestNormalDistDrawClamp(t *testing.T) {
+	rng := rand.New(rand.NewSource(1)) //nolint:gosec
+	nd := NormalDist{Mean: -time.Second, StdDev: 1}
+
+	result := nd.Draw(rng)
+
+	assert.Equal(t, time.Duration(0), result)
+}
+
+func TestLognormalDistDraw(t *testing.T) {
+	rng := rand.New(rand.NewSource(1)) //nolint:gosec
+	ld := LognormalDist{Median: time.Millisecond, Sigma: 0.5}
+	d := &Data{Retain: 1001}
+
+	for i := 0; i < 1001; i++ {
+		d.updateSample(Sample{Duration: ld.Draw(rng)})
+	}
+
+	assert.InDelta(t, float64(time.Millisecond), float64(d.Percentile(50)), float64(50*time.Microsecond))
+}
+
+func TestMixtureDistDraw(t *testing.T) {
+	rng := rand.New(rand.NewSource(1)) //nolint:gosec
+	md := MixtureDist{First: ConstantDist(1), Second: ConstantDist(2), Fraction: 0.25}
+	counts := map[time.Duration]int{}
+
+	for i := 0; i < 1000; i++ {
+		counts[md.Draw(rng)]++
+	}
+
+	assert.Len(t, counts, 2)
+	assert.InDelta(t, 250, counts[2], 50)
+}
+
+func TestGeneratorSamples(t *testing.T) {
+	g := &Generator{
+		Dist:     NormalDist{Mean: 100, StdDev: 10},
+		Seed:     7,
+		Start:    time.Unix(1000, 0),
+		Interval: time.Second,
+	}
+	other := &Generator{Dist: g.Dist, Seed: 7}
+
+	result := g.Samples(3)
+
+	assert.Len(t, result, 3)
+	for i, s := range result {
+		assert.Equal(t, time.Unix(1000+int64(i), 0), s.At)
+		assert.Equal(t, other.Next().Duration, s.Duration)
+	}
+}
+
+func TestGeneratorFill(t *testing.T) {
+	g := &Generator{
+		Dist:     ConstantDist(100),
+		Start:    time.Unix(1000, 0),
+		Interval: time.Second,
+	}
+	d := &Data{Retain: 2}
+
+	g.Fill(d, 3)
+
+	assert.Equal(t, int64(3), d.Samples)
+	assert.Equal(t, time.Duration(100), d.Mean)
+	assert.Equal(t, []Sample{
+		{At: time.Unix(1001, 0), Duration: 100},
+		{At: time.Unix(1002, 0), Duration: 100},
+	}, d.Retained())
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// confidenceZ is the critical value of the normal distribution for a
+// 95% confidence interval.
+const confidenceZ = 1.96
+
+// significant tests whether the difference between the means of two
+// Data is statistically significant, that is, whether the approximate
+// 95% confidence interval for the difference excludes zero.  The
+// second return value is false if either Data has fewer than two
+// samples, in which case significance cannot be determined.
+func significant(from, to *Data) (bool, bool) {
+	if from.Samples < 2 || to.Samples < 2 {
+		return false, false
+	}
+
+	se := math.Sqrt(float64(from.m2)/float64(from.Samples-1)/float64(from.Samples) +
+		float64(to.m2)/float64(to.Samples-1)/float64(to.Samples))
+
+	return math.Abs(float64(to.Mean-from.Mean)) > confidenceZ*se, true
+}
+
+// GroupBy groups a set of Results by the value of the specified
+// label, merging the Data of the Results in each group with
+// Data.Merge.  Results without the label are grouped under the empty
+// string.  The Data of the Results are not modified.
+func GroupBy(results []*Result, label string) map[string]*Data {
+	groups := map[string]*Data{}
+	for _, r := range results {
+		value := r.Labels[label]
+		d, ok := groups[value]
+		if !ok {
+			d = &Data{}
+			groups[value] = d
+		}
+		d.Merge(r.Data)
+	}
+
+	return groups
+}
+
+// WriteComparison writes a table comparing a set of groups, such as
+// those returned by GroupBy, to a control group, using the default
+// NumberFormat.  The control group is listed first, followed by the
+// other groups sorted by name; for each group, the change in the
+// mean relative to the control group is shown.  If the writer is a
+// terminal, the changes are colored (see UseColor).  An error
+// wrapping ErrUnknownGroup is returned if the control group is not
+// present.  Changes which are not statistically significant are
+// shown as "~"; see Regressions.
+func WriteComparison(w io.Writer, groups map[string]*Data, control string) error {
+	return (&NumberFormat{Color: UseColor(w)}).WriteComparison(w, groups, control)
+}
+
+// WriteComparison is similar to the WriteComparison function, but
+// formats the table using the NumberFormat.
+func (nf *NumberFormat) WriteComparison(w io.Writer, groups map[string]*Data, control string) error {
+	ctl, ok := groups[control]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownGroup, control)
+	}
+
+	// Sort the group names, putting the control group first
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		if name != control {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	names = append([]string{control}, names...)
+
+	// Build the table
+	rows := [][]string{{"GROUP", "SAMPLES", "MEAN", "STDDEV", "DELTA"}}
+	colors := []string{""}
+	for _, name := range names {
+		d := groups[name]
+		delta, color := "control", ""
+		if name != control {
+			delta, color = nf.Percent(ctl.Mean, d.Mean), nf.deltaColor(ctl.Mean, d.Mean)
+			if sig, ok := significant(ctl, d); ok && !sig {
+				delta, color = "~", nf.dim()
+			}
+		}
+		rows = append(rows, []string{name, nf.Int(d.Samples), nf.Duration(d.Mean), nf.Duration(d.SampleStdDev()), delta})
+		colors = append(colors, color)
+	}
+
+	return nf.writeTable(w, rows, colors)
+}
+
+// Regressions returns the sorted names of the groups, such as those
+// returned by GroupBy, whose mean is greater than that of the control
+// group by more than the tolerance, expressed as a fraction of the
+// control group's mean, and for which the change is statistically
+// significant.  Groups with fewer than two samples are never reported,
+// as the significance of their change cannot be determined.  This is
+// suitable for gating continuous integration on performance without
+// failing on changes within the noise.  An error wrapping
+// ErrUnknownGroup is returned if the control group is not present.
+func Regressions(groups map[string]*Data, control string, tolerance float64) ([]string, error) {
+	ctl, ok := groups[control]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownGroup, control)
+	}
+
+	names := []string{}
+	for name, d := range groups {
+		if name == control || d.Mean <= ctl.Mean ||
+			float64(d.Mean-ctl.Mean) <= tolerance*float64(ctl.Mean) {
+			continue
+		}
+		if sig, ok := significant(ctl, d); ok && sig {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
@@ -0,0 +1,231 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupBy(t *testing.T) {
+	results := []*Result{
+		{
+			Name:   "a",
+			Data:   &Data{Samples: 1, Mean: 10, Max: 10, Min: 10},
+			Labels: map[string]string{"backend": "memory"},
+		},
+		{
+			Name:   "b",
+			Data:   &Data{Samples: 1, Mean: 30, Max: 30, Min: 30},
+			Labels: map[string]string{"backend": "memory"},
+		},
+		{
+			Name:   "c",
+			Data:   &Data{Samples: 1, Mean: 40, Max: 40, Min: 40},
+			Labels: map[string]string{"backend": "disk"},
+		},
+		{
+			Name: "d",
+			Data: &Data{Samples: 1, Mean: 50, Max: 50, Min: 50},
+		},
+	}
+
+	result := GroupBy(results, "backend")
+
+	assert.Equal(t, map[string]*Data{
+		"memory": {Samples: 2, Mean: 20, Max: 30, Min: 10, m2: 200},
+		"disk":   {Samples: 1, Mean: 40, Max: 40, Min: 40},
+		"":       {Samples: 1, Mean: 50, Max: 50, Min: 50},
+	}, result)
+	assert.Equal(t, &Data{Samples: 1, Mean: 10, Max: 10, Min: 10}, results[0].Data)
+}
+
+func TestSignificantBase(t *testing.T) {
+	from := &Data{Samples: 10, Mean: 100, m2: 900}
+	to := &Data{Samples: 10, Mean: 110, m2: 900}
+
+	sig, ok := significant(from, to)
+
+	assert.True(t, sig)
+	assert.True(t, ok)
+}
+
+func TestSignificantNoise(t *testing.T) {
+	from := &Data{Samples: 10, Mean: 100, m2: 9000}
+	to := &Data{Samples: 10, Mean: 110, m2: 9000}
+
+	sig, ok := significant(from, to)
+
+	assert.False(t, sig)
+	assert.True(t, ok)
+}
+
+func TestSignificantTooFewSamples(t *testing.T) {
+	from := &Data{Samples: 1, Mean: 100}
+	to := &Data{Samples: 10, Mean: 110, m2: 900}
+
+	sig, ok := significant(from, to)
+
+	assert.False(t, sig)
+	assert.False(t, ok)
+}
+
+func TestWriteComparisonBase(t *testing.T) {
+	buf := &bytes.Buffer{}
+	groups := map[string]*Data{
+		"memory": {Samples: 2, Mean: 200, Max: 300, Min: 100, m2: 20000},
+		"disk":   {Samples: 1, Mean: 400, Max: 400, Min: 400},
+		"cache":  {Samples: 1, Mean: 100, Max: 100, Min: 100},
+	}
+
+	err := WriteComparison(buf, groups, "memory")
+
+	assert.NoError(t, err)
+	assert.Equal(t, `GROUP   SAMPLES  MEAN   STDDEV  DELTA
+memory  2        200ns  141ns   control
+cache   1        100ns  0s      -50.0%
+disk    1        400ns  0s      +100.0%
+`, buf.String())
+}
+
+func TestWriteComparisonZeroControl(t *testing.T) {
+	buf := &bytes.Buffer{}
+	groups := map[string]*Data{
+		"memory": {},
+		"disk":   {Samples: 1, Mean: 400, Max: 400, Min: 400},
+	}
+
+	err := WriteComparison(buf, groups, "memory")
+
+	assert.NoError(t, err)
+	assert.Equal(t, `GROUP   SAMPLES  MEAN   STDDEV  DELTA
+memory  0        0s     0s      control
+disk    1        400ns  0s      -
+`, buf.String())
+}
+
+func TestWriteComparisonUnknownControl(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	err := WriteComparison(buf, map[string]*Data{}, "memory")
+
+	assert.ErrorIs(t, err, ErrUnknownGroup)
+	assert.EqualError(t, err, `unknown group: "memory"`)
+}
+
+func TestWriteComparisonError(t *testing.T) {
+	err := WriteComparison(errWriter{}, map[string]*Data{"memory": {}}, "memory")
+
+	assert.Same(t, assert.AnError, err)
+}
+
+func TestWriteComparisonNotSignificant(t *testing.T) {
+	buf := &bytes.Buffer{}
+	groups := map[string]*Data{
+		"memory": {Samples: 10, Mean: 100, Max: 200, Min: 10, m2: 9000},
+		"disk":   {Samples: 10, Mean: 110, Max: 200, Min: 10, m2: 9000},
+	}
+
+	err := WriteComparison(buf, groups, "memory")
+
+	assert.NoError(t, err)
+	assert.Equal(t, `GROUP   SAMPLES  MEAN   STDDEV  DELTA
+memory  10       100ns  31ns    control
+disk    10       110ns  31ns    ~
+`, buf.String())
+}
+
+func TestNumberFormatWriteComparison(t *testing.T) {
+	buf := &bytes.Buffer{}
+	groups := map[string]*Data{
+		"memory": {Samples: 2000, Mean: 2000000, Max: 3000000, Min: 1000000},
+		"disk":   {Samples: 1000, Mean: 4000000, Max: 4000000, Min: 4000000},
+	}
+	nf := &NumberFormat{
+		Unit:      time.Millisecond,
+		Decimals:  1,
+		Thousands: ",",
+		Width:     8,
+	}
+
+	err := nf.WriteComparison(buf, groups, "memory")
+
+	assert.NoError(t, err)
+	assert.Equal(t, `GROUP     SAMPLES     MEAN   STDDEV    DELTA
+memory      2,000    2.0ms    0.0ms  control
+disk        1,000    4.0ms    0.0ms  +100.0%
+`, buf.String())
+}
+
+func TestNumberFormatWriteComparisonColorNotSignificant(t *testing.T) {
+	buf := &bytes.Buffer{}
+	groups := map[string]*Data{
+		"memory": {Samples: 10, Mean: 100, Max: 200, Min: 10, m2: 9000},
+		"disk":   {Samples: 10, Mean: 110, Max: 200, Min: 10, m2: 9000},
+	}
+	nf := &NumberFormat{Color: true}
+
+	err := nf.WriteComparison(buf, groups, "memory")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "GROUP   SAMPLES  MEAN   STDDEV  DELTA\n"+
+		"memory  10       100ns  31ns    control\n"+
+		"disk    10       110ns  31ns    \x1b[2m~\x1b[0m\n", buf.String())
+}
+
+func TestNumberFormatWriteComparisonColor(t *testing.T) {
+	buf := &bytes.Buffer{}
+	groups := map[string]*Data{
+		"memory": {Samples: 1, Mean: 200, Max: 200, Min: 200},
+		"disk":   {Samples: 1, Mean: 400, Max: 400, Min: 400},
+		"cache":  {Samples: 1, Mean: 100, Max: 100, Min: 100},
+	}
+	nf := &NumberFormat{Color: true}
+
+	err := nf.WriteComparison(buf, groups, "memory")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "GROUP   SAMPLES  MEAN   STDDEV  DELTA\n"+
+		"memory  1        200ns  0s      control\n"+
+		"cache   1        100ns  0s      \x1b[32m-50.0%\x1b[0m\n"+
+		"disk    1        400ns  0s      \x1b[1;31m+100.0%\x1b[0m\n", buf.String())
+}
+
+func TestRegressionsBase(t *testing.T) {
+	groups := map[string]*Data{
+		"memory": {Samples: 10, Mean: 100, m2: 900},
+		"disk":   {Samples: 10, Mean: 150, m2: 900},
+		"cache":  {Samples: 10, Mean: 50, m2: 900},
+		"tmpfs":  {Samples: 10, Mean: 104, m2: 900},
+		"remote": {Samples: 10, Mean: 150, m2: 900000},
+		"single": {Samples: 1, Mean: 200},
+		"nfs":    {Samples: 10, Mean: 120, m2: 900},
+	}
+
+	result, err := Regressions(groups, "memory", 0.05)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"disk", "nfs"}, result)
+}
+
+func TestRegressionsUnknownControl(t *testing.T) {
+	result, err := Regressions(map[string]*Data{}, "memory", 0)
+
+	assert.ErrorIs(t, err, ErrUnknownGroup)
+	assert.Nil(t, result)
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// FuncData contains the accumulated timing data for repeated
+// invocations of a function, such as a benchmark workload; it is the
+// counterpart of CmdData for code running in the same process.  If a
+// Timeout is set, an invocation that runs longer than the Timeout is
+// considered hung: it is abandoned and counted in Timeouts, rather
+// than being timed, so that one stuck invocation cannot wedge an
+// entire benchmark session.  Since a goroutine cannot be killed, an
+// abandoned invocation continues to run in the background until the
+// function returns.
+type FuncData struct {
+	Data           Data          // Time taken by the function
+	Progress       ProgressFunc  // Called after each invocation by Repeat; may be nil
+	Timeout        time.Duration // Time limit for each invocation; 0 for none
+	Timeouts       int64         // Number of invocations that exceeded the Timeout
+	AbortOnTimeout bool          // Set to have Repeat stop at a timeout
+}
+
+// Run invokes the function and updates the data with the time it
+// took.  If a Timeout is set, the function is invoked in its own
+// goroutine; if it exceeds the Timeout, it is abandoned and an error
+// wrapping ErrTimeout is returned.
+func (fd *FuncData) Run(fn func()) error {
+	if fd.Timeout <= 0 {
+		fd.Data.TimeIt(fn)
+		return nil
+	}
+
+	clock := fd.Data.clock()
+	start := clock.Now()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+
+	// Wait for the function or the timeout
+	timer := time.NewTimer(fd.Timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		// The function may have returned just as the timer fired
+		select {
+		case <-done:
+		default:
+			fd.Timeouts++
+			return fmt.Errorf("%w: function exceeded %s", ErrTimeout, fd.Timeout)
+		}
+	}
+
+	fd.Data.UpdateAt(start, elapsed(clock, start))
+
+	return nil
+}
+
+// Repeat invokes the function n times, updating the data for each
+// invocation.  Invocations that exceed the Timeout are skipped unless
+// AbortOnTimeout is set, in which case Repeat stops with the error.
+// If a Progress callback is set, it is called with the data after
+// each invocation.
+func (fd *FuncData) Repeat(n int, fn func()) error {
+	return fd.RepeatContext(context.Background(), n, fn)
+}
+
+// RepeatContext is similar to Repeat, but stops cleanly if the
+// context is canceled before all invocations are complete.  As with
+// CmdData.RepeatContext, the data accumulated by the completed
+// invocations is retained and flagged as Partial, and an error
+// wrapping ErrPartial is returned.
+func (fd *FuncData) RepeatContext(ctx context.Context, n int, fn func()) error {
+	for i := 0; i < n; i++ {
+		if err := ctx.Err(); err != nil {
+			fd.Data.Partial = true
+			return fmt.Errorf("%w: %d of %d runs completed: %s", ErrPartial, i, n, err)
+		}
+
+		if err := fd.Run(fn); err != nil && (fd.AbortOnTimeout || !errors.Is(err, ErrTimeout)) {
+			return err
+		}
+		if fd.Progress != nil {
+			fd.Progress(i+1, n, &fd.Data)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build !timeitnoop
+// +build !timeitnoop
+
+package timeit
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuncDataRunBase(t *testing.T) {
+	fd := &FuncData{}
+	fd.Data.Clock = &testClock{delta: 50}
+	called := false
+
+	err := fd.Run(func() { called = true })
+
+	assert.NoError(t, err)
+	assert.True(t, called)
+	assert.Equal(t, int64(1), fd.Data.Samples)
+	assert.Equal(t, time.Duration(50), fd.Data.Mean)
+}
+
+func TestFuncDataRunWithinTimeout(t *testing.T) {
+	fd := &FuncData{Timeout: 10 * time.Second}
+	fd.Data.Clock = &testClock{delta: 50}
+	called := false
+
+	err := fd.Run(func() { called = true })
+
+	assert.NoError(t, err)
+	assert.True(t, called)
+	assert.Equal(t, int64(0), fd.Timeouts)
+	assert.Equal(t, int64(1), fd.Data.Samples)
+	assert.Equal(t, time.Duration(50), fd.Data.Mean)
+}
+
+func TestFuncDataRunTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	fd := &FuncData{Timeout: 50 * time.Millisecond}
+
+	err := fd.Run(func() { <-release })
+
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.EqualError(t, err, "timed out: function exceeded 50ms")
+	assert.Equal(t, int64(1), fd.Timeouts)
+	assert.Equal(t, int64(0), fd.Data.Samples)
+}
+
+func TestFuncDataRepeatBase(t *testing.T) {
+	fd := &FuncData{}
+	count := 0
+
+	err := fd.Repeat(3, func() { count++ })
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.Equal(t, int64(3), fd.Data.Samples)
+}
+
+func TestFuncDataRepeatProgress(t *testing.T) {
+	calls := [][2]int{}
+	fd := &FuncData{}
+	fd.Progress = func(done, total int, d *Data) {
+		assert.Same(t, &fd.Data, d)
+		assert.Equal(t, int64(done), d.Samples)
+		calls = append(calls, [2]int{done, total})
+	}
+
+	err := fd.Repeat(3, func() {})
+
+	assert.NoError(t, err)
+	assert.Equal(t, [][2]int{{1, 3}, {2, 3}, {3, 3}}, calls)
+}
+
+func TestFuncDataRepeatContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fd := &FuncData{}
+	fd.Progress = func(done, total int, d *Data) {
+		if done == 2 {
+			cancel()
+		}
+	}
+
+	err := fd.RepeatContext(ctx, 5, func() {})
+
+	assert.ErrorIs(t, err, ErrPartial)
+	assert.EqualError(t, err, "measurement incomplete: 2 of 5 runs completed: context canceled")
+	assert.Equal(t, int64(2), fd.Data.Samples)
+	assert.True(t, fd.Data.Partial)
+}
+
+func TestFuncDataRepeatTimeoutContinue(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	fd := &FuncData{Timeout: 50 * time.Millisecond}
+	count := int32(0)
+
+	err := fd.Repeat(3, func() {
+		if atomic.AddInt32(&count, 1) == 2 {
+			<-release
+		}
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&count))
+	assert.Equal(t, int64(1), fd.Timeouts)
+	assert.Equal(t, int64(2), fd.Data.Samples)
+}
+
+func TestFuncDataRepeatTimeoutAbort(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	fd := &FuncData{Timeout: 50 * time.Millisecond, AbortOnTimeout: true}
+
+	err := fd.Repeat(3, func() { <-release })
+
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.Equal(t, int64(1), fd.Timeouts)
+	assert.Equal(t, int64(0), fd.Data.Samples)
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"os"
+	"runtime"
+)
+
+// heapSink prevents the compiler from optimizing away the heap
+// warming allocation.
+var heapSink []byte
+
+// PrepareHeap prepares the heap for stable measurements, reducing the
+// variance caused by the garbage collector being triggered at
+// different points in different runs.  If ballast is non-zero, a
+// ballast of that many bytes is allocated and retained, raising the
+// heap size at which garbage collection is triggered; the ballast is
+// not touched, so it consumes little physical memory.  If warm is
+// non-zero, that many bytes are allocated, touched, and discarded,
+// growing the heap so that the measurement does not pay for growing
+// it.  A garbage collection is then run so that measurement begins
+// with a clean heap.  The sizes are recorded in the RunInfo, so that
+// the results remain interpretable.  The ballast is released when the
+// returned function is called; the caller must retain the function
+// until the measurement is complete, e.g.,
+//
+//	defer ri.PrepareHeap(64<<20, 0)()
+func (ri *RunInfo) PrepareHeap(ballast, warm int) (release func()) {
+	var b []byte
+	if ballast > 0 {
+		b = make([]byte, ballast)
+	}
+
+	// Warm the heap by touching every page of a temporary buffer
+	if warm > 0 {
+		heapSink = make([]byte, warm)
+		for i := 0; i < len(heapSink); i += os.Getpagesize() {
+			heapSink[i] = 1
+		}
+		heapSink = nil
+	}
+	runtime.GC()
+
+	ri.Ballast = int64(ballast)
+	ri.HeapWarm = int64(warm)
+
+	return func() {
+		runtime.KeepAlive(b)
+		b = nil
+	}
+}
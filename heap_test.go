@@ -0,0 +1,41 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunInfoPrepareHeapBase(t *testing.T) {
+	ri := &RunInfo{}
+
+	release := ri.PrepareHeap(1<<20, 1<<20)
+	release()
+
+	assert.Equal(t, int64(1<<20), ri.Ballast)
+	assert.Equal(t, int64(1<<20), ri.HeapWarm)
+	assert.Nil(t, heapSink)
+}
+
+func TestRunInfoPrepareHeapNone(t *testing.T) {
+	ri := &RunInfo{Ballast: 5, HeapWarm: 5}
+
+	release := ri.PrepareHeap(0, 0)
+	release()
+
+	assert.Equal(t, &RunInfo{}, ri)
+}
@@ -0,0 +1,379 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// heatmapShades are the characters used to render heatmap cells, in
+// order of increasing count.
+const heatmapShades = " .:-=+*#%@"
+
+// DefaultHeatmapInterval is the width of each time interval of a
+// Heatmap with no Interval.
+const DefaultHeatmapInterval = time.Minute
+
+// HeatmapColumn contains the counts of samples falling into each
+// latency bucket during a single time interval.
+type HeatmapColumn struct {
+	Start  time.Time `json:"start"`  // Start of the time interval
+	Counts []int64   `json:"counts"` // Counts for each latency bucket
+}
+
+// Heatmap accumulates a two-dimensional grid of sample counts, with
+// one dimension being time intervals and the other being latency
+// buckets.  This allows the evolution of latency over time, such as
+// during an incident, to be inspected from a single object.  Each
+// column contains one more count than there are Bounds; the last
+// count is for samples exceeding the largest bound.
+type Heatmap struct {
+	Interval  time.Duration   // Width of each time interval; 0 for default
+	Bounds    []time.Duration // Upper bounds of latency buckets, ascending
+	Retention int             // Maximum intervals to keep; 0 for no limit
+	Clock     Clock           // Source of time for Update; nil for WallClock
+	Events    []Event         // Events to mark when rendered; may be nil
+	columns   []HeatmapColumn
+}
+
+// clock returns the Clock to use for the Heatmap.
+func (h *Heatmap) clock() Clock {
+	if h.Clock == nil {
+		return WallClock
+	}
+
+	return h.Clock
+}
+
+// interval returns the width of each time interval of the Heatmap.
+func (h *Heatmap) interval() time.Duration {
+	if h.Interval <= 0 {
+		return DefaultHeatmapInterval
+	}
+
+	return h.Interval
+}
+
+// bucket returns the index of the latency bucket a sample belongs to.
+func (h *Heatmap) bucket(sample time.Duration) int {
+	return sort.Search(len(h.Bounds), func(i int) bool {
+		return sample <= h.Bounds[i]
+	})
+}
+
+// newColumn constructs a new, empty column.
+func (h *Heatmap) newColumn(start time.Time) HeatmapColumn {
+	return HeatmapColumn{
+		Start:  start,
+		Counts: make([]int64, len(h.Bounds)+1),
+	}
+}
+
+// column returns the column for the interval containing the
+// specified time, creating it if necessary.  Returns nil if the time
+// falls in an interval that is no longer retained.
+func (h *Heatmap) column(t time.Time) *HeatmapColumn {
+	interval := h.interval()
+	start := t.Truncate(interval)
+
+	// Handle the common cases first: no columns, or the time falls
+	// after the last column
+	if len(h.columns) == 0 {
+		h.columns = append(h.columns, h.newColumn(start))
+		return &h.columns[0]
+	}
+	last := h.columns[len(h.columns)-1].Start
+	if start.After(last) {
+		// Fill in any gap with empty columns, unless the gap
+		// exceeds the retention
+		gap := int(start.Sub(last)/interval) - 1
+		if h.Retention > 0 && gap >= h.Retention {
+			h.columns = h.columns[:0]
+		} else {
+			for i := 1; i <= gap; i++ {
+				h.columns = append(h.columns, h.newColumn(last.Add(time.Duration(i)*interval)))
+			}
+		}
+		h.columns = append(h.columns, h.newColumn(start))
+
+		// Apply the retention
+		if h.Retention > 0 && len(h.columns) > h.Retention {
+			h.columns = append(h.columns[:0], h.columns[len(h.columns)-h.Retention:]...)
+		}
+
+		return &h.columns[len(h.columns)-1]
+	}
+
+	// Time is in the past; find its column
+	idx := len(h.columns) - 1 - int(last.Sub(start)/interval)
+	if idx < 0 {
+		return nil
+	}
+
+	return &h.columns[idx]
+}
+
+// Update adds a sample to the heatmap, in the time interval
+// containing the current time.
+func (h *Heatmap) Update(sample time.Duration) {
+	h.UpdateAt(h.clock().Now(), sample)
+}
+
+// UpdateAt adds a sample to the heatmap, in the time interval
+// containing the specified time.  Samples that fall into an interval
+// that is no longer retained are discarded.
+func (h *Heatmap) UpdateAt(t time.Time, sample time.Duration) {
+	if col := h.column(t); col != nil {
+		col.Counts[h.bucket(sample)]++
+	}
+}
+
+// Columns returns the retained columns of the heatmap, in time
+// order.  The returned slice must not be modified.
+func (h *Heatmap) Columns() []HeatmapColumn {
+	return h.columns
+}
+
+// heatmapMarshaled is the marshaled form of a Heatmap.
+type heatmapMarshaled struct {
+	Interval time.Duration   `json:"interval"`
+	Bounds   []time.Duration `json:"bounds"`
+	Columns  []HeatmapColumn `json:"columns"`
+}
+
+// MarshalJSON implements json.Marshaler and allows a Heatmap to be
+// serialized intelligibly as JSON.
+func (h *Heatmap) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&heatmapMarshaled{
+		Interval: h.interval(),
+		Bounds:   h.Bounds,
+		Columns:  h.columns,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler and allows a Heatmap to
+// be deserialized from JSON produced by MarshalJSON.  Only the
+// Interval, Bounds, and columns are restored; the Retention is
+// applied to later updates.
+func (h *Heatmap) UnmarshalJSON(text []byte) error {
+	// Implement the noop convention
+	if string(text) == "null" {
+		return nil
+	}
+
+	hm := &heatmapMarshaled{}
+	if err := json.Unmarshal(text, hm); err != nil {
+		return err
+	}
+
+	// Make sure the columns match the bounds
+	for _, col := range hm.Columns {
+		if len(col.Counts) != len(hm.Bounds)+1 {
+			return fmt.Errorf("%w: heatmap column at %s has %d counts for %d bounds", ErrBadImport, col.Start.Format(time.RFC3339), len(col.Counts), len(hm.Bounds))
+		}
+	}
+
+	h.Interval = hm.Interval
+	h.Bounds = hm.Bounds
+	h.columns = hm.Columns
+
+	return nil
+}
+
+// labels returns the labels for the latency buckets of the heatmap,
+// smallest latencies first, along with the width of the widest
+// label.
+func (h *Heatmap) labels() ([]string, int) {
+	labels := make([]string, len(h.Bounds)+1)
+	width := 0
+	for i := range labels {
+		if i < len(h.Bounds) {
+			labels[i] = fmt.Sprintf("<=%s", h.Bounds[i])
+		} else if len(h.Bounds) > 0 {
+			labels[i] = fmt.Sprintf(">%s", h.Bounds[len(h.Bounds)-1])
+		} else {
+			labels[i] = "all"
+		}
+		if len(labels[i]) > width {
+			width = len(labels[i])
+		}
+	}
+
+	return labels, width
+}
+
+// maxCount returns the largest count in any cell of the heatmap, or
+// 1 if the heatmap is empty.
+func (h *Heatmap) maxCount() int64 {
+	max := int64(1)
+	for _, col := range h.columns {
+		for _, count := range col.Counts {
+			if count > max {
+				max = count
+			}
+		}
+	}
+
+	return max
+}
+
+// eventColumn returns the index of the column containing the
+// specified event, or -1 if the event does not occur during a
+// retained interval.
+func (h *Heatmap) eventColumn(e Event) int {
+	if len(h.columns) == 0 || e.At.Before(h.columns[0].Start) {
+		return -1
+	}
+	idx := int(e.At.Sub(h.columns[0].Start) / h.interval())
+	if idx >= len(h.columns) {
+		return -1
+	}
+
+	return idx
+}
+
+// Render writes an ASCII rendering of the heatmap to the specified
+// writer.  Each row corresponds to a latency bucket, with the
+// largest latencies at the top, and each column to a time interval,
+// with the oldest interval at the left.  Denser cells are rendered
+// with heavier characters.  Events occurring during the rendered
+// intervals are marked with a "^" below the interval, and listed
+// after the heatmap.
+func (h *Heatmap) Render(w io.Writer) error {
+	labels, width := h.labels()
+	max := h.maxCount()
+
+	// Render each row, starting with the largest latencies
+	for i := len(labels) - 1; i >= 0; i-- {
+		row := &strings.Builder{}
+		fmt.Fprintf(row, "%*s |", width, labels[i])
+		for _, col := range h.columns {
+			// Scale the count, rounding up so that any
+			// non-zero count is visible
+			shade := (col.Counts[i]*int64(len(heatmapShades)-1) + max - 1) / max
+			row.WriteByte(heatmapShades[shade])
+		}
+		row.WriteByte('\n')
+
+		if _, err := io.WriteString(w, row.String()); err != nil {
+			return err
+		}
+	}
+
+	return h.renderEvents(w, width)
+}
+
+// renderEvents writes the event markers and the list of events below
+// a rendered heatmap.  The labels of the heatmap rows have the
+// specified width.
+func (h *Heatmap) renderEvents(w io.Writer, width int) error {
+	if len(h.columns) == 0 {
+		return nil
+	}
+
+	// Find the events occurring during the rendered intervals
+	events := []Event{}
+	marks := []byte(strings.Repeat(" ", len(h.columns)))
+	for _, e := range h.Events {
+		idx := h.eventColumn(e)
+		if idx < 0 {
+			continue
+		}
+		marks[idx] = '^'
+		events = append(events, e)
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].At.Before(events[j].At)
+	})
+
+	// Render the markers and the list
+	out := &strings.Builder{}
+	fmt.Fprintf(out, "%*s |%s\n", width, "", marks)
+	for _, e := range events {
+		fmt.Fprintf(out, "%*s ^ %s %s\n", width, "", e.At.Format(time.RFC3339), e.Name)
+	}
+	_, err := io.WriteString(w, out.String())
+
+	return err
+}
+
+// Dimensions used when rendering a heatmap as SVG, in pixels.
+const (
+	heatmapSVGCell = 10 // Width and height of each cell
+	heatmapSVGChar = 7  // Approximate width of a label character
+	heatmapSVGFont = 10 // Font size of the labels
+)
+
+// svgEscape returns the text escaped for inclusion in an SVG
+// document.
+func svgEscape(text string) string {
+	buf := &strings.Builder{}
+	_ = xml.EscapeText(buf, []byte(text))
+
+	return buf.String()
+}
+
+// RenderSVG writes an SVG rendering of the heatmap to the specified
+// writer.  The layout matches Render: each row corresponds to a
+// latency bucket, with the largest latencies at the top, and each
+// column to a time interval, with the oldest interval at the left.
+// The opacity of each cell is proportional to its count, and each
+// cell carries a tooltip giving the interval and count.  Events
+// occurring during the rendered intervals are drawn as vertical
+// lines through the interval, with the event name as a tooltip.
+func (h *Heatmap) RenderSVG(w io.Writer) error {
+	labels, width := h.labels()
+	max := h.maxCount()
+	left := (width + 1) * heatmapSVGChar
+	out := &strings.Builder{}
+	fmt.Fprintf(out, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d">`+"\n", left+len(h.columns)*heatmapSVGCell, len(labels)*heatmapSVGCell)
+
+	// Render each row, starting with the largest latencies
+	for row := 0; row < len(labels); row++ {
+		i := len(labels) - 1 - row
+		y := row * heatmapSVGCell
+		fmt.Fprintf(out, `<text x="%d" y="%d" font-size="%d" text-anchor="end">%s</text>`+"\n", left-heatmapSVGChar, y+heatmapSVGCell, heatmapSVGFont, svgEscape(labels[i]))
+		for j, col := range h.columns {
+			if col.Counts[i] == 0 {
+				continue
+			}
+			fmt.Fprintf(out, `<rect x="%d" y="%d" width="%d" height="%d" fill="red" fill-opacity="%.3f"><title>%s: %d</title></rect>`+"\n", left+j*heatmapSVGCell, y, heatmapSVGCell, heatmapSVGCell, float64(col.Counts[i])/float64(max), col.Start.Format(time.RFC3339), col.Counts[i])
+		}
+	}
+
+	// Render the events
+	for _, e := range h.Events {
+		idx := h.eventColumn(e)
+		if idx < 0 {
+			continue
+		}
+		x := left + idx*heatmapSVGCell + heatmapSVGCell/2
+		fmt.Fprintf(out, `<line x1="%d" y1="0" x2="%d" y2="%d" stroke="black"><title>%s %s</title></line>`+"\n", x, x, len(labels)*heatmapSVGCell, e.At.Format(time.RFC3339), svgEscape(e.Name))
+	}
+
+	out.WriteString("</svg>\n")
+	_, err := io.WriteString(w, out.String())
+
+	return err
+}
@@ -0,0 +1,518 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type errWriter struct{}
+
+func (errWriter) Write(p []byte) (int, error) {
+	return 0, assert.AnError
+}
+
+var testHeatmapStart = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestHeatmapClockDefault(t *testing.T) {
+	h := &Heatmap{}
+
+	result := h.clock()
+
+	assert.Equal(t, WallClock, result)
+}
+
+func TestHeatmapIntervalDefault(t *testing.T) {
+	h := &Heatmap{}
+
+	result := h.interval()
+
+	assert.Equal(t, DefaultHeatmapInterval, result)
+}
+
+func TestHeatmapIntervalSet(t *testing.T) {
+	h := &Heatmap{Interval: time.Second}
+
+	result := h.interval()
+
+	assert.Equal(t, time.Second, result)
+}
+
+func TestHeatmapUpdateZeroValue(t *testing.T) {
+	h := &Heatmap{Clock: &stepClock{now: testHeatmapStart, step: 90 * time.Second}}
+
+	h.Update(10)
+	h.Update(20)
+	h.Update(30)
+
+	assert.Equal(t, []HeatmapColumn{
+		{Start: testHeatmapStart, Counts: []int64{1}},
+		{Start: testHeatmapStart.Add(time.Minute), Counts: []int64{1}},
+		{Start: testHeatmapStart.Add(2 * time.Minute), Counts: []int64{0}},
+		{Start: testHeatmapStart.Add(3 * time.Minute), Counts: []int64{1}},
+	}, h.Columns())
+}
+
+func TestHeatmapClockSet(t *testing.T) {
+	clock := &testClock{}
+	h := &Heatmap{Clock: clock}
+
+	result := h.clock()
+
+	assert.Same(t, clock, result)
+}
+
+func TestHeatmapBucket(t *testing.T) {
+	h := &Heatmap{
+		Bounds: []time.Duration{10, 20, 30},
+	}
+
+	assert.Equal(t, 0, h.bucket(5))
+	assert.Equal(t, 0, h.bucket(10))
+	assert.Equal(t, 1, h.bucket(15))
+	assert.Equal(t, 2, h.bucket(30))
+	assert.Equal(t, 3, h.bucket(31))
+}
+
+func TestHeatmapColumnEmpty(t *testing.T) {
+	h := &Heatmap{
+		Interval: time.Minute,
+		Bounds:   []time.Duration{10},
+	}
+
+	result := h.column(testHeatmapStart.Add(30 * time.Second))
+
+	assert.Equal(t, &HeatmapColumn{
+		Start:  testHeatmapStart,
+		Counts: []int64{0, 0},
+	}, result)
+	assert.Len(t, h.columns, 1)
+}
+
+func TestHeatmapColumnCurrent(t *testing.T) {
+	h := &Heatmap{
+		Interval: time.Minute,
+		Bounds:   []time.Duration{10},
+		columns: []HeatmapColumn{
+			{Start: testHeatmapStart, Counts: []int64{1, 2}},
+		},
+	}
+
+	result := h.column(testHeatmapStart.Add(30 * time.Second))
+
+	assert.Same(t, &h.columns[0], result)
+	assert.Len(t, h.columns, 1)
+}
+
+func TestHeatmapColumnNext(t *testing.T) {
+	h := &Heatmap{
+		Interval: time.Minute,
+		Bounds:   []time.Duration{10},
+		columns: []HeatmapColumn{
+			{Start: testHeatmapStart, Counts: []int64{1, 2}},
+		},
+	}
+
+	result := h.column(testHeatmapStart.Add(90 * time.Second))
+
+	assert.Same(t, &h.columns[1], result)
+	assert.Equal(t, []HeatmapColumn{
+		{Start: testHeatmapStart, Counts: []int64{1, 2}},
+		{Start: testHeatmapStart.Add(time.Minute), Counts: []int64{0, 0}},
+	}, h.columns)
+}
+
+func TestHeatmapColumnGap(t *testing.T) {
+	h := &Heatmap{
+		Interval: time.Minute,
+		Bounds:   []time.Duration{10},
+		columns: []HeatmapColumn{
+			{Start: testHeatmapStart, Counts: []int64{1, 2}},
+		},
+	}
+
+	result := h.column(testHeatmapStart.Add(150 * time.Second))
+
+	assert.Same(t, &h.columns[2], result)
+	assert.Equal(t, []HeatmapColumn{
+		{Start: testHeatmapStart, Counts: []int64{1, 2}},
+		{Start: testHeatmapStart.Add(time.Minute), Counts: []int64{0, 0}},
+		{Start: testHeatmapStart.Add(2 * time.Minute), Counts: []int64{0, 0}},
+	}, h.columns)
+}
+
+func TestHeatmapColumnRetention(t *testing.T) {
+	h := &Heatmap{
+		Interval:  time.Minute,
+		Bounds:    []time.Duration{10},
+		Retention: 2,
+		columns: []HeatmapColumn{
+			{Start: testHeatmapStart, Counts: []int64{1, 2}},
+			{Start: testHeatmapStart.Add(time.Minute), Counts: []int64{3, 4}},
+		},
+	}
+
+	result := h.column(testHeatmapStart.Add(150 * time.Second))
+
+	assert.Same(t, &h.columns[1], result)
+	assert.Equal(t, []HeatmapColumn{
+		{Start: testHeatmapStart.Add(time.Minute), Counts: []int64{3, 4}},
+		{Start: testHeatmapStart.Add(2 * time.Minute), Counts: []int64{0, 0}},
+	}, h.columns)
+}
+
+func TestHeatmapColumnGapExceedsRetention(t *testing.T) {
+	h := &Heatmap{
+		Interval:  time.Minute,
+		Bounds:    []time.Duration{10},
+		Retention: 2,
+		columns: []HeatmapColumn{
+			{Start: testHeatmapStart, Counts: []int64{1, 2}},
+		},
+	}
+
+	result := h.column(testHeatmapStart.Add(10 * time.Minute))
+
+	assert.Same(t, &h.columns[0], result)
+	assert.Equal(t, []HeatmapColumn{
+		{Start: testHeatmapStart.Add(10 * time.Minute), Counts: []int64{0, 0}},
+	}, h.columns)
+}
+
+func TestHeatmapColumnPast(t *testing.T) {
+	h := &Heatmap{
+		Interval: time.Minute,
+		Bounds:   []time.Duration{10},
+		columns: []HeatmapColumn{
+			{Start: testHeatmapStart, Counts: []int64{1, 2}},
+			{Start: testHeatmapStart.Add(time.Minute), Counts: []int64{3, 4}},
+		},
+	}
+
+	result := h.column(testHeatmapStart.Add(30 * time.Second))
+
+	assert.Same(t, &h.columns[0], result)
+	assert.Len(t, h.columns, 2)
+}
+
+func TestHeatmapColumnExpired(t *testing.T) {
+	h := &Heatmap{
+		Interval: time.Minute,
+		Bounds:   []time.Duration{10},
+		columns: []HeatmapColumn{
+			{Start: testHeatmapStart, Counts: []int64{1, 2}},
+		},
+	}
+
+	result := h.column(testHeatmapStart.Add(-30 * time.Second))
+
+	assert.Nil(t, result)
+	assert.Len(t, h.columns, 1)
+}
+
+func TestHeatmapUpdate(t *testing.T) {
+	h := &Heatmap{
+		Interval: time.Minute,
+		Bounds:   []time.Duration{10},
+		Clock:    &testClock{now: testHeatmapStart},
+	}
+
+	h.Update(5)
+	h.Update(15)
+	h.Update(20)
+
+	assert.Equal(t, []HeatmapColumn{
+		{Start: testHeatmapStart, Counts: []int64{1, 2}},
+	}, h.Columns())
+}
+
+func TestHeatmapUpdateAtExpired(t *testing.T) {
+	h := &Heatmap{
+		Interval: time.Minute,
+		Bounds:   []time.Duration{10},
+		columns: []HeatmapColumn{
+			{Start: testHeatmapStart, Counts: []int64{1, 2}},
+		},
+	}
+
+	h.UpdateAt(testHeatmapStart.Add(-time.Minute), 5)
+
+	assert.Equal(t, []HeatmapColumn{
+		{Start: testHeatmapStart, Counts: []int64{1, 2}},
+	}, h.Columns())
+}
+
+func TestHeatmapMarshalJSON(t *testing.T) {
+	h := &Heatmap{
+		Interval: time.Minute,
+		Bounds:   []time.Duration{10},
+		columns: []HeatmapColumn{
+			{Start: testHeatmapStart, Counts: []int64{1, 2}},
+		},
+	}
+
+	result, err := json.Marshal(h)
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+    "interval": 60000000000,
+    "bounds": [10],
+    "columns": [
+        {"start": "2020-01-01T00:00:00Z", "counts": [1, 2]}
+    ]
+}`, string(result))
+}
+
+func TestHeatmapRenderBase(t *testing.T) {
+	h := &Heatmap{
+		Interval: time.Minute,
+		Bounds:   []time.Duration{10, 100},
+		columns: []HeatmapColumn{
+			{Start: testHeatmapStart, Counts: []int64{9, 1, 0}},
+			{Start: testHeatmapStart.Add(time.Minute), Counts: []int64{3, 5, 2}},
+		},
+	}
+	buf := &bytes.Buffer{}
+
+	err := h.Render(buf)
+
+	require.NoError(t, err)
+	assert.Equal(t, ` >100ns | :
+<=100ns |.+
+ <=10ns |@-
+`, buf.String())
+}
+
+func TestHeatmapRenderNoBounds(t *testing.T) {
+	h := &Heatmap{
+		Interval: time.Minute,
+		columns: []HeatmapColumn{
+			{Start: testHeatmapStart, Counts: []int64{4}},
+			{Start: testHeatmapStart.Add(time.Minute), Counts: []int64{0}},
+		},
+	}
+	buf := &bytes.Buffer{}
+
+	err := h.Render(buf)
+
+	require.NoError(t, err)
+	assert.Equal(t, "all |@ \n", buf.String())
+}
+
+func TestHeatmapRenderEvents(t *testing.T) {
+	h := &Heatmap{
+		Interval: time.Minute,
+		Bounds:   []time.Duration{10},
+		Events: []Event{
+			{At: testHeatmapStart.Add(90 * time.Second), Name: "deploy v2"},
+			{At: testHeatmapStart.Add(10 * time.Second), Name: "deploy v1"},
+			{At: testHeatmapStart.Add(-time.Minute), Name: "too early"},
+			{At: testHeatmapStart.Add(3 * time.Minute), Name: "too late"},
+		},
+		columns: []HeatmapColumn{
+			{Start: testHeatmapStart, Counts: []int64{4, 0}},
+			{Start: testHeatmapStart.Add(time.Minute), Counts: []int64{0, 4}},
+			{Start: testHeatmapStart.Add(2 * time.Minute), Counts: []int64{4, 0}},
+		},
+	}
+	buf := &bytes.Buffer{}
+
+	err := h.Render(buf)
+
+	require.NoError(t, err)
+	assert.Equal(t, ` >10ns | @ 
+<=10ns |@ @
+       |^^ 
+       ^ `+testHeatmapStart.Add(10*time.Second).Format(time.RFC3339)+` deploy v1
+       ^ `+testHeatmapStart.Add(90*time.Second).Format(time.RFC3339)+` deploy v2
+`, buf.String())
+}
+
+func TestHeatmapRenderEventsNone(t *testing.T) {
+	h := &Heatmap{
+		Interval: time.Minute,
+		Events:   []Event{{At: testHeatmapStart.Add(time.Hour), Name: "too late"}},
+		columns: []HeatmapColumn{
+			{Start: testHeatmapStart, Counts: []int64{4}},
+		},
+	}
+	buf := &bytes.Buffer{}
+
+	err := h.Render(buf)
+
+	require.NoError(t, err)
+	assert.Equal(t, "all |@\n", buf.String())
+}
+
+func TestHeatmapRenderEventsEmpty(t *testing.T) {
+	h := &Heatmap{
+		Interval: time.Minute,
+		Events:   []Event{{At: testHeatmapStart, Name: "deploy"}},
+	}
+	buf := &bytes.Buffer{}
+
+	err := h.Render(buf)
+
+	require.NoError(t, err)
+	assert.Equal(t, "all |\n", buf.String())
+}
+
+func TestHeatmapRenderError(t *testing.T) {
+	h := &Heatmap{
+		Interval: time.Minute,
+		Bounds:   []time.Duration{10},
+	}
+
+	err := h.Render(errWriter{})
+
+	assert.Same(t, assert.AnError, err)
+}
+
+func TestHeatmapUnmarshalJSONBase(t *testing.T) {
+	h := &Heatmap{Retention: 5}
+
+	err := json.Unmarshal([]byte(`{
+    "interval": 60000000000,
+    "bounds": [10],
+    "columns": [
+        {"start": "2020-01-01T00:00:00Z", "counts": [1, 2]}
+    ]
+}`), h)
+
+	require.NoError(t, err)
+	assert.Equal(t, &Heatmap{
+		Interval:  time.Minute,
+		Bounds:    []time.Duration{10},
+		Retention: 5,
+		columns: []HeatmapColumn{
+			{Start: testHeatmapStart, Counts: []int64{1, 2}},
+		},
+	}, h)
+}
+
+func TestHeatmapUnmarshalJSONRoundTrip(t *testing.T) {
+	orig := &Heatmap{
+		Interval: time.Minute,
+		Bounds:   []time.Duration{10, 100},
+		columns: []HeatmapColumn{
+			{Start: testHeatmapStart, Counts: []int64{9, 1, 0}},
+			{Start: testHeatmapStart.Add(time.Minute), Counts: []int64{3, 5, 2}},
+		},
+	}
+	text, err := json.Marshal(orig)
+	require.NoError(t, err)
+	h := &Heatmap{}
+
+	err = json.Unmarshal(text, h)
+
+	require.NoError(t, err)
+	assert.Equal(t, orig, h)
+}
+
+func TestHeatmapUnmarshalJSONNull(t *testing.T) {
+	h := &Heatmap{Interval: time.Minute}
+
+	err := h.UnmarshalJSON([]byte("null"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Heatmap{Interval: time.Minute}, h)
+}
+
+func TestHeatmapUnmarshalJSONBadJSON(t *testing.T) {
+	h := &Heatmap{}
+
+	err := h.UnmarshalJSON([]byte(`{"bounds": "bad"}`))
+
+	assert.Error(t, err)
+	assert.Equal(t, &Heatmap{}, h)
+}
+
+func TestHeatmapUnmarshalJSONBadCounts(t *testing.T) {
+	h := &Heatmap{}
+
+	err := h.UnmarshalJSON([]byte(`{
+    "interval": 60000000000,
+    "bounds": [10],
+    "columns": [
+        {"start": "2020-01-01T00:00:00Z", "counts": [1]}
+    ]
+}`))
+
+	assert.ErrorIs(t, err, ErrBadImport)
+	assert.EqualError(t, err, "invalid imported data: heatmap column at 2020-01-01T00:00:00Z has 1 counts for 1 bounds")
+	assert.Equal(t, &Heatmap{}, h)
+}
+
+func TestHeatmapRenderSVGBase(t *testing.T) {
+	h := &Heatmap{
+		Interval: time.Minute,
+		Bounds:   []time.Duration{10},
+		Events: []Event{
+			{At: testHeatmapStart.Add(90 * time.Second), Name: "deploy <v2>"},
+			{At: testHeatmapStart.Add(-time.Minute), Name: "too early"},
+		},
+		columns: []HeatmapColumn{
+			{Start: testHeatmapStart, Counts: []int64{4, 0}},
+			{Start: testHeatmapStart.Add(time.Minute), Counts: []int64{1, 2}},
+		},
+	}
+	buf := &bytes.Buffer{}
+
+	err := h.RenderSVG(buf)
+
+	require.NoError(t, err)
+	assert.Equal(t, `<svg xmlns="http://www.w3.org/2000/svg" width="69" height="20">
+<text x="42" y="10" font-size="10" text-anchor="end">&gt;10ns</text>
+<rect x="59" y="0" width="10" height="10" fill="red" fill-opacity="0.500"><title>2020-01-01T00:01:00Z: 2</title></rect>
+<text x="42" y="20" font-size="10" text-anchor="end">&lt;=10ns</text>
+<rect x="49" y="10" width="10" height="10" fill="red" fill-opacity="1.000"><title>2020-01-01T00:00:00Z: 4</title></rect>
+<rect x="59" y="10" width="10" height="10" fill="red" fill-opacity="0.250"><title>2020-01-01T00:01:00Z: 1</title></rect>
+<line x1="64" y1="0" x2="64" y2="20" stroke="black"><title>2020-01-01T00:01:30Z deploy &lt;v2&gt;</title></line>
+</svg>
+`, buf.String())
+}
+
+func TestHeatmapRenderSVGEmpty(t *testing.T) {
+	h := &Heatmap{
+		Interval: time.Minute,
+		Events:   []Event{{At: testHeatmapStart, Name: "deploy"}},
+	}
+	buf := &bytes.Buffer{}
+
+	err := h.RenderSVG(buf)
+
+	require.NoError(t, err)
+	assert.Equal(t, `<svg xmlns="http://www.w3.org/2000/svg" width="28" height="10">
+<text x="21" y="10" font-size="10" text-anchor="end">all</text>
+</svg>
+`, buf.String())
+}
+
+func TestHeatmapRenderSVGError(t *testing.T) {
+	h := &Heatmap{
+		Interval: time.Minute,
+		Bounds:   []time.Duration{10},
+	}
+
+	err := h.RenderSVG(errWriter{})
+
+	assert.Same(t, assert.AnError, err)
+}
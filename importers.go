@@ -0,0 +1,156 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setM2 sets the sum of square differences of the Data from a value
+// in square nanoseconds, saturating if the value is out of range.
+func (d *Data) setM2(m2 float64) {
+	if m2 >= math.MaxInt64 {
+		d.m2 = math.MaxInt64
+		d.Overflowed = true
+		return
+	}
+
+	d.m2 = time.Duration(m2)
+}
+
+// hyperfineExport is the JSON export format of hyperfine; only the
+// fields of interest are decoded.
+type hyperfineExport struct {
+	Results []struct {
+		Command string    `json:"command"`
+		Times   []float64 `json:"times"`
+	} `json:"results"`
+}
+
+// ReadHyperfine reads the JSON export of the hyperfine benchmarking
+// tool (as written by "hyperfine --export-json") and updates the Data
+// in the dataset with the time of each run, in the order the runs
+// were made.  The Data are named by the benchmarked command.  As with
+// ReplayJSON, Data not already present in the dataset will be
+// created.
+func ReadHyperfine(r io.Reader, dataset map[string]*Data) error {
+	export := &hyperfineExport{}
+	if err := json.NewDecoder(r).Decode(export); err != nil {
+		return fmt.Errorf("%w: %s", ErrBadImport, err)
+	}
+
+	for _, result := range export.Results {
+		for _, t := range result.Times {
+			(&Record{
+				Duration: seconds(t),
+				Name:     result.Command,
+			}).replay(dataset)
+		}
+	}
+
+	return nil
+}
+
+// vegetaReport is the JSON report format of vegeta; only the fields
+// of interest are decoded.
+type vegetaReport struct {
+	Latencies struct {
+		Mean time.Duration `json:"mean"`
+		Max  time.Duration `json:"max"`
+		Min  time.Duration `json:"min"`
+	} `json:"latencies"`
+	Requests *int64 `json:"requests"`
+}
+
+// ReadVegeta reads the JSON report of the vegeta load testing tool (as
+// written by "vegeta report -type=json") and sets the statistics of
+// the Data to those reported.  Vegeta does not report the standard
+// deviation of the latencies, so the variance of the Data is zero.
+func ReadVegeta(r io.Reader, d *Data) error {
+	report := &vegetaReport{}
+	if err := json.NewDecoder(r).Decode(report); err != nil {
+		return fmt.Errorf("%w: %s", ErrBadImport, err)
+	}
+	if report.Requests == nil {
+		return fmt.Errorf("%w: missing request count", ErrBadImport)
+	}
+
+	d.Samples = *report.Requests
+	d.Mean = report.Latencies.Mean
+	d.Max = report.Latencies.Max
+	d.Min = report.Latencies.Min
+	d.m2 = 0
+
+	return nil
+}
+
+// ReadWrk reads the text output of the wrk load testing tool and sets
+// the statistics of the Data to the reported latency statistics.  Wrk
+// does not report the minimum latency, so the minimum of the Data is
+// zero.
+func ReadWrk(r io.Reader, d *Data) error {
+	var samples int64
+	var mean, stdDev, max time.Duration
+	foundLatency, foundCount := false, false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) >= 4 && fields[0] == "Latency" && !foundLatency:
+			// Thread statistics: average, stdev, max
+			var err error
+			if mean, err = time.ParseDuration(fields[1]); err != nil {
+				return fmt.Errorf("%w: %s", ErrBadImport, err)
+			}
+			if stdDev, err = time.ParseDuration(fields[2]); err != nil {
+				return fmt.Errorf("%w: %s", ErrBadImport, err)
+			}
+			if max, err = time.ParseDuration(fields[3]); err != nil {
+				return fmt.Errorf("%w: %s", ErrBadImport, err)
+			}
+			foundLatency = true
+
+		case len(fields) >= 3 && fields[1] == "requests" && fields[2] == "in":
+			// Total request count
+			var err error
+			if samples, err = strconv.ParseInt(fields[0], 10, 64); err != nil {
+				return fmt.Errorf("%w: %s", ErrBadImport, err)
+			}
+			foundCount = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if !foundLatency || !foundCount {
+		return fmt.Errorf("%w: missing latency statistics", ErrBadImport)
+	}
+
+	d.Samples = samples
+	d.Mean = mean
+	d.Max = max
+	d.Min = 0
+	d.setM2(float64(stdDev) * float64(stdDev) * float64(samples))
+
+	return nil
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataSetM2Base(t *testing.T) {
+	d := &Data{}
+
+	d.setM2(1000)
+
+	assert.Equal(t, &Data{m2: 1000}, d)
+}
+
+func TestDataSetM2Overflow(t *testing.T) {
+	d := &Data{}
+
+	d.setM2(1e19)
+
+	assert.Equal(t, &Data{m2: math.MaxInt64, Overflowed: true}, d)
+}
+
+func TestReadHyperfineBase(t *testing.T) {
+	text := `{
+  "results": [
+    {
+      "command": "sleep 0.1",
+      "mean": 0.15,
+      "stddev": 0.05,
+      "times": [0.1, 0.2],
+      "exit_codes": [0, 0]
+    },
+    {
+      "command": "sleep 0.2",
+      "mean": 0.2,
+      "times": [0.2]
+    }
+  ]
+}`
+	existing := &Data{Flags: StdDev}
+	dataset := map[string]*Data{"sleep 0.1": existing}
+
+	err := ReadHyperfine(strings.NewReader(text), dataset)
+
+	assert.NoError(t, err)
+	assert.Same(t, existing, dataset["sleep 0.1"])
+	assert.Equal(t, int64(2), existing.Samples)
+	assert.Equal(t, 150*time.Millisecond, existing.Mean)
+	assert.Equal(t, 100*time.Millisecond, existing.Min)
+	assert.Equal(t, 200*time.Millisecond, existing.Max)
+	assert.Equal(t, int64(1), dataset["sleep 0.2"].Samples)
+}
+
+func TestReadHyperfineError(t *testing.T) {
+	err := ReadHyperfine(strings.NewReader("bad"), map[string]*Data{})
+
+	assert.ErrorIs(t, err, ErrBadImport)
+}
+
+func TestReadVegetaBase(t *testing.T) {
+	text := `{"latencies":{"total":3000000000,"mean":3000000,"50th":2500000,"90th":4000000,"95th":5000000,"99th":8000000,"max":12000000,"min":1000000},"requests":1000,"rate":100.1,"success":1,"status_codes":{"200":1000},"errors":[]}`
+	d := &Data{Flags: StdDev, m2: 5}
+
+	err := ReadVegeta(strings.NewReader(text), d)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Data{
+		Samples: 1000,
+		Mean:    3 * time.Millisecond,
+		Max:     12 * time.Millisecond,
+		Min:     time.Millisecond,
+		Flags:   StdDev,
+	}, d)
+}
+
+func TestReadVegetaDecodeError(t *testing.T) {
+	err := ReadVegeta(strings.NewReader("bad"), &Data{})
+
+	assert.ErrorIs(t, err, ErrBadImport)
+}
+
+func TestReadVegetaMissingRequests(t *testing.T) {
+	err := ReadVegeta(strings.NewReader(`{"latencies":{}}`), &Data{})
+
+	assert.ErrorIs(t, err, ErrBadImport)
+	assert.EqualError(t, err, "invalid imported data: missing request count")
+}
+
+const wrkOutput = `Running 10s test @ http://127.0.0.1:8080/
+  2 threads and 10 connections
+  Thread Stats   Avg      Stdev     Max   +/- Stdev
+    Latency   500.00us    2.00ms  12.92ms   93.69%
+    Req/Sec    56.20k     8.07k   62.00k    86.54%
+  Latency Distribution
+     50%  250.00us
+     99%    5.00ms
+  1000 requests in 10.10s, 171.10MB read
+Requests/sec:     99.01
+Transfer/sec:     16.94MB
+`
+
+func TestReadWrkBase(t *testing.T) {
+	d := &Data{Flags: StdDev, Min: 5}
+
+	err := ReadWrk(strings.NewReader(wrkOutput), d)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Data{
+		Samples: 1000,
+		Mean:    500 * time.Microsecond,
+		Max:     12920 * time.Microsecond,
+		Flags:   StdDev,
+		m2:      4e15,
+	}, d)
+	assert.Equal(t, 2*time.Millisecond, d.StdDev())
+}
+
+func TestReadWrkBadMean(t *testing.T) {
+	err := ReadWrk(strings.NewReader(strings.Replace(wrkOutput, "500.00us", "bad", 1)), &Data{})
+
+	assert.ErrorIs(t, err, ErrBadImport)
+}
+
+func TestReadWrkBadStdDev(t *testing.T) {
+	err := ReadWrk(strings.NewReader(strings.Replace(wrkOutput, "2.00ms", "bad", 1)), &Data{})
+
+	assert.ErrorIs(t, err, ErrBadImport)
+}
+
+func TestReadWrkBadMax(t *testing.T) {
+	err := ReadWrk(strings.NewReader(strings.Replace(wrkOutput, "12.92ms", "bad", 1)), &Data{})
+
+	assert.ErrorIs(t, err, ErrBadImport)
+}
+
+func TestReadWrkBadCount(t *testing.T) {
+	err := ReadWrk(strings.NewReader(strings.Replace(wrkOutput, "1000 requests", "bad requests", 1)), &Data{})
+
+	assert.ErrorIs(t, err, ErrBadImport)
+}
+
+func TestReadWrkMissing(t *testing.T) {
+	err := ReadWrk(strings.NewReader("Running 10s test\n"), &Data{})
+
+	assert.ErrorIs(t, err, ErrBadImport)
+	assert.EqualError(t, err, "invalid imported data: missing latency statistics")
+}
+
+func TestReadWrkReadError(t *testing.T) {
+	err := ReadWrk(strings.NewReader(strings.Repeat("x", 1024*1024)), &Data{})
+
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrBadImport)
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"io"
+	"time"
+)
+
+// DefaultBurstThreshold is the number of standard deviations above
+// the mean at which samples are considered slow by a BurstDetector
+// with no Threshold set.
+const DefaultBurstThreshold = 3.0
+
+// Incident describes a burst of slow samples.
+type Incident struct {
+	Start time.Time     `json:"start" yaml:"start"` // Time of the first slow sample
+	End   time.Time     `json:"end" yaml:"end"`     // Time of the last slow sample
+	Count int           `json:"count" yaml:"count"` // Number of slow samples
+	Worst time.Duration `json:"worst" yaml:"worst"` // The slowest sample
+}
+
+// BurstDetector clusters consecutive slow samples, such as those
+// returned by Data.Retained, into incidents, converting the raw tail
+// of a latency distribution into discrete events that can be matched
+// against logs and deployment histories.
+type BurstDetector struct {
+	Threshold time.Duration // Slowest acceptable sample; 0 for default
+	MaxGap    int           // Fast samples tolerated within an incident
+	MinCount  int           // Minimum slow samples for an incident
+}
+
+// threshold returns the threshold for slow samples.  If no threshold
+// is set, it is computed from the samples as the mean plus
+// DefaultBurstThreshold standard deviations.
+func (bd *BurstDetector) threshold(samples []Sample) time.Duration {
+	if bd.Threshold > 0 {
+		return bd.Threshold
+	}
+
+	d := &Data{}
+	for _, s := range samples {
+		d.updateSample(Sample{Duration: s.Duration})
+	}
+
+	return d.Mean + time.Duration(DefaultBurstThreshold*float64(d.SampleStdDev()))
+}
+
+// Incidents returns the incidents found in the samples, which must
+// be in time order.  A sample is slow if it exceeds the threshold;
+// an incident begins with a slow sample and ends when more than
+// MaxGap consecutive fast samples are seen.  Incidents with fewer
+// than MinCount slow samples are not reported.
+func (bd *BurstDetector) Incidents(samples []Sample) []Incident {
+	threshold := bd.threshold(samples)
+
+	result := []Incident{}
+	var cur *Incident
+	gap := 0
+	closeIncident := func() {
+		if cur != nil && cur.Count >= bd.MinCount {
+			result = append(result, *cur)
+		}
+		cur = nil
+	}
+	for _, s := range samples {
+		// Handle fast samples
+		if s.Duration <= threshold {
+			gap++
+			if gap > bd.MaxGap {
+				closeIncident()
+			}
+			continue
+		}
+
+		// Start or extend the incident
+		gap = 0
+		if cur == nil {
+			cur = &Incident{Start: s.At}
+		}
+		cur.End = s.At
+		cur.Count++
+		if s.Duration > cur.Worst {
+			cur.Worst = s.Duration
+		}
+	}
+	closeIncident()
+
+	return result
+}
+
+// WriteIncidents writes a table listing the incidents to the
+// specified writer, using the default NumberFormat.
+func WriteIncidents(w io.Writer, incidents []Incident) error {
+	return (&NumberFormat{}).WriteIncidents(w, incidents)
+}
+
+// WriteIncidents is similar to the WriteIncidents function, but
+// formats the table using the NumberFormat.
+func (nf *NumberFormat) WriteIncidents(w io.Writer, incidents []Incident) error {
+	rows := [][]string{{"START", "END", "COUNT", "WORST"}}
+	for _, inc := range incidents {
+		rows = append(rows, []string{inc.Start.Format(time.RFC3339Nano), inc.End.Format(time.RFC3339Nano), nf.Int(int64(inc.Count)), nf.Duration(inc.Worst)})
+	}
+
+	return nf.writeTable(w, rows, nil)
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// burstSamples constructs a time-ordered series of samples, one per
+// second, from the durations.
+func burstSamples(durations ...time.Duration) []Sample {
+	samples := make([]Sample, len(durations))
+	for i, d := range durations {
+		samples[i] = Sample{
+			At:       time.Unix(int64(1000+i), 0).UTC(),
+			Duration: d,
+		}
+	}
+
+	return samples
+}
+
+func TestBurstDetectorThresholdSet(t *testing.T) {
+	bd := &BurstDetector{Threshold: 50}
+
+	result := bd.threshold(burstSamples(10, 20, 30))
+
+	assert.Equal(t, time.Duration(50), result)
+}
+
+func TestBurstDetectorThresholdDefault(t *testing.T) {
+	bd := &BurstDetector{}
+
+	result := bd.threshold(burstSamples(10, 20, 30))
+
+	assert.Equal(t, time.Duration(50), result)
+}
+
+func TestBurstDetectorIncidentsBase(t *testing.T) {
+	bd := &BurstDetector{Threshold: 50}
+
+	result := bd.Incidents(burstSamples(10, 60, 70, 10, 10, 80, 10))
+
+	assert.Equal(t, []Incident{
+		{
+			Start: time.Unix(1001, 0).UTC(),
+			End:   time.Unix(1002, 0).UTC(),
+			Count: 2,
+			Worst: 70,
+		},
+		{
+			Start: time.Unix(1005, 0).UTC(),
+			End:   time.Unix(1005, 0).UTC(),
+			Count: 1,
+			Worst: 80,
+		},
+	}, result)
+}
+
+func TestBurstDetectorIncidentsMaxGap(t *testing.T) {
+	bd := &BurstDetector{Threshold: 50, MaxGap: 1}
+
+	result := bd.Incidents(burstSamples(60, 10, 70, 10, 10, 80))
+
+	assert.Equal(t, []Incident{
+		{
+			Start: time.Unix(1000, 0).UTC(),
+			End:   time.Unix(1002, 0).UTC(),
+			Count: 2,
+			Worst: 70,
+		},
+		{
+			Start: time.Unix(1005, 0).UTC(),
+			End:   time.Unix(1005, 0).UTC(),
+			Count: 1,
+			Worst: 80,
+		},
+	}, result)
+}
+
+func TestBurstDetectorIncidentsMinCount(t *testing.T) {
+	bd := &BurstDetector{Threshold: 50, MinCount: 2}
+
+	result := bd.Incidents(burstSamples(10, 60, 70, 10, 10, 80, 10))
+
+	assert.Equal(t, []Incident{
+		{
+			Start: time.Unix(1001, 0).UTC(),
+			End:   time.Unix(1002, 0).UTC(),
+			Count: 2,
+			Worst: 70,
+		},
+	}, result)
+}
+
+func TestBurstDetectorIncidentsNone(t *testing.T) {
+	bd := &BurstDetector{Threshold: 50}
+
+	result := bd.Incidents(burstSamples(10, 20, 30))
+
+	assert.Equal(t, []Incident{}, result)
+}
+
+func TestWriteIncidentsBase(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	err := WriteIncidents(buf, []Incident{
+		{
+			Start: time.Unix(1001, 0).UTC(),
+			End:   time.Unix(1002, 0).UTC(),
+			Count: 2,
+			Worst: 70 * time.Millisecond,
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "START                 END                   COUNT  WORST\n1970-01-01T00:16:41Z  1970-01-01T00:16:42Z  2      70ms\n", buf.String())
+}
+
+func TestWriteIncidentsError(t *testing.T) {
+	err := WriteIncidents(errWriter{}, nil)
+
+	assert.Same(t, assert.AnError, err)
+}
+
+func TestNumberFormatWriteIncidents(t *testing.T) {
+	buf := &bytes.Buffer{}
+	nf := &NumberFormat{Human: true}
+
+	err := nf.WriteIncidents(buf, []Incident{
+		{
+			Start: time.Unix(1001, 0).UTC(),
+			End:   time.Unix(1002, 0).UTC(),
+			Count: 2,
+			Worst: 70123456,
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "START                 END                   COUNT  WORST\n1970-01-01T00:16:41Z  1970-01-01T00:16:42Z  2      70.1ms\n", buf.String())
+}
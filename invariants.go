@@ -0,0 +1,76 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import "fmt"
+
+// checkInvariants checks the invariants of the Data's own statistics.
+func (d *Data) checkInvariants() error {
+	switch {
+	case d.Samples < 0:
+		return fmt.Errorf("%w: negative sample count %d", ErrInvariant, d.Samples)
+	case d.InvalidSamples < 0:
+		return fmt.Errorf("%w: negative invalid sample count %d", ErrInvariant, d.InvalidSamples)
+	case d.Rejected < 0:
+		return fmt.Errorf("%w: negative rejected sample count %d", ErrInvariant, d.Rejected)
+	case d.m2 < 0:
+		return fmt.Errorf("%w: negative variance", ErrInvariant)
+	case d.Samples == 0 && (d.Mean != 0 || d.m2 != 0):
+		return fmt.Errorf("%w: statistics without samples", ErrInvariant)
+	case d.Samples > 0 && !d.Overflowed && (d.Min > d.Mean || d.Mean > d.Max):
+		return fmt.Errorf("%w: mean %s outside [%s, %s]", ErrInvariant, d.Mean, d.Min, d.Max)
+	case d.Samples == 1 && !d.Overflowed && d.m2 != 0:
+		return fmt.Errorf("%w: variance of a single sample", ErrInvariant)
+	}
+
+	for i, count := range d.over {
+		if count < 0 || count > d.Samples {
+			return fmt.Errorf("%w: count %d over threshold %s", ErrInvariant, count, d.Thresholds[i])
+		}
+	}
+
+	rates := []struct {
+		name string
+		rate *Rate
+	}{{"item", &d.ItemRate}, {"byte", &d.ByteRate}}
+	for _, r := range rates {
+		switch {
+		case r.rate.Samples < 0 || r.rate.Samples > d.Samples:
+			return fmt.Errorf("%w: %s rate sample count %d", ErrInvariant, r.name, r.rate.Samples)
+		case r.rate.m2 < 0:
+			return fmt.Errorf("%w: negative %s rate variance", ErrInvariant, r.name)
+		}
+	}
+
+	return nil
+}
+
+// CheckInvariants checks that the Data is in a possible state: for
+// instance, that the mean lies between the minimum and maximum, and
+// that the variance is not negative.  The Data passed to Next are
+// checked as well.  An error wrapping ErrInvariant, describing the
+// first violation found, is returned if the Data is in an impossible
+// state.  This is intended for use in tests, and in particular in
+// fuzz tests, to verify that no sequence of updates can corrupt the
+// statistics.
+func (d *Data) CheckInvariants() error {
+	for cur := d; cur != nil; cur = cur.Next {
+		if err := cur.checkInvariants(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package timeit
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func FuzzDataUpdate(f *testing.F) {
+	f.Add([]byte{0x14, 0x3c, 0x28})
+	f.Add([]byte{0x01, 0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01, 0x00})
+	f.Add([]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		d := &Data{Next: &Data{Limit: time.Second, Invalid: DropInvalid}, Retain: 4}
+		for len(data) > 0 {
+			sample, n := binary.Varint(data)
+			if n <= 0 {
+				return
+			}
+			data = data[n:]
+
+			if sample%3 == 0 {
+				d.UpdateThroughput(time.Duration(sample), sample%7, sample%11)
+			} else {
+				d.Update(time.Duration(sample))
+			}
+			if err := d.CheckInvariants(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataCheckInvariantsBase(t *testing.T) {
+	next := &Data{}
+	d := &Data{Next: next, Retain: 2}
+	for _, sample := range []time.Duration{10, 30, 20, -5} {
+		d.Update(sample)
+	}
+	d.UpdateThroughput(10, 5, 100)
+
+	err := d.CheckInvariants()
+
+	assert.NoError(t, err)
+}
+
+func TestDataCheckInvariantsSamples(t *testing.T) {
+	d := &Data{Samples: -1}
+
+	err := d.CheckInvariants()
+
+	assert.ErrorIs(t, err, ErrInvariant)
+	assert.EqualError(t, err, "invariant violated: negative sample count -1")
+}
+
+func TestDataCheckInvariantsInvalidSamples(t *testing.T) {
+	d := &Data{InvalidSamples: -1}
+
+	err := d.CheckInvariants()
+
+	assert.ErrorIs(t, err, ErrInvariant)
+	assert.EqualError(t, err, "invariant violated: negative invalid sample count -1")
+}
+
+func TestDataCheckInvariantsRejected(t *testing.T) {
+	d := &Data{Rejected: -1}
+
+	err := d.CheckInvariants()
+
+	assert.ErrorIs(t, err, ErrInvariant)
+	assert.EqualError(t, err, "invariant violated: negative rejected sample count -1")
+}
+
+func TestDataCheckInvariantsVariance(t *testing.T) {
+	d := &Data{Samples: 2, Mean: 5, Max: 10, m2: -1}
+
+	err := d.CheckInvariants()
+
+	assert.ErrorIs(t, err, ErrInvariant)
+	assert.EqualError(t, err, "invariant violated: negative variance")
+}
+
+func TestDataCheckInvariantsEmpty(t *testing.T) {
+	d := &Data{Mean: 5}
+
+	err := d.CheckInvariants()
+
+	assert.ErrorIs(t, err, ErrInvariant)
+	assert.EqualError(t, err, "invariant violated: statistics without samples")
+}
+
+func TestDataCheckInvariantsMean(t *testing.T) {
+	d := &Data{Samples: 2, Mean: 20, Min: 5, Max: 10}
+
+	err := d.CheckInvariants()
+
+	assert.ErrorIs(t, err, ErrInvariant)
+	assert.EqualError(t, err, "invariant violated: mean 20ns outside [5ns, 10ns]")
+}
+
+func TestDataCheckInvariantsSingleSample(t *testing.T) {
+	d := &Data{Samples: 1, Mean: 5, Min: 5, Max: 5, m2: 1}
+
+	err := d.CheckInvariants()
+
+	assert.ErrorIs(t, err, ErrInvariant)
+	assert.EqualError(t, err, "invariant violated: variance of a single sample")
+}
+
+func TestDataCheckInvariantsRateSamples(t *testing.T) {
+	d := &Data{Samples: 1, Mean: 5, Min: 5, Max: 5, ItemRate: Rate{Samples: 2}}
+
+	err := d.CheckInvariants()
+
+	assert.ErrorIs(t, err, ErrInvariant)
+	assert.EqualError(t, err, "invariant violated: item rate sample count 2")
+}
+
+func TestDataCheckInvariantsRateVariance(t *testing.T) {
+	d := &Data{Samples: 1, Mean: 5, Min: 5, Max: 5, ByteRate: Rate{Samples: 1, m2: -1}}
+
+	err := d.CheckInvariants()
+
+	assert.ErrorIs(t, err, ErrInvariant)
+	assert.EqualError(t, err, "invariant violated: negative byte rate variance")
+}
+
+func TestDataCheckInvariantsNext(t *testing.T) {
+	d := &Data{Next: &Data{Samples: 2, Mean: 20, Min: 5, Max: 10}}
+
+	err := d.CheckInvariants()
+
+	assert.ErrorIs(t, err, ErrInvariant)
+	assert.EqualError(t, err, "invariant violated: mean 20ns outside [5ns, 10ns]")
+}
+
+func TestDataCheckInvariantsOverflowed(t *testing.T) {
+	d := &Data{Samples: 2, Mean: 20, Min: 5, Max: 10, Overflowed: true}
+
+	err := d.CheckInvariants()
+
+	assert.NoError(t, err)
+}
@@ -0,0 +1,216 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Load describes an open-loop load to apply to a function: the
+// function is invoked at a constant rate, each invocation in its own
+// goroutine, regardless of how long prior invocations take.  This
+// measures latency as seen by independent clients, rather than the
+// closed-loop latency measured by repeatedly calling TimeIt.
+//
+// If an invocation starts later than it was scheduled to, e.g.,
+// because the system is overloaded, the observed call duration
+// understates the latency a client would have seen; this is known as
+// coordinated omission.  Setting CorrectOmission causes latency to be
+// measured from the scheduled start time instead, so that the delay
+// is included.
+//
+// Each scheduled start may be delayed by a random jitter, up to the
+// configured Jitter, to avoid artificial synchronization with the
+// system under test.  If MaxInFlight is set, invocations scheduled
+// while that many invocations are already executing are dropped
+// rather than started, bounding the concurrency of the load.
+type Load struct {
+	Rate            float64       // Invocations per second
+	Duration        time.Duration // How long to apply the load
+	Clock           Clock         // Source of time for scheduling and latency; nil for WallClock
+	CorrectOmission bool          // Measure latency from scheduled start
+	Jitter          time.Duration // Bound on random delay of each start
+	MaxInFlight     int           // Bound on concurrency; 0 for no limit
+}
+
+// LoadResult contains the results of applying a Load.  An invocation
+// is counted as late if it started more than one interval after it
+// was scheduled.
+type LoadResult struct {
+	Rate    float64 // The rate at which the load was applied
+	Calls   int64   // The number of invocations started
+	Dropped int64   // The number of invocations dropped
+	Late    int64   // The number of invocations started late
+	Data    Data    // Latency of the invocations
+}
+
+// loadRun contains the state of a single run of a Load.
+type loadRun struct {
+	Load
+	fn       func()
+	clock    Clock
+	interval time.Duration
+	inFlight chan struct{}
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	result   *LoadResult
+}
+
+// acquire attempts to reserve an in-flight slot for an invocation.
+// It returns false if the invocation should be dropped.
+func (lr *loadRun) acquire() bool {
+	if lr.inFlight == nil {
+		return true
+	}
+
+	select {
+	case lr.inFlight <- struct{}{}:
+		return true
+
+	default:
+		return false
+	}
+}
+
+// release releases an in-flight slot.
+func (lr *loadRun) release() {
+	if lr.inFlight != nil {
+		<-lr.inFlight
+	}
+}
+
+// invoke invokes the function, recording its latency.  The
+// scheduled time is used to correct for coordinated omission if
+// requested.
+func (lr *loadRun) invoke(scheduled time.Time) {
+	defer lr.wg.Done()
+	defer lr.release()
+
+	late := lr.clock.Since(scheduled)
+	start := lr.clock.Now()
+	lr.fn()
+	delta := elapsed(lr.clock, start)
+	if lr.CorrectOmission && late > 0 {
+		delta += late
+	}
+
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	lr.result.Data.Update(delta)
+	if late > lr.interval {
+		lr.result.Late++
+	}
+}
+
+// Run applies the load to the function, returning the results.  If
+// the context is canceled, Run stops starting new invocations and
+// returns the results accumulated so far along with the context's
+// error.  In either case, Run waits for all invocations to complete
+// before returning.  An error wrapping ErrBadRate is returned if the
+// Rate is not positive or is too high for the interval between
+// invocations to be represented.
+func (l Load) Run(ctx context.Context, fn func()) (*LoadResult, error) {
+	interval := time.Duration(0)
+	if l.Rate > 0 {
+		interval = time.Duration(float64(time.Second) / l.Rate)
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("%w: %v", ErrBadRate, l.Rate)
+	}
+
+	lr := &loadRun{
+		Load:     l,
+		fn:       fn,
+		clock:    l.Clock,
+		interval: interval,
+		result:   &LoadResult{Rate: l.Rate},
+	}
+	if lr.clock == nil {
+		lr.clock = WallClock
+	}
+	if l.MaxInFlight > 0 {
+		lr.inFlight = make(chan struct{}, l.MaxInFlight)
+	}
+	defer lr.wg.Wait()
+
+	// Set up the jitter
+	jitter := func() time.Duration { return 0 }
+	if l.Jitter > 0 {
+		rng := rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec
+		jitter = func() time.Duration {
+			return time.Duration(rng.Int63n(int64(l.Jitter)))
+		}
+	}
+
+	// Schedule invocations at fixed intervals from the start
+	start := lr.clock.Now()
+	end := start.Add(l.Duration)
+	scheduled := start.Add(jitter())
+	timer := time.NewTimer(scheduled.Sub(lr.clock.Now()))
+	defer timer.Stop()
+	for next := start; next.Before(end); next = next.Add(lr.interval) {
+		select {
+		case <-ctx.Done():
+			return lr.result, ctx.Err()
+
+		case <-timer.C:
+		}
+
+		// Start the invocation, unless too many are in flight
+		if lr.acquire() {
+			lr.mu.Lock()
+			lr.result.Calls++
+			lr.mu.Unlock()
+			lr.wg.Add(1)
+			go lr.invoke(scheduled)
+		} else {
+			lr.mu.Lock()
+			lr.result.Dropped++
+			lr.mu.Unlock()
+		}
+
+		scheduled = next.Add(lr.interval + jitter())
+		timer.Reset(scheduled.Sub(lr.clock.Now()))
+	}
+
+	return lr.result, nil
+}
+
+// Ramp applies the load to the function at each of the specified
+// rates in turn, each for the load's duration, returning the results
+// of each step.  This produces the data for a latency versus
+// throughput curve.  If the context is canceled, the results of the
+// completed steps and the partial results of the current step are
+// returned along with the context's error; if a rate is invalid, the
+// results of the completed steps are returned along with the error.
+func (l Load) Ramp(ctx context.Context, rates []float64, fn func()) ([]*LoadResult, error) {
+	results := make([]*LoadResult, 0, len(rates))
+	for _, rate := range rates {
+		l.Rate = rate
+		result, err := l.Run(ctx, fn)
+		if result != nil {
+			results = append(results, result)
+		}
+		if err != nil {
+			return results, err
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,247 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build !timeitnoop
+// +build !timeitnoop
+
+package timeit
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadRunAcquireUnlimited(t *testing.T) {
+	lr := &loadRun{}
+
+	assert.True(t, lr.acquire())
+	lr.release()
+}
+
+func TestLoadRunAcquireLimited(t *testing.T) {
+	lr := &loadRun{inFlight: make(chan struct{}, 1)}
+
+	assert.True(t, lr.acquire())
+	assert.False(t, lr.acquire())
+	lr.release()
+	assert.True(t, lr.acquire())
+}
+
+func TestLoadRunInvoke(t *testing.T) {
+	called := false
+	lr := &loadRun{
+		fn:     func() { called = true },
+		clock:  &testClock{now: time.Unix(1000, 0), delta: 50},
+		result: &LoadResult{},
+	}
+	lr.wg.Add(1)
+
+	lr.invoke(time.Unix(999, 0))
+
+	assert.True(t, called)
+	assert.Equal(t, int64(1), lr.result.Data.Samples)
+	assert.Equal(t, time.Duration(50), lr.result.Data.Mean)
+	assert.Equal(t, int64(1), lr.result.Late)
+}
+
+func TestLoadRunInvokeOnTime(t *testing.T) {
+	lr := &loadRun{
+		fn:       func() {},
+		clock:    &testClock{now: time.Unix(1000, 0), delta: 50},
+		interval: time.Hour,
+		result:   &LoadResult{},
+	}
+	lr.wg.Add(1)
+
+	lr.invoke(time.Unix(1000, 0))
+
+	assert.Equal(t, int64(1), lr.result.Data.Samples)
+	assert.Equal(t, int64(0), lr.result.Late)
+}
+
+func TestLoadRunInvokeCorrectOmission(t *testing.T) {
+	lr := &loadRun{
+		Load:   Load{CorrectOmission: true},
+		fn:     func() {},
+		clock:  &testClock{now: time.Unix(1000, 0), delta: 50},
+		result: &LoadResult{},
+	}
+	lr.wg.Add(1)
+
+	lr.invoke(time.Unix(999, 0))
+
+	assert.Equal(t, int64(1), lr.result.Data.Samples)
+	assert.Equal(t, time.Second+100, lr.result.Data.Mean)
+}
+
+func TestLoadRunInvokeCorrectOmissionEarly(t *testing.T) {
+	lr := &loadRun{
+		Load:   Load{CorrectOmission: true},
+		fn:     func() {},
+		clock:  &testClock{now: time.Unix(1000, 0), delta: 50},
+		result: &LoadResult{},
+	}
+	lr.wg.Add(1)
+
+	lr.invoke(time.Unix(1001, 0))
+
+	assert.Equal(t, time.Duration(50), lr.result.Data.Mean)
+}
+
+func TestLoadRunBase(t *testing.T) {
+	l := Load{
+		Rate:     1000,
+		Duration: 20 * time.Millisecond,
+	}
+	count := int64(0)
+
+	result, err := l.Run(context.Background(), func() {
+		atomic.AddInt64(&count, 1)
+		time.Sleep(5 * time.Millisecond)
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1000.0, result.Rate)
+	assert.Equal(t, int64(20), result.Calls)
+	assert.Equal(t, int64(20), atomic.LoadInt64(&count))
+	assert.Equal(t, int64(20), result.Data.Samples)
+	assert.GreaterOrEqual(t, int64(result.Data.Min), int64(5*time.Millisecond))
+}
+
+func TestLoadRunJitter(t *testing.T) {
+	l := Load{
+		Rate:     1000,
+		Duration: 20 * time.Millisecond,
+		Jitter:   500 * time.Microsecond,
+	}
+
+	result, err := l.Run(context.Background(), func() {})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(20), result.Calls)
+	assert.Equal(t, int64(20), result.Data.Samples)
+}
+
+func TestLoadRunMaxInFlight(t *testing.T) {
+	l := Load{
+		Rate:        1000,
+		Duration:    20 * time.Millisecond,
+		MaxInFlight: 1,
+	}
+
+	result, err := l.Run(context.Background(), func() {
+		time.Sleep(5 * time.Millisecond)
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(20), result.Calls+result.Dropped)
+	assert.Greater(t, result.Dropped, int64(0))
+	assert.Equal(t, result.Calls, result.Data.Samples)
+}
+
+func TestLoadRunCanceled(t *testing.T) {
+	l := Load{
+		Rate:     100,
+		Duration: time.Hour,
+		Clock:    &testClock{delta: 50},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	count := int64(0)
+
+	result, err := l.Run(ctx, func() {
+		if atomic.AddInt64(&count, 1) == 3 {
+			cancel()
+		}
+	})
+
+	assert.Same(t, context.Canceled, err)
+	assert.GreaterOrEqual(t, result.Calls, int64(3))
+	assert.Equal(t, result.Calls, result.Data.Samples)
+	assert.Equal(t, time.Duration(50), result.Data.Mean)
+}
+
+func TestLoadRampBase(t *testing.T) {
+	l := Load{
+		Duration: 10 * time.Millisecond,
+	}
+
+	results, err := l.Ramp(context.Background(), []float64{500, 1000}, func() {})
+
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, 500.0, results[0].Rate)
+	assert.Equal(t, int64(5), results[0].Calls)
+	assert.Equal(t, 1000.0, results[1].Rate)
+	assert.Equal(t, int64(10), results[1].Calls)
+}
+
+func TestLoadRampCanceled(t *testing.T) {
+	l := Load{
+		Duration: time.Hour,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, err := l.Ramp(ctx, []float64{500, 1000}, func() {})
+
+	assert.Same(t, context.Canceled, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, 500.0, results[0].Rate)
+}
+
+func TestLoadRunZeroRate(t *testing.T) {
+	l := Load{Duration: time.Hour}
+	called := false
+
+	result, err := l.Run(context.Background(), func() { called = true })
+
+	assert.ErrorIs(t, err, ErrBadRate)
+	assert.Nil(t, result)
+	assert.False(t, called)
+}
+
+func TestLoadRunNegativeRate(t *testing.T) {
+	l := Load{Rate: -1, Duration: time.Hour}
+
+	result, err := l.Run(context.Background(), func() {})
+
+	assert.ErrorIs(t, err, ErrBadRate)
+	assert.Nil(t, result)
+}
+
+func TestLoadRunRateTooHigh(t *testing.T) {
+	l := Load{Rate: 2e9, Duration: time.Hour}
+
+	result, err := l.Run(context.Background(), func() {})
+
+	assert.ErrorIs(t, err, ErrBadRate)
+	assert.Nil(t, result)
+}
+
+func TestLoadRampBadRate(t *testing.T) {
+	l := Load{
+		Duration: 10 * time.Millisecond,
+	}
+
+	results, err := l.Ramp(context.Background(), []float64{500, 0, 1000}, func() {})
+
+	assert.ErrorIs(t, err, ErrBadRate)
+	require.Len(t, results, 1)
+	assert.Equal(t, 500.0, results[0].Rate)
+}
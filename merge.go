@@ -0,0 +1,98 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"math"
+	"time"
+)
+
+// Merge combines the statistics of another Data into this one, as if
+// all the samples of the other Data had been passed to Update.  This
+// allows timing data collected separately, e.g., by different
+// goroutines or in different runs, to be aggregated.  As with
+// Update, nothing is merged if the Data is frozen; unlike Update, the
+// other Data is not passed on to Next.  The counts of invalid and
+// rejected samples, and of overrunning and skipped runs, are merged
+// even if the other Data has no valid samples, and the result is
+// Partial if either Data is.
+func (d *Data) Merge(other *Data) {
+	if d.checkFrozen() {
+		return
+	}
+	d.InvalidSamples += other.InvalidSamples
+	d.Rejected += other.Rejected
+	d.Overruns += other.Overruns
+	d.Skipped += other.Skipped
+	d.mergeOver(other)
+	d.Overflowed = d.Overflowed || other.Overflowed
+	d.Partial = d.Partial || other.Partial
+	d.ItemRate.Merge(&other.ItemRate)
+	d.ByteRate.Merge(&other.ByteRate)
+	if other.Samples == 0 {
+		return
+	}
+
+	// Handle merging into an empty Data
+	if d.Samples == 0 {
+		d.Samples = other.Samples
+		d.Mean = other.Mean
+		d.Max = other.Max
+		d.Min = other.Min
+		d.MinAt = other.MinAt
+		d.MaxAt = other.MaxAt
+		d.m2 = other.m2
+		return
+	}
+
+	// Keep track of minimum and maximum
+	if other.Min < d.Min {
+		d.Min = other.Min
+		d.MinAt = other.MinAt
+	}
+	if other.Max > d.Max {
+		d.Max = other.Max
+		d.MaxAt = other.MaxAt
+	}
+
+	// Combine the counts, means, and m2 values using the parallel
+	// variant of Welford's algorithm
+	na, nb := float64(d.Samples), float64(other.Samples)
+	n := na + nb
+	delta := float64(other.Mean - d.Mean)
+	d.Mean += time.Duration(delta * nb / n)
+	d.setM2(float64(d.m2) + float64(other.m2) + delta*delta*na*nb/n)
+	if d.Samples > math.MaxInt64-other.Samples {
+		d.Samples = math.MaxInt64
+		d.Overflowed = true
+	} else {
+		d.Samples += other.Samples
+	}
+}
+
+// Clone returns a copy of the Data, including its configuration,
+// such as the Flags, Retain, and Thresholds, and its retained samples
+// and previous maxima.  The copy has no Next, so that samples passed
+// to the copy are not also passed to the Data's Next.
+func (d *Data) Clone() *Data {
+	c := *d
+	c.Next = nil
+	c.Thresholds = append([]time.Duration(nil), d.Thresholds...)
+	c.retained = append([]Sample(nil), d.retained...)
+	c.maxima = append([]Sample(nil), d.maxima...)
+	c.over = append([]int64(nil), d.over...)
+
+	return &c
+}
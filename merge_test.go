@@ -0,0 +1,163 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataMergeBase(t *testing.T) {
+	d := &Data{Flags: StdDev, InvalidSamples: 1, Rejected: 1}
+	for _, s := range []time.Duration{10, 20, 30} {
+		d.updateSample(Sample{Duration: s})
+	}
+	other := &Data{InvalidSamples: 2, Rejected: 4, Overflowed: true}
+	for _, s := range []time.Duration{40, 50} {
+		other.updateSample(Sample{Duration: s})
+	}
+
+	d.Merge(other)
+
+	assert.Equal(t, &Data{
+		Samples:        5,
+		Mean:           30,
+		Max:            50,
+		Min:            10,
+		InvalidSamples: 3,
+		Rejected:       5,
+		Overflowed:     true,
+		Flags:          StdDev,
+		m2:             1000,
+	}, d)
+}
+
+func TestDataMergeRuns(t *testing.T) {
+	d := &Data{Overruns: 1, Skipped: 2}
+	other := &Data{Overruns: 3, Skipped: 4}
+
+	d.Merge(other)
+
+	assert.Equal(t, &Data{Overruns: 4, Skipped: 6}, d)
+}
+
+func TestDataMergePartial(t *testing.T) {
+	d := &Data{}
+	d.updateSample(Sample{Duration: 10})
+	other := &Data{Partial: true}
+	other.updateSample(Sample{Duration: 20})
+
+	d.Merge(other)
+
+	assert.True(t, d.Partial)
+	assert.Equal(t, int64(2), d.Samples)
+}
+
+func TestDataMergeNewMin(t *testing.T) {
+	d := &Data{Samples: 1, Mean: 50, Max: 50, Min: 50}
+	other := &Data{Samples: 1, Mean: 10, Max: 10, Min: 10}
+
+	d.Merge(other)
+
+	assert.Equal(t, &Data{
+		Samples: 2,
+		Mean:    30,
+		Max:     50,
+		Min:     10,
+		m2:      800,
+	}, d)
+}
+
+func TestDataMergeEmptyOther(t *testing.T) {
+	d := &Data{Samples: 1, Mean: 50, Max: 50, Min: 50}
+
+	d.Merge(&Data{InvalidSamples: 1, Rejected: 2})
+
+	assert.Equal(t, &Data{Samples: 1, Mean: 50, Max: 50, Min: 50, InvalidSamples: 1, Rejected: 2}, d)
+}
+
+func TestDataMergeEmptySelf(t *testing.T) {
+	d := &Data{Flags: StdDev}
+	other := &Data{
+		Samples:        2,
+		Mean:           30,
+		Max:            50,
+		Min:            10,
+		InvalidSamples: 1,
+		Flags:          Variance,
+		m2:             800,
+	}
+
+	d.Merge(other)
+
+	assert.Equal(t, &Data{
+		Samples:        2,
+		Mean:           30,
+		Max:            50,
+		Min:            10,
+		InvalidSamples: 1,
+		Flags:          StdDev,
+		m2:             800,
+	}, d)
+}
+
+func TestDataMergeSamplesOverflow(t *testing.T) {
+	d := &Data{Samples: math.MaxInt64 - 1, Mean: 50, Max: 50, Min: 50}
+
+	d.Merge(&Data{Samples: 2, Mean: 50, Max: 50, Min: 50})
+
+	assert.Equal(t, int64(math.MaxInt64), d.Samples)
+	assert.True(t, d.Overflowed)
+}
+
+func TestDataCloneBase(t *testing.T) {
+	next := &Data{}
+	d := &Data{
+		Flags:      SampleStdDev,
+		Next:       next,
+		Retain:     2,
+		Extremes:   true,
+		Maxima:     2,
+		Thresholds: []time.Duration{100},
+	}
+	for _, s := range []time.Duration{50, 150, 200} {
+		d.updateSample(Sample{At: time.Unix(1000, 0), Duration: s})
+	}
+
+	result := d.Clone()
+
+	expected := *d
+	expected.Next = nil
+	assert.Equal(t, &expected, result)
+	assert.Equal(t, d.Retained(), result.Retained())
+	assert.Equal(t, int64(3), next.Samples)
+}
+
+func TestDataCloneIndependent(t *testing.T) {
+	d := &Data{Retain: 2, Thresholds: []time.Duration{100}}
+	d.updateSample(Sample{Duration: 150})
+	result := d.Clone()
+
+	result.updateSample(Sample{Duration: 250})
+	result.Thresholds[0] = 200
+
+	assert.Equal(t, int64(1), d.Samples)
+	assert.Len(t, d.Retained(), 1)
+	assert.Equal(t, []time.Duration{100}, d.Thresholds)
+	assert.Equal(t, []int64{1}, d.over)
+}
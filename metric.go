@@ -0,0 +1,100 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// Counter is a count, such as a number of requests or errors, that
+// may be reported alongside timing data by a Reporter.  A Counter is
+// safe for concurrent use.
+type Counter struct {
+	count int64
+}
+
+// Inc increments the counter by 1.
+func (c *Counter) Inc() {
+	atomic.AddInt64(&c.count, 1)
+}
+
+// Add adds n to the counter.
+func (c *Counter) Add(n int64) {
+	atomic.AddInt64(&c.count, n)
+}
+
+// Count returns the current value of the counter.
+func (c *Counter) Count() int64 {
+	return atomic.LoadInt64(&c.count)
+}
+
+// Gauge is a value that may go up and down, such as a queue depth or
+// the number of open connections, that may be reported alongside
+// timing data by a Reporter.  A Gauge is safe for concurrent use.
+type Gauge struct {
+	bits uint64
+}
+
+// Set sets the value of the gauge.
+func (g *Gauge) Set(v float64) {
+	atomic.StoreUint64(&g.bits, math.Float64bits(v))
+}
+
+// Add adds delta, which may be negative, to the value of the gauge.
+func (g *Gauge) Add(delta float64) {
+	for {
+		old := atomic.LoadUint64(&g.bits)
+		updated := math.Float64bits(math.Float64frombits(old) + delta)
+		if atomic.CompareAndSwapUint64(&g.bits, old, updated) {
+			return
+		}
+	}
+}
+
+// Value returns the current value of the gauge.
+func (g *Gauge) Value() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&g.bits))
+}
+
+// counterValues returns the current values of a set of counters, or
+// nil if there are none.
+func counterValues(counters map[string]*Counter) map[string]int64 {
+	if len(counters) == 0 {
+		return nil
+	}
+
+	result := make(map[string]int64, len(counters))
+	for name, c := range counters {
+		result[name] = c.Count()
+	}
+
+	return result
+}
+
+// gaugeValues returns the current values of a set of gauges, or nil
+// if there are none.
+func gaugeValues(gauges map[string]*Gauge) map[string]float64 {
+	if len(gauges) == 0 {
+		return nil
+	}
+
+	result := make(map[string]float64, len(gauges))
+	for name, g := range gauges {
+		result[name] = g.Value()
+	}
+
+	return result
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounterInc(t *testing.T) {
+	c := &Counter{}
+
+	c.Inc()
+	c.Inc()
+
+	assert.Equal(t, int64(2), c.Count())
+}
+
+func TestCounterAdd(t *testing.T) {
+	c := &Counter{}
+
+	c.Add(5)
+	c.Add(-2)
+
+	assert.Equal(t, int64(3), c.Count())
+}
+
+func TestCounterConcurrent(t *testing.T) {
+	c := &Counter{}
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.Inc()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, int64(1000), c.Count())
+}
+
+func TestGaugeSet(t *testing.T) {
+	g := &Gauge{}
+
+	g.Set(2.5)
+
+	assert.Equal(t, 2.5, g.Value())
+}
+
+func TestGaugeAdd(t *testing.T) {
+	g := &Gauge{}
+	g.Set(2.5)
+
+	g.Add(1)
+	g.Add(-3)
+
+	assert.Equal(t, 0.5, g.Value())
+}
+
+func TestGaugeAddConcurrent(t *testing.T) {
+	g := &Gauge{}
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				g.Add(1)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, 1000.0, g.Value())
+}
+
+func TestCounterValuesBase(t *testing.T) {
+	c := &Counter{}
+	c.Add(3)
+
+	result := counterValues(map[string]*Counter{"requests": c})
+
+	assert.Equal(t, map[string]int64{"requests": 3}, result)
+}
+
+func TestCounterValuesEmpty(t *testing.T) {
+	result := counterValues(nil)
+
+	assert.Nil(t, result)
+}
+
+func TestGaugeValuesBase(t *testing.T) {
+	g := &Gauge{}
+	g.Set(7)
+
+	result := gaugeValues(map[string]*Gauge{"depth": g})
+
+	assert.Equal(t, map[string]float64{"depth": 7}, result)
+}
+
+func TestGaugeValuesEmpty(t *testing.T) {
+	result := gaugeValues(nil)
+
+	assert.Nil(t, result)
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// unitSuffixes maps the standard units of time to their suffixes.
+var unitSuffixes = map[time.Duration]string{
+	time.Nanosecond:  "ns",
+	time.Microsecond: "µs",
+	time.Millisecond: "ms",
+	time.Second:      "s",
+	time.Minute:      "m",
+	time.Hour:        "h",
+}
+
+// NumberFormat controls the formatting of numbers and durations in
+// tables such as those written by WriteComparison and
+// WriteIncidents.  The zero value formats durations with
+// time.Duration.String and aligns columns with tab stops; reports
+// destined for spreadsheets, human readers, or monospace logs may
+// each select a more suitable format.  If Color is set, changes in
+// comparisons are colored with ANSI escape sequences: regressions
+// are bold red, improvements are green, and changes no larger than
+// the Tolerance or not statistically significant are dimmed; UseColor
+// may be used to decide whether to enable color.
+type NumberFormat struct {
+	Unit      time.Duration // Unit for durations; 0 for automatic
+	Decimals  int           // Decimal places for durations with a Unit
+	Human     bool          // Use three significant digits if no Unit
+	Thousands string        // Thousands separator, e.g., ","
+	Point     string        // Decimal point; "" for "."
+	Width     int           // Fixed column width; 0 to align to tab stops
+	Color     bool          // Color changes in comparisons
+	Tolerance float64       // Largest relative change considered noise
+}
+
+// number applies the separators to a formatted number, such as that
+// returned by strconv.FormatFloat.  The number may be followed by a
+// suffix, such as a unit, which is left unchanged.
+func (nf *NumberFormat) number(text string) string {
+	// Split off the sign, the integer part, the fraction, and the
+	// suffix
+	sign := ""
+	if strings.HasPrefix(text, "-") || strings.HasPrefix(text, "+") {
+		sign, text = text[:1], text[1:]
+	}
+	digits := func(s string) int {
+		i := 0
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		return i
+	}
+	n := digits(text)
+	text, suffix := text[:n], text[n:]
+	frac := ""
+	if strings.HasPrefix(suffix, ".") {
+		n = digits(suffix[1:])
+		frac, suffix = suffix[1:n+1], suffix[n+1:]
+	}
+
+	// Insert the thousands separators
+	if nf.Thousands != "" {
+		groups := []string{}
+		for len(text) > 3 {
+			groups = append([]string{text[len(text)-3:]}, groups...)
+			text = text[:len(text)-3]
+		}
+		text = strings.Join(append([]string{text}, groups...), nf.Thousands)
+	}
+
+	// Add the fraction
+	if frac != "" {
+		point := nf.Point
+		if point == "" {
+			point = "."
+		}
+		text += point + frac
+	}
+
+	return sign + text + suffix
+}
+
+// Int formats an integer.
+func (nf *NumberFormat) Int(n int64) string {
+	return nf.number(strconv.FormatInt(n, 10))
+}
+
+// Duration formats a duration.  If a Unit is set, the duration is
+// formatted as a number of that unit with the configured number of
+// decimal places, followed by the unit suffix if the unit is a
+// standard unit; otherwise, it is formatted with three significant
+// digits if Human is set, or with time.Duration.String.
+func (nf *NumberFormat) Duration(d time.Duration) string {
+	switch {
+	case nf.Unit > 0:
+		return nf.number(strconv.FormatFloat(float64(d)/float64(nf.Unit), 'f', nf.Decimals, 64)) + unitSuffixes[nf.Unit]
+	case nf.Human:
+		return nf.number(humanDuration(d))
+	}
+
+	return d.String()
+}
+
+// Percent formats the change from one duration to another as a
+// signed percentage; see the "percentChange" template function.
+func (nf *NumberFormat) Percent(from, to time.Duration) string {
+	return nf.number(percentChange(from, to))
+}
+
+// writeTable writes a table to the writer.  If a Width is set, each
+// column is padded to that width, with the first column aligned to
+// the left and the remainder to the right; otherwise, the columns are
+// aligned to tab stops.  The colors, if not nil, give the ANSI escape
+// sequence with which to color the last cell of each row; since the
+// escape sequences have no width, only the last cell may be colored
+// without disturbing the alignment.
+func (nf *NumberFormat) writeTable(w io.Writer, rows [][]string, colors []string) error {
+	if nf.Width <= 0 {
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		for i, row := range rows {
+			if colors != nil {
+				row[len(row)-1] = colorize(colors[i], row[len(row)-1])
+			}
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+
+		return tw.Flush()
+	}
+
+	for i, row := range rows {
+		line := &strings.Builder{}
+		for j, cell := range row {
+			if j == 0 {
+				fmt.Fprintf(line, "%-*s", nf.Width, cell)
+				continue
+			}
+			cell = fmt.Sprintf(" %*s", nf.Width, cell)
+			if colors != nil && j == len(row)-1 {
+				cell = colorize(colors[i], cell)
+			}
+			line.WriteString(cell)
+		}
+		line.WriteByte('\n')
+
+		if _, err := io.WriteString(w, line.String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
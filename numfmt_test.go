@@ -0,0 +1,154 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumberFormatNumberBase(t *testing.T) {
+	nf := &NumberFormat{}
+
+	result := nf.number("-1234567.89")
+
+	assert.Equal(t, "-1234567.89", result)
+}
+
+func TestNumberFormatNumberSeparators(t *testing.T) {
+	nf := &NumberFormat{Thousands: ".", Point: ","}
+
+	assert.Equal(t, "-1.234.567,89", nf.number("-1234567.89"))
+	assert.Equal(t, "+123.456", nf.number("+123456"))
+	assert.Equal(t, "123", nf.number("123"))
+	assert.Equal(t, "0,5", nf.number("0.5"))
+}
+
+func TestNumberFormatInt(t *testing.T) {
+	nf := &NumberFormat{Thousands: ","}
+
+	result := nf.Int(1234567)
+
+	assert.Equal(t, "1,234,567", result)
+}
+
+func TestNumberFormatDurationDefault(t *testing.T) {
+	nf := &NumberFormat{}
+
+	result := nf.Duration(1234567)
+
+	assert.Equal(t, "1.234567ms", result)
+}
+
+func TestNumberFormatDurationUnit(t *testing.T) {
+	nf := &NumberFormat{Unit: time.Microsecond, Decimals: 1, Thousands: ","}
+
+	result := nf.Duration(1234567)
+
+	assert.Equal(t, "1,234.6µs", result)
+}
+
+func TestNumberFormatDurationOtherUnit(t *testing.T) {
+	nf := &NumberFormat{Unit: 10 * time.Millisecond, Decimals: 2}
+
+	result := nf.Duration(1234567 * 10)
+
+	assert.Equal(t, "1.23", result)
+}
+
+func TestNumberFormatDurationHuman(t *testing.T) {
+	nf := &NumberFormat{Human: true, Point: ","}
+
+	result := nf.Duration(1234567)
+
+	assert.Equal(t, "1,23ms", result)
+}
+
+func TestNumberFormatNumberSuffix(t *testing.T) {
+	nf := &NumberFormat{Thousands: ".", Point: ","}
+
+	assert.Equal(t, "123ms", nf.number("123ms"))
+	assert.Equal(t, "-1.234,5µs", nf.number("-1234.5µs"))
+	assert.Equal(t, "1m30s", nf.number("1m30s"))
+	assert.Equal(t, "-", nf.number("-"))
+}
+
+func TestNumberFormatDurationHumanSeparators(t *testing.T) {
+	nf := &NumberFormat{Human: true, Thousands: ","}
+
+	assert.Equal(t, "123ms", nf.Duration(123*time.Millisecond))
+	assert.Equal(t, "1m30s", nf.Duration(90*time.Second))
+	assert.Equal(t, "1h0m0s", nf.Duration(time.Hour))
+	assert.Equal(t, "1.23ms", nf.Duration(1234567))
+}
+
+func TestNumberFormatPercent(t *testing.T) {
+	nf := &NumberFormat{Point: ","}
+
+	result := nf.Percent(800, 900)
+
+	assert.Equal(t, "+12,5%", result)
+}
+
+func TestNumberFormatWriteTableTabs(t *testing.T) {
+	buf := &bytes.Buffer{}
+	nf := &NumberFormat{}
+
+	err := nf.writeTable(buf, [][]string{{"A", "BB"}, {"CCC", "D"}}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "A    BB\nCCC  D\n", buf.String())
+}
+
+func TestNumberFormatWriteTableFixed(t *testing.T) {
+	buf := &bytes.Buffer{}
+	nf := &NumberFormat{Width: 4}
+
+	err := nf.writeTable(buf, [][]string{{"A", "BB"}, {"CCC", "D"}}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "A      BB\nCCC     D\n", buf.String())
+}
+
+func TestNumberFormatWriteTableFixedError(t *testing.T) {
+	nf := &NumberFormat{Width: 4}
+
+	err := nf.writeTable(errWriter{}, [][]string{{"A", "BB"}}, nil)
+
+	assert.Same(t, assert.AnError, err)
+}
+
+func TestNumberFormatWriteTableTabsColor(t *testing.T) {
+	buf := &bytes.Buffer{}
+	nf := &NumberFormat{}
+
+	err := nf.writeTable(buf, [][]string{{"A", "BB"}, {"CCC", "D"}}, []string{"", ansiGreen})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "A    BB\nCCC  \x1b[32mD\x1b[0m\n", buf.String())
+}
+
+func TestNumberFormatWriteTableFixedColor(t *testing.T) {
+	buf := &bytes.Buffer{}
+	nf := &NumberFormat{Width: 4}
+
+	err := nf.writeTable(buf, [][]string{{"A", "BB"}, {"CCC", "D"}}, []string{"", ansiGreen})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "A      BB\nCCC \x1b[32m    D\x1b[0m\n", buf.String())
+}
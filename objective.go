@@ -0,0 +1,164 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Percentile returns the pth percentile of the retained samples (see
+// Retained), using the nearest-rank method, or 0 if no samples are
+// retained.  The percentile should be between 0 and 100.
+func (d *Data) Percentile(p float64) time.Duration {
+	if len(d.retained) == 0 {
+		return 0
+	}
+
+	samples := make([]time.Duration, len(d.retained))
+	for i, s := range d.retained {
+		samples[i] = s.Duration
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+
+	return samples[rank(p, len(samples))]
+}
+
+// rank returns the index of the pth percentile in a sorted slice of n
+// elements, using the nearest-rank method.
+func rank(p float64, n int) int {
+	idx := int(math.Ceil(p/100*float64(n))) - 1
+	switch {
+	case idx < 0:
+		return 0
+	case idx >= n:
+		return n - 1
+	}
+
+	return idx
+}
+
+// Objective describes a latency objective for a timer, such as "99%
+// of samples complete within 250ms."
+type Objective struct {
+	Percentile float64       `json:"percentile" yaml:"percentile"` // Percentage of samples, e.g., 99
+	Threshold  time.Duration `json:"threshold" yaml:"threshold"`   // Latency they must not exceed
+}
+
+// String returns a description of the objective, e.g., "p99 <= 250ms".
+func (o Objective) String() string {
+	return fmt.Sprintf("p%s <= %s", strconv.FormatFloat(o.Percentile, 'f', -1, 64), o.Threshold)
+}
+
+// ObjectiveResult describes how well a timer met its objective.
+// Budget is the fraction of the error budget consumed: the number of
+// samples exceeding the threshold, divided by the number the
+// objective permits to exceed it.  An objective with a Percentile of
+// 100 permits no such samples, so any violation consumes the whole
+// budget.
+type ObjectiveResult struct {
+	Name      string        `json:"name" yaml:"name"`           // Name of the timer
+	Objective Objective     `json:"objective" yaml:"objective"` // The objective
+	Samples   int           `json:"samples" yaml:"samples"`     // Number of samples considered
+	Achieved  time.Duration `json:"achieved" yaml:"achieved"`   // Actual latency at the Percentile
+	Budget    float64       `json:"budget" yaml:"budget"`       // Fraction of error budget consumed
+	Pass      bool          `json:"pass" yaml:"pass"`           // Set if the objective was met
+}
+
+// CheckObjectives checks the retained samples of each timer in the
+// dataset against its objective, returning the results sorted by
+// timer name.  The retained samples form the window over which the
+// objective is evaluated, so retention must be enabled (see
+// Retained); timers with no retained samples, or which are not in the
+// dataset, are omitted.
+func CheckObjectives(dataset map[string]*Data, objectives map[string]Objective) []ObjectiveResult {
+	names := make([]string, 0, len(objectives))
+	for name := range objectives {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := []ObjectiveResult{}
+	for _, name := range names {
+		d, ok := dataset[name]
+		if !ok || len(d.retained) == 0 {
+			continue
+		}
+		obj := objectives[name]
+
+		// Count the samples exceeding the threshold
+		over := 0
+		for _, s := range d.retained {
+			if s.Duration > obj.Threshold {
+				over++
+			}
+		}
+
+		// Compute the error budget consumed
+		budget := 0.0
+		if allowed := float64(len(d.retained)) * (100 - obj.Percentile) / 100; allowed > 0 {
+			budget = float64(over) / allowed
+		} else if over > 0 {
+			budget = 1
+		}
+
+		achieved := d.Percentile(obj.Percentile)
+		result = append(result, ObjectiveResult{
+			Name:      name,
+			Objective: obj,
+			Samples:   len(d.retained),
+			Achieved:  achieved,
+			Budget:    budget,
+			Pass:      achieved <= obj.Threshold,
+		})
+	}
+
+	return result
+}
+
+// WriteReportCard writes a table summarizing the results of
+// CheckObjectives to the specified writer, using the default
+// NumberFormat.  For each timer, the objective, the achieved latency,
+// the error budget consumed, and whether the objective was met are
+// shown.  If the writer is a terminal, the status is colored (see
+// UseColor).
+func WriteReportCard(w io.Writer, results []ObjectiveResult) error {
+	return (&NumberFormat{Color: UseColor(w)}).WriteReportCard(w, results)
+}
+
+// WriteReportCard is similar to the WriteReportCard function, but
+// formats the table using the NumberFormat.
+func (nf *NumberFormat) WriteReportCard(w io.Writer, results []ObjectiveResult) error {
+	rows := [][]string{{"TIMER", "OBJECTIVE", "SAMPLES", "ACHIEVED", "BUDGET", "STATUS"}}
+	colors := []string{""}
+	for _, r := range results {
+		status, color := "FAIL", ansiBoldRed
+		if r.Pass {
+			status, color = "PASS", ansiGreen
+		}
+		if !nf.Color {
+			color = ""
+		}
+		budget := nf.number(strconv.FormatFloat(100*r.Budget, 'f', 1, 64)) + "%"
+		rows = append(rows, []string{r.Name, r.Objective.String(), nf.Int(int64(r.Samples)), nf.Duration(r.Achieved), budget, status})
+		colors = append(colors, color)
+	}
+
+	return nf.writeTable(w, rows, colors)
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func objectiveData(samples ...time.Duration) *Data {
+	d := &Data{Retain: len(samples), Clock: &testClock{now: time.Unix(1000, 0)}}
+	for _, s := range samples {
+		d.updateSample(Sample{Duration: s})
+	}
+
+	return d
+}
+
+func TestDataPercentileBase(t *testing.T) {
+	d := objectiveData(50, 10, 40, 20, 30)
+
+	assert.Equal(t, time.Duration(10), d.Percentile(0))
+	assert.Equal(t, time.Duration(10), d.Percentile(20))
+	assert.Equal(t, time.Duration(30), d.Percentile(50))
+	assert.Equal(t, time.Duration(50), d.Percentile(99))
+	assert.Equal(t, time.Duration(50), d.Percentile(150))
+}
+
+func TestDataPercentileEmpty(t *testing.T) {
+	d := &Data{}
+
+	assert.Equal(t, time.Duration(0), d.Percentile(50))
+}
+
+func TestObjectiveString(t *testing.T) {
+	obj := Objective{Percentile: 99.9, Threshold: 250 * time.Millisecond}
+
+	assert.Equal(t, "p99.9 <= 250ms", obj.String())
+}
+
+func TestCheckObjectives(t *testing.T) {
+	dataset := map[string]*Data{
+		"fast":   objectiveData(10, 20, 30, 40, 50, 60, 70, 80, 90, 100),
+		"slow":   objectiveData(10, 20, 30, 40, 50, 60, 70, 80, 190, 200),
+		"strict": objectiveData(10, 20, 30, 40, 50, 60, 70, 80, 90, 200),
+		"empty":  {},
+	}
+	objectives := map[string]Objective{
+		"fast":    {Percentile: 90, Threshold: 95},
+		"slow":    {Percentile: 90, Threshold: 95},
+		"strict":  {Percentile: 100, Threshold: 100},
+		"empty":   {Percentile: 90, Threshold: 95},
+		"missing": {Percentile: 90, Threshold: 95},
+	}
+
+	result := CheckObjectives(dataset, objectives)
+
+	assert.Equal(t, []ObjectiveResult{
+		{
+			Name:      "fast",
+			Objective: Objective{Percentile: 90, Threshold: 95},
+			Samples:   10,
+			Achieved:  90,
+			Budget:    1,
+			Pass:      true,
+		},
+		{
+			Name:      "slow",
+			Objective: Objective{Percentile: 90, Threshold: 95},
+			Samples:   10,
+			Achieved:  190,
+			Budget:    2,
+		},
+		{
+			Name:      "strict",
+			Objective: Objective{Percentile: 100, Threshold: 100},
+			Samples:   10,
+			Achieved:  200,
+			Budget:    1,
+		},
+	}, result)
+}
+
+func TestCheckObjectivesBoundary(t *testing.T) {
+	dataset := map[string]*Data{
+		"edge": objectiveData(10, 20, 30, 40, 50, 60, 70, 80, 90, 100),
+	}
+	objectives := map[string]Objective{
+		"edge": {Percentile: 90, Threshold: 90},
+	}
+
+	result := CheckObjectives(dataset, objectives)
+
+	assert.Equal(t, []ObjectiveResult{
+		{
+			Name:      "edge",
+			Objective: Objective{Percentile: 90, Threshold: 90},
+			Samples:   10,
+			Achieved:  90,
+			Budget:    1,
+			Pass:      true,
+		},
+	}, result)
+}
+
+func TestWriteReportCard(t *testing.T) {
+	buf := &bytes.Buffer{}
+	results := []ObjectiveResult{
+		{
+			Name:      "fast",
+			Objective: Objective{Percentile: 99, Threshold: 250 * time.Millisecond},
+			Samples:   1000,
+			Achieved:  200 * time.Millisecond,
+			Budget:    0.5,
+			Pass:      true,
+		},
+		{
+			Name:      "slow",
+			Objective: Objective{Percentile: 99, Threshold: 250 * time.Millisecond},
+			Samples:   1000,
+			Achieved:  300 * time.Millisecond,
+			Budget:    2,
+		},
+	}
+
+	err := WriteReportCard(buf, results)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `TIMER  OBJECTIVE     SAMPLES  ACHIEVED  BUDGET  STATUS
+fast   p99 <= 250ms  1000     200ms     50.0%   PASS
+slow   p99 <= 250ms  1000     300ms     200.0%  FAIL
+`, buf.String())
+}
+
+func TestNumberFormatWriteReportCardColor(t *testing.T) {
+	buf := &bytes.Buffer{}
+	results := []ObjectiveResult{
+		{
+			Name:      "fast",
+			Objective: Objective{Percentile: 99, Threshold: 250},
+			Samples:   1000,
+			Achieved:  200,
+			Budget:    0.5,
+			Pass:      true,
+		},
+		{
+			Name:      "slow",
+			Objective: Objective{Percentile: 99, Threshold: 250},
+			Samples:   1000,
+			Achieved:  300,
+			Budget:    2,
+		},
+	}
+	nf := &NumberFormat{Color: true, Thousands: ","}
+
+	err := nf.WriteReportCard(buf, results)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "TIMER  OBJECTIVE     SAMPLES  ACHIEVED  BUDGET  STATUS\n"+
+		"fast   p99 <= 250ns  1,000    200ns     50.0%   \x1b[32mPASS\x1b[0m\n"+
+		"slow   p99 <= 250ns  1,000    300ns     200.0%  \x1b[1;31mFAIL\x1b[0m\n", buf.String())
+}
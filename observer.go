@@ -0,0 +1,54 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"log"
+	"sync/atomic"
+)
+
+// PanicFunc is the type of a callback invoked with the value of a
+// panic recovered from an observer callback, such as those of a
+// Detector or BurnAlerter.
+type PanicFunc func(v interface{})
+
+// LogPanics returns a PanicFunc that logs recovered panics to the
+// specified logger, or to the standard logger if it is nil.
+func LogPanics(logger *log.Logger) PanicFunc {
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	return func(v interface{}) {
+		logger.Printf("timeit: observer panic: %v", v)
+	}
+}
+
+// callObserver invokes an observer callback, recovering from any
+// panic so that a buggy observer cannot take down the instrumented
+// application.  Recovered panics are counted and passed to the
+// PanicFunc, if one is given.
+func callObserver(fn func(), panics *int64, onPanic PanicFunc) {
+	defer func() {
+		if v := recover(); v != nil {
+			atomic.AddInt64(panics, 1)
+			if onPanic != nil {
+				onPanic(v)
+			}
+		}
+	}()
+
+	fn()
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogPanicsBase(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := log.New(buf, "", 0)
+
+	LogPanics(logger)("oops")
+
+	assert.Equal(t, "timeit: observer panic: oops\n", buf.String())
+}
+
+func TestLogPanicsDefault(t *testing.T) {
+	buf := &bytes.Buffer{}
+	orig := log.Writer()
+	flags := log.Flags()
+	defer func() {
+		log.SetOutput(orig)
+		log.SetFlags(flags)
+	}()
+	log.SetOutput(buf)
+	log.SetFlags(0)
+
+	LogPanics(nil)("oops")
+
+	assert.Equal(t, "timeit: observer panic: oops\n", buf.String())
+}
+
+func TestCallObserverBase(t *testing.T) {
+	var panics int64
+	called := false
+
+	callObserver(func() { called = true }, &panics, func(v interface{}) {
+		t.Error("unexpected panic")
+	})
+
+	assert.True(t, called)
+	assert.Equal(t, int64(0), panics)
+}
+
+func TestCallObserverPanic(t *testing.T) {
+	var panics int64
+	var recovered []interface{}
+
+	callObserver(func() { panic("oops") }, &panics, func(v interface{}) {
+		recovered = append(recovered, v)
+	})
+
+	assert.Equal(t, int64(1), panics)
+	assert.Equal(t, []interface{}{"oops"}, recovered)
+}
+
+func TestCallObserverPanicNoHandler(t *testing.T) {
+	var panics int64
+
+	callObserver(func() { panic("oops") }, &panics, nil)
+
+	assert.Equal(t, int64(1), panics)
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// stdout is the writer to which Options.Write writes if no output
+// file is configured.  It is a variable so that it may be overridden
+// by tests.
+var stdout io.Writer = os.Stdout
+
+// Environment variables read by Options.LoadEnv.
+const (
+	EnvFormat   = "TIMEIT_FORMAT"   // Output format
+	EnvOutput   = "TIMEIT_OUTPUT"   // Output file
+	EnvFlags    = "TIMEIT_FLAGS"    // Computed fields to include
+	EnvInterval = "TIMEIT_INTERVAL" // Report interval, e.g., "10s"
+	EnvEnabled  = "TIMEIT_ENABLED"  // Whether output is enabled, e.g., "false"
+)
+
+// flagNames maps the names of the marshal flags, as used on the
+// command line, to the flags.
+var flagNames = map[string]MarshalFlags{
+	"variance":        Variance,
+	"sample_variance": SampleVariance,
+	"std_dev":         StdDev,
+	"sample_std_dev":  SampleStdDev,
+}
+
+// String returns the flags as a comma-separated list of names, e.g.,
+// "sample_std_dev,std_dev".
+func (f MarshalFlags) String() string {
+	names := []string{}
+	for name, bit := range flagNames {
+		if f&bit != 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ",")
+}
+
+// Set sets the flags from a comma-separated list of names.  An error
+// wrapping ErrUnknownFlag is returned if a name is not recognized.
+// Together with String, this allows MarshalFlags to be used as a
+// flag.Value.
+func (f *MarshalFlags) Set(value string) error {
+	var result MarshalFlags
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		bit, ok := flagNames[name]
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrUnknownFlag, name)
+		}
+		result |= bit
+	}
+	*f = result
+
+	return nil
+}
+
+// Options contains the timing output options of a command-line
+// program.  RegisterFlags registers standard flags for setting the
+// options, so that programs can expose timing output options
+// uniformly; LoadEnv loads the options from environment variables,
+// so that they can be changed in containers without code or flag
+// changes.
+type Options struct {
+	Format   string        // Output format; "" for "json"
+	Output   string        // Output file; "" or "-" for standard output
+	Flags    MarshalFlags  // Computed fields to include; 0 to leave as is
+	Interval time.Duration // Interval between reports, for a Reporter
+	Disabled bool          // Set to disable output
+}
+
+// LoadEnv loads the options from the TIMEIT_* environment variables
+// (see EnvFormat and friends).  Options whose environment variables
+// are not set are left unchanged, so LoadEnv may be called before
+// parsing flags to let the flags take precedence.  An error wrapping
+// ErrBadEnv is returned if an environment variable cannot be parsed.
+func (o *Options) LoadEnv() error {
+	if value, ok := os.LookupEnv(EnvFormat); ok {
+		o.Format = value
+	}
+	if value, ok := os.LookupEnv(EnvOutput); ok {
+		o.Output = value
+	}
+	if value, ok := os.LookupEnv(EnvFlags); ok {
+		if err := o.Flags.Set(value); err != nil {
+			return fmt.Errorf("%w: %s: %s", ErrBadEnv, EnvFlags, err)
+		}
+	}
+	if value, ok := os.LookupEnv(EnvInterval); ok {
+		interval, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %s", ErrBadEnv, EnvInterval, err)
+		}
+		o.Interval = interval
+	}
+	if value, ok := os.LookupEnv(EnvEnabled); ok {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %s", ErrBadEnv, EnvEnabled, err)
+		}
+		o.Disabled = !enabled
+	}
+
+	return nil
+}
+
+// RegisterFlags registers the standard flags for the options on the
+// flag set: -timeit.format, -timeit.output, -timeit.flags, and
+// -timeit.interval.  The current values of the options are used as
+// the defaults.
+func (o *Options) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.Format, "timeit.format", o.Format, fmt.Sprintf("Output format for timing data (%s)", strings.Join(Formats(), ", ")))
+	fs.StringVar(&o.Output, "timeit.output", o.Output, "Output file for timing data; \"-\" for standard output")
+	fs.Var(&o.Flags, "timeit.flags", "Comma-separated computed fields to include in timing data (sample_std_dev, sample_variance, std_dev, variance)")
+	fs.DurationVar(&o.Interval, "timeit.interval", o.Interval, "Interval between timing reports")
+}
+
+// format returns the output format.
+func (o *Options) format() string {
+	if o.Format == "" {
+		return "json"
+	}
+
+	return o.Format
+}
+
+// Write writes the dataset in the configured format to the configured
+// output, unless output is Disabled.  If Flags is set, it is applied
+// to copies of each Data in the dataset; the dataset itself is not
+// modified.  An error wrapping ErrUnknownFormat is returned if the
+// format is not known.
+func (o *Options) Write(dataset map[string]*Data) error {
+	if o.Disabled {
+		return nil
+	}
+
+	enc, err := LookupFormat(o.format())
+	if err != nil {
+		return err
+	}
+
+	if o.Flags != 0 {
+		copies := make(map[string]*Data, len(dataset))
+		for name, d := range dataset {
+			copies[name] = d.Clone()
+			copies[name].Flags = o.Flags
+		}
+		dataset = copies
+	}
+
+	if o.Output == "" || o.Output == "-" {
+		return enc.Encode(stdout, dataset)
+	}
+
+	f, err := os.Create(o.Output)
+	if err != nil {
+		return err
+	}
+	err = enc.Encode(f, dataset)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalFlagsImplementsValue(t *testing.T) {
+	assert.Implements(t, (*flag.Value)(nil), new(MarshalFlags))
+}
+
+func TestMarshalFlagsString(t *testing.T) {
+	assert.Equal(t, "", MarshalFlags(0).String())
+	assert.Equal(t, "sample_std_dev,variance", (Variance | SampleStdDev).String())
+}
+
+func TestMarshalFlagsSetBase(t *testing.T) {
+	f := Variance
+
+	err := f.Set("std_dev, sample_std_dev,")
+
+	assert.NoError(t, err)
+	assert.Equal(t, StdDev|SampleStdDev, f)
+}
+
+func TestMarshalFlagsSetUnknown(t *testing.T) {
+	f := Variance
+
+	err := f.Set("std_dev,bogus")
+
+	assert.ErrorIs(t, err, ErrUnknownFlag)
+	assert.EqualError(t, err, `unknown marshal flag: "bogus"`)
+	assert.Equal(t, Variance, f)
+}
+
+func TestOptionsRegisterFlags(t *testing.T) {
+	o := &Options{Format: "yaml"}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	o.RegisterFlags(fs)
+
+	err := fs.Parse([]string{"-timeit.output", "out.json", "-timeit.flags", "std_dev", "-timeit.interval", "5s"})
+
+	require.NoError(t, err)
+	assert.Equal(t, &Options{Format: "yaml", Output: "out.json", Flags: StdDev, Interval: 5 * time.Second}, o)
+	assert.Equal(t, "yaml", fs.Lookup("timeit.format").DefValue)
+}
+
+func TestOptionsLoadEnvBase(t *testing.T) {
+	t.Setenv(EnvFormat, "yaml")
+	t.Setenv(EnvOutput, "out.yaml")
+	t.Setenv(EnvFlags, "variance")
+	t.Setenv(EnvInterval, "10s")
+	t.Setenv(EnvEnabled, "false")
+	o := &Options{Format: "json", Flags: StdDev}
+
+	err := o.LoadEnv()
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Options{
+		Format:   "yaml",
+		Output:   "out.yaml",
+		Flags:    Variance,
+		Interval: 10 * time.Second,
+		Disabled: true,
+	}, o)
+}
+
+func TestOptionsLoadEnvUnset(t *testing.T) {
+	for _, name := range []string{EnvFormat, EnvOutput, EnvFlags, EnvInterval, EnvEnabled} {
+		t.Setenv(name, "")
+		_ = os.Unsetenv(name)
+	}
+	o := &Options{Format: "json", Flags: StdDev, Disabled: true}
+
+	err := o.LoadEnv()
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Options{Format: "json", Flags: StdDev, Disabled: true}, o)
+}
+
+func TestOptionsLoadEnvBadFlags(t *testing.T) {
+	t.Setenv(EnvFlags, "bogus")
+	o := &Options{}
+
+	err := o.LoadEnv()
+
+	assert.ErrorIs(t, err, ErrBadEnv)
+	assert.EqualError(t, err, `invalid environment variable: TIMEIT_FLAGS: unknown marshal flag: "bogus"`)
+}
+
+func TestOptionsLoadEnvBadInterval(t *testing.T) {
+	t.Setenv(EnvInterval, "bogus")
+	o := &Options{}
+
+	err := o.LoadEnv()
+
+	assert.ErrorIs(t, err, ErrBadEnv)
+}
+
+func TestOptionsLoadEnvBadEnabled(t *testing.T) {
+	t.Setenv(EnvEnabled, "bogus")
+	o := &Options{}
+
+	err := o.LoadEnv()
+
+	assert.ErrorIs(t, err, ErrBadEnv)
+}
+
+func TestOptionsWriteDisabled(t *testing.T) {
+	o := &Options{Format: "bogus", Disabled: true}
+
+	err := o.Write(testDataset)
+
+	assert.NoError(t, err)
+}
+
+func TestOptionsWriteStdout(t *testing.T) {
+	buf := &bytes.Buffer{}
+	defer func(orig io.Writer) { stdout = orig }(stdout)
+	stdout = buf
+	o := &Options{Flags: StdDev}
+	dataset := map[string]*Data{
+		"a": {Samples: 3, Mean: 50, Max: 75, Min: 25, m2: 1250},
+	}
+
+	err := o.Write(dataset)
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"a": {"samples": 3, "mean": 50, "max": 75, "min": 25, "std_dev": 20}}`, buf.String())
+	assert.Equal(t, MarshalFlags(0), dataset["a"].Flags)
+}
+
+func TestOptionsWriteFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "out.yaml")
+	o := &Options{Format: "yaml", Output: name}
+
+	err := o.Write(testDataset)
+
+	require.NoError(t, err)
+	text, err := os.ReadFile(name)
+	require.NoError(t, err)
+	assert.Equal(t, `a:
+  samples: 3
+  mean: 50ns
+  max: 75ns
+  min: 25ns
+  std_dev: 20ns
+`, string(text))
+}
+
+func TestOptionsWriteUnknownFormat(t *testing.T) {
+	o := &Options{Format: "bogus"}
+
+	err := o.Write(testDataset)
+
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestOptionsWriteCreateError(t *testing.T) {
+	o := &Options{Output: filepath.Join(t.TempDir(), "missing", "out.json")}
+
+	err := o.Write(testDataset)
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"math"
+	"time"
+)
+
+// addSat adds two durations, saturating at the limits of the type
+// instead of wrapping.  The boolean return is true if the addition
+// overflowed.
+func addSat(a, b time.Duration) (time.Duration, bool) {
+	c := a + b
+	if b > 0 && c < a {
+		return math.MaxInt64, true
+	} else if b < 0 && c > a {
+		return math.MinInt64, true
+	}
+
+	return c, false
+}
+
+// mulSat multiplies two durations, saturating at the limits of the
+// type instead of wrapping.  The boolean return is true if the
+// multiplication overflowed.
+func mulSat(a, b time.Duration) (time.Duration, bool) {
+	if a == 0 || b == 0 {
+		return 0, false
+	}
+
+	c := a * b
+	if c/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		if (a < 0) == (b < 0) {
+			return math.MaxInt64, true
+		}
+		return math.MinInt64, true
+	}
+
+	return c, false
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddSat(t *testing.T) {
+	result, over := addSat(5, 3)
+	assert.Equal(t, time.Duration(8), result)
+	assert.False(t, over)
+
+	result, over = addSat(5, -3)
+	assert.Equal(t, time.Duration(2), result)
+	assert.False(t, over)
+
+	result, over = addSat(math.MaxInt64-1, 3)
+	assert.Equal(t, time.Duration(math.MaxInt64), result)
+	assert.True(t, over)
+
+	result, over = addSat(math.MinInt64+1, -3)
+	assert.Equal(t, time.Duration(math.MinInt64), result)
+	assert.True(t, over)
+}
+
+func TestMulSat(t *testing.T) {
+	result, over := mulSat(5, 3)
+	assert.Equal(t, time.Duration(15), result)
+	assert.False(t, over)
+
+	result, over = mulSat(0, math.MaxInt64)
+	assert.Equal(t, time.Duration(0), result)
+	assert.False(t, over)
+
+	result, over = mulSat(5, -3)
+	assert.Equal(t, time.Duration(-15), result)
+	assert.False(t, over)
+
+	result, over = mulSat(10*time.Second, 10*time.Second)
+	assert.Equal(t, time.Duration(math.MaxInt64), result)
+	assert.True(t, over)
+
+	result, over = mulSat(-10*time.Second, -10*time.Second)
+	assert.Equal(t, time.Duration(math.MaxInt64), result)
+	assert.True(t, over)
+
+	result, over = mulSat(-10*time.Second, 10*time.Second)
+	assert.Equal(t, time.Duration(math.MinInt64), result)
+	assert.True(t, over)
+
+	result, over = mulSat(-1, math.MinInt64)
+	assert.Equal(t, time.Duration(math.MaxInt64), result)
+	assert.True(t, over)
+
+	result, over = mulSat(math.MinInt64, -1)
+	assert.Equal(t, time.Duration(math.MaxInt64), result)
+	assert.True(t, over)
+}
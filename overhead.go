@@ -0,0 +1,144 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// overheadRuns is the number of empty measurements timed to estimate
+// the cost of instrumentation.
+const overheadRuns = 10000
+
+// updateCost caches the estimated cost of a single measurement using
+// a Data with the default configuration.
+var updateCost struct {
+	sync.Mutex
+	cost time.Duration
+}
+
+// UpdateCost returns an estimate of the time spent inside the
+// instrumentation for each measurement made with TimeIt on a Data
+// with the default configuration, including reading the WallClock
+// and updating the statistics.  The estimate is made by timing a
+// large number of measurements of an empty function, and is cached
+// once made.  While instrumentation is disabled (see Enable),
+// nothing is measured, so 0 is returned and nothing is cached.
+func UpdateCost() time.Duration {
+	updateCost.Lock()
+	defer updateCost.Unlock()
+
+	if updateCost.cost == 0 {
+		updateCost.cost = measureCost(&Data{})
+	}
+
+	return updateCost.cost
+}
+
+// UpdateCost is similar to the UpdateCost function, but estimates the
+// cost of a measurement using the Data's configuration: its Clock,
+// InvalidPolicy, Limit, retention, Extremes, and Thresholds all
+// contribute to the cost.  The Filter and Next are not included.  The
+// estimate is cached in the Data the first time it is made while
+// instrumentation is enabled.
+func (d *Data) UpdateCost() time.Duration {
+	if d.cost == 0 {
+		d.cost = measureCost(&Data{
+			Clock:      d.Clock,
+			Invalid:    d.Invalid,
+			Limit:      d.Limit,
+			Retain:     d.Retain,
+			Extremes:   d.Extremes,
+			Maxima:     d.Maxima,
+			Thresholds: d.Thresholds,
+		})
+	}
+
+	return d.cost
+}
+
+// measureCost times a large number of measurements of an empty
+// function using the specified Data, returning the mean time taken
+// by each, or 0 if instrumentation is disabled.  The time is read
+// from the system clock, since the Data's Clock may not measure real
+// time.
+func measureCost(d *Data) time.Duration {
+	if !Enabled() {
+		return 0
+	}
+
+	start := time.Now()
+	for i := 0; i < overheadRuns; i++ {
+		d.TimeIt(func() {})
+	}
+	cost := time.Since(start) / overheadRuns
+	if cost <= 0 {
+		cost = 1
+	}
+
+	return cost
+}
+
+// Overhead returns an estimate of the total time spent inside the
+// instrumentation while collecting the Data: the Data's UpdateCost
+// multiplied by the number of samples.
+func (d *Data) Overhead() time.Duration {
+	overhead, _ := mulSat(d.UpdateCost(), time.Duration(d.Samples))
+
+	return overhead
+}
+
+// OverheadFraction returns the Overhead as a fraction of the total
+// measured time, so users can see when measurement overhead is
+// becoming a material fraction of the measured work.  Returns 0 if
+// no time has been measured.
+func (d *Data) OverheadFraction() float64 {
+	total := float64(d.Mean) * float64(d.Samples)
+	if total <= 0 {
+		return 0
+	}
+
+	return float64(d.Overhead()) / total
+}
+
+// WriteOverhead writes a table showing the estimated instrumentation
+// overhead of each Data in the dataset to the specified writer,
+// using the default NumberFormat.
+func WriteOverhead(w io.Writer, dataset map[string]*Data) error {
+	return (&NumberFormat{}).WriteOverhead(w, dataset)
+}
+
+// WriteOverhead is similar to the WriteOverhead function, but formats
+// the table using the NumberFormat.
+func (nf *NumberFormat) WriteOverhead(w io.Writer, dataset map[string]*Data) error {
+	names := make([]string, 0, len(dataset))
+	for name := range dataset {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rows := [][]string{{"TIMER", "SAMPLES", "MEAN", "OVERHEAD", "FRACTION"}}
+	for _, name := range names {
+		d := dataset[name]
+		fraction := nf.number(strconv.FormatFloat(100*d.OverheadFraction(), 'f', 1, 64)) + "%"
+		rows = append(rows, []string{name, nf.Int(d.Samples), nf.Duration(d.Mean), nf.Duration(d.Overhead()), fraction})
+	}
+
+	return nf.writeTable(w, rows, nil)
+}
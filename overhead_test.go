@@ -0,0 +1,135 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build !timeitnoop
+// +build !timeitnoop
+
+package timeit
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateCostBase(t *testing.T) {
+	first := UpdateCost()
+	second := UpdateCost()
+
+	assert.Greater(t, first, time.Duration(0))
+	assert.Equal(t, first, second)
+}
+
+func TestUpdateCostDisabled(t *testing.T) {
+	defer func(cost time.Duration) { updateCost.cost = cost }(UpdateCost())
+	updateCost.cost = 0
+	defer Enable(true)
+	Enable(false)
+
+	result := UpdateCost()
+
+	assert.Equal(t, time.Duration(0), result)
+	assert.Equal(t, time.Duration(0), updateCost.cost)
+}
+
+func TestDataUpdateCostBase(t *testing.T) {
+	d := &Data{Retain: 10, Extremes: true, Thresholds: []time.Duration{time.Second}}
+
+	result := d.UpdateCost()
+
+	assert.Greater(t, result, time.Duration(0))
+	assert.Equal(t, result, d.cost)
+	assert.Equal(t, int64(0), d.Samples)
+}
+
+func TestDataUpdateCostCached(t *testing.T) {
+	d := &Data{cost: 50}
+
+	result := d.UpdateCost()
+
+	assert.Equal(t, time.Duration(50), result)
+}
+
+func TestDataUpdateCostClock(t *testing.T) {
+	clock := &FakeClock{Step: time.Hour}
+	d := &Data{Clock: clock}
+
+	result := d.UpdateCost()
+
+	assert.Greater(t, result, time.Duration(0))
+	assert.Less(t, result, time.Hour)
+	assert.NotEqual(t, time.Time{}, clock.Time)
+}
+
+func TestDataUpdateCostDisabled(t *testing.T) {
+	defer Enable(true)
+	Enable(false)
+	d := &Data{}
+
+	result := d.UpdateCost()
+
+	assert.Equal(t, time.Duration(0), result)
+	assert.Equal(t, time.Duration(0), d.cost)
+}
+
+func TestDataOverheadBase(t *testing.T) {
+	d := &Data{Samples: 10, cost: 50}
+
+	result := d.Overhead()
+
+	assert.Equal(t, time.Duration(500), result)
+}
+
+func TestDataOverheadSaturate(t *testing.T) {
+	d := &Data{Samples: math.MaxInt64}
+
+	result := d.Overhead()
+
+	assert.Equal(t, time.Duration(math.MaxInt64), result)
+}
+
+func TestDataOverheadFractionBase(t *testing.T) {
+	d := &Data{Samples: 10, Mean: time.Millisecond}
+
+	result := d.OverheadFraction()
+
+	assert.InDelta(t, float64(d.UpdateCost())/float64(time.Millisecond), result, 1e-12)
+}
+
+func TestDataOverheadFractionEmpty(t *testing.T) {
+	d := &Data{}
+
+	result := d.OverheadFraction()
+
+	assert.Equal(t, 0.0, result)
+}
+
+func TestWriteOverhead(t *testing.T) {
+	buf := &bytes.Buffer{}
+	dataset := map[string]*Data{
+		"b": {Samples: 10, Mean: 1000, cost: 50},
+		"a": {Samples: 1000, Mean: 1000000, cost: 50},
+	}
+
+	err := WriteOverhead(buf, dataset)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `TIMER  SAMPLES  MEAN  OVERHEAD  FRACTION
+a      1000     1ms   50µs      0.0%
+b      10       1µs   500ns     5.0%
+`, buf.String())
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultPeriodicInterval is the interval between runs of a Periodic
+// with no Interval or Schedule.
+const DefaultPeriodicInterval = time.Minute
+
+// ScheduleFunc is a function that returns the time of the next run
+// scheduled after the specified time, allowing a Periodic to follow
+// an irregular, cron-like schedule, e.g., "every weekday at 02:00".
+// The returned time must be after the specified time.
+type ScheduleFunc func(t time.Time) time.Time
+
+// Periodic runs a function on a schedule, recording the duration of
+// each run.  By default, runs are scheduled at multiples of the
+// interval from the time Run is called; if a Schedule is set, runs
+// are instead scheduled at the times it returns.  Parsing cron
+// expressions is out of scope; a ScheduleFunc wrapping a cron
+// library may be used for that.  A run that is still executing when
+// the next run after its start is due is counted as an overrun, and
+// any scheduled runs that were missed while it was executing are
+// counted as skipped; the next run is then scheduled for the first
+// scheduled time after it completes.  The overrun and skipped counts
+// are also added to the Data, so that job health is reported along
+// with its latency.
+type Periodic struct {
+	Interval time.Duration // Interval between runs; 0 for default
+	Schedule ScheduleFunc  // Schedule of runs; nil to use the Interval
+	Data     *Data         // Data to update with run durations; may be nil
+	Clock    Clock         // Source of time; nil for WallClock
+	overruns int64
+	skipped  int64
+}
+
+// clock returns the Clock to use for the Periodic.
+func (p *Periodic) clock() Clock {
+	if p.Clock == nil {
+		return WallClock
+	}
+
+	return p.Clock
+}
+
+// interval returns the interval between runs.
+func (p *Periodic) interval() time.Duration {
+	if p.Interval <= 0 {
+		return DefaultPeriodicInterval
+	}
+
+	return p.Interval
+}
+
+// next returns the time of the next run scheduled after the
+// specified time.  An error wrapping ErrBadSchedule is returned if
+// the Schedule does not return a later time.
+func (p *Periodic) next(t time.Time) (time.Time, error) {
+	if p.Schedule == nil {
+		return t.Add(p.interval()), nil
+	}
+
+	next := p.Schedule(t)
+	if !next.After(t) {
+		return next, fmt.Errorf("%w: next run at %s is not after %s", ErrBadSchedule, next.Format(time.RFC3339Nano), t.Format(time.RFC3339Nano))
+	}
+
+	return next, nil
+}
+
+// Overruns returns the number of runs that were still executing when
+// the next run was due.  It is safe to call while Run is executing.
+func (p *Periodic) Overruns() int64 {
+	return atomic.LoadInt64(&p.overruns)
+}
+
+// Skipped returns the number of runs that were skipped because a
+// prior run was still executing.  It is safe to call while Run is
+// executing.
+func (p *Periodic) Skipped() int64 {
+	return atomic.LoadInt64(&p.skipped)
+}
+
+// countRuns adds to the counts of overrunning and skipped runs, both
+// those of the Periodic and those of its Data.
+func (p *Periodic) countRuns(overruns, skipped int64) {
+	atomic.AddInt64(&p.overruns, overruns)
+	atomic.AddInt64(&p.skipped, skipped)
+	if p.Data != nil && Enabled() && !p.Data.checkFrozen() {
+		p.Data.Overruns += overruns
+		p.Data.Skipped += skipped
+	}
+}
+
+// runOnce executes the function once, updating the Data with its
+// duration.  It returns the time the run completed.
+func (p *Periodic) runOnce(start time.Time, fn func()) time.Time {
+	fn()
+	end := p.clock().Now()
+	if p.Data != nil {
+		p.Data.Update(skewed(end.Sub(start)))
+	}
+
+	return end
+}
+
+// following computes the time of the next run after a run scheduled
+// at the specified time, which began and ended at the specified
+// times, updating the counts of overrunning and skipped runs.
+func (p *Periodic) following(scheduled, begin, end time.Time) (time.Time, error) {
+	// The run overran if it had not completed when the next run
+	// after it began was due
+	overruns := int64(0)
+	due, err := p.next(begin)
+	if err != nil {
+		return due, err
+	}
+	if end.After(due) {
+		overruns = 1
+	}
+
+	// Skip any runs scheduled before the run completed
+	skipped := int64(0)
+	var next time.Time
+	if p.Schedule == nil {
+		// Compute the skipped intervals directly
+		interval := p.interval()
+		intervals := int64(end.Sub(scheduled)/interval) + 1
+		if intervals < 1 {
+			intervals = 1
+		}
+		skipped = intervals - 1
+		next = scheduled.Add(time.Duration(intervals) * interval)
+	} else {
+		next, err = p.next(scheduled)
+		for err == nil && !next.After(end) {
+			skipped++
+			next, err = p.next(next)
+		}
+		if err != nil {
+			return next, err
+		}
+	}
+	p.countRuns(overruns, skipped)
+
+	return next, nil
+}
+
+// Run runs the function on the configured schedule until the context
+// is canceled, then returns the context's error.  When using the
+// Interval, the first run occurs immediately; when using a Schedule,
+// it occurs at the first scheduled time.  An error wrapping
+// ErrBadSchedule is returned if the Schedule does not return a time
+// after the one it is passed.
+func (p *Periodic) Run(ctx context.Context, fn func()) error {
+	clock := p.clock()
+	next := clock.Now()
+	delay := time.Duration(0)
+	if p.Schedule != nil {
+		var err error
+		if next, err = p.next(next); err != nil {
+			return err
+		}
+		delay = next.Sub(clock.Now())
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-timer.C:
+			// Don't run if the context was also canceled
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+		}
+
+		// Perform the run and compute the next scheduled time
+		scheduled := next
+		begin := clock.Now()
+		end := p.runOnce(begin, fn)
+		var err error
+		if next, err = p.following(scheduled, begin, end); err != nil {
+			return err
+		}
+		timer.Reset(next.Sub(clock.Now()))
+	}
+}
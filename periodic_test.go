@@ -0,0 +1,367 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build !timeitnoop
+// +build !timeitnoop
+
+package timeit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testSchedule(t time.Time) time.Time {
+	return t.Truncate(10 * time.Second).Add(10 * time.Second)
+}
+
+func TestPeriodicCounters(t *testing.T) {
+	p := &Periodic{
+		overruns: 3,
+		skipped:  5,
+	}
+
+	assert.Equal(t, int64(3), p.Overruns())
+	assert.Equal(t, int64(5), p.Skipped())
+}
+
+func TestPeriodicClockDefault(t *testing.T) {
+	p := &Periodic{}
+
+	assert.Equal(t, WallClock, p.clock())
+}
+
+func TestPeriodicClockSet(t *testing.T) {
+	clock := &testClock{}
+	p := &Periodic{Clock: clock}
+
+	assert.Same(t, clock, p.clock())
+}
+
+func TestPeriodicIntervalDefault(t *testing.T) {
+	p := &Periodic{}
+
+	assert.Equal(t, DefaultPeriodicInterval, p.interval())
+}
+
+func TestPeriodicIntervalSet(t *testing.T) {
+	p := &Periodic{Interval: time.Second}
+
+	assert.Equal(t, time.Second, p.interval())
+}
+
+func TestPeriodicNextInterval(t *testing.T) {
+	p := &Periodic{Interval: 10 * time.Second}
+
+	result, err := p.next(time.Unix(1000, 0))
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1010, 0), result)
+}
+
+func TestPeriodicNextSchedule(t *testing.T) {
+	p := &Periodic{
+		Interval: time.Second,
+		Schedule: testSchedule,
+	}
+
+	result, err := p.next(time.Unix(1005, 0))
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1010, 0), result)
+}
+
+func TestPeriodicNextBadSchedule(t *testing.T) {
+	p := &Periodic{
+		Schedule: func(t time.Time) time.Time { return t },
+	}
+
+	_, err := p.next(time.Unix(1000, 0).UTC())
+
+	assert.ErrorIs(t, err, ErrBadSchedule)
+	assert.EqualError(t, err, "invalid schedule: next run at 1970-01-01T00:16:40Z is not after 1970-01-01T00:16:40Z")
+}
+
+func TestPeriodicCountRunsBase(t *testing.T) {
+	p := &Periodic{
+		overruns: 1,
+		skipped:  2,
+		Data:     &Data{Overruns: 3, Skipped: 4},
+	}
+
+	p.countRuns(1, 5)
+
+	assert.Equal(t, int64(2), p.Overruns())
+	assert.Equal(t, int64(7), p.Skipped())
+	assert.Equal(t, &Data{Overruns: 4, Skipped: 9}, p.Data)
+}
+
+func TestPeriodicCountRunsNoData(t *testing.T) {
+	p := &Periodic{}
+
+	p.countRuns(1, 5)
+
+	assert.Equal(t, int64(1), p.Overruns())
+	assert.Equal(t, int64(5), p.Skipped())
+}
+
+func TestPeriodicCountRunsFrozen(t *testing.T) {
+	if debugBuild {
+		t.Skip("countRuns panics in debug builds")
+	}
+	p := &Periodic{
+		Data: &Data{frozen: true},
+	}
+
+	p.countRuns(1, 5)
+
+	assert.Equal(t, int64(1), p.Overruns())
+	assert.Equal(t, &Data{frozen: true}, p.Data)
+}
+
+func TestPeriodicRunOnceBase(t *testing.T) {
+	p := &Periodic{
+		Interval: time.Hour,
+		Data:     &Data{},
+	}
+	called := false
+
+	result := p.runOnce(time.Now(), func() { called = true })
+
+	assert.True(t, called)
+	assert.False(t, result.IsZero())
+	assert.Equal(t, int64(1), p.Data.Samples)
+}
+
+func TestPeriodicFollowingBase(t *testing.T) {
+	p := &Periodic{Interval: 10 * time.Second}
+
+	result, err := p.following(time.Unix(1000, 0), time.Unix(1001, 0), time.Unix(1005, 0))
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1010, 0), result)
+	assert.Equal(t, int64(0), p.Overruns())
+	assert.Equal(t, int64(0), p.Skipped())
+}
+
+func TestPeriodicFollowingOverrun(t *testing.T) {
+	p := &Periodic{Interval: 10 * time.Second}
+
+	result, err := p.following(time.Unix(1000, 0), time.Unix(1001, 0), time.Unix(1025, 0))
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1030, 0), result)
+	assert.Equal(t, int64(1), p.Overruns())
+	assert.Equal(t, int64(2), p.Skipped())
+}
+
+func TestPeriodicFollowingLateStart(t *testing.T) {
+	p := &Periodic{Interval: 10 * time.Second}
+
+	result, err := p.following(time.Unix(1000, 0), time.Unix(1008, 0), time.Unix(1012, 0))
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1020, 0), result)
+	assert.Equal(t, int64(0), p.Overruns())
+	assert.Equal(t, int64(1), p.Skipped())
+}
+
+func TestPeriodicFollowingSchedule(t *testing.T) {
+	p := &Periodic{Schedule: testSchedule}
+
+	result, err := p.following(time.Unix(1000, 0), time.Unix(1001, 0), time.Unix(1005, 0))
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1010, 0), result)
+	assert.Equal(t, int64(0), p.Overruns())
+	assert.Equal(t, int64(0), p.Skipped())
+}
+
+func TestPeriodicFollowingScheduleOverrun(t *testing.T) {
+	p := &Periodic{Schedule: testSchedule}
+
+	result, err := p.following(time.Unix(1000, 0), time.Unix(1001, 0), time.Unix(1030, 0))
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1040, 0), result)
+	assert.Equal(t, int64(1), p.Overruns())
+	assert.Equal(t, int64(3), p.Skipped())
+}
+
+func TestPeriodicFollowingBadSchedule(t *testing.T) {
+	p := &Periodic{
+		Schedule: func(t time.Time) time.Time { return t },
+	}
+
+	_, err := p.following(time.Unix(1000, 0), time.Unix(1001, 0), time.Unix(1005, 0))
+
+	assert.ErrorIs(t, err, ErrBadSchedule)
+	assert.Equal(t, int64(0), p.Overruns())
+}
+
+func TestPeriodicFollowingBadScheduleSkipped(t *testing.T) {
+	calls := 0
+	p := &Periodic{
+		Schedule: func(t time.Time) time.Time {
+			calls++
+			if calls > 2 {
+				return t
+			}
+			return testSchedule(t)
+		},
+	}
+
+	_, err := p.following(time.Unix(1000, 0), time.Unix(1001, 0), time.Unix(1030, 0))
+
+	assert.ErrorIs(t, err, ErrBadSchedule)
+	assert.Equal(t, int64(0), p.Skipped())
+}
+
+func TestPeriodicRunBase(t *testing.T) {
+	p := &Periodic{
+		Interval: time.Millisecond,
+		Data:     &Data{},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	count := 0
+
+	err := p.Run(ctx, func() {
+		count++
+		if count >= 3 {
+			cancel()
+		}
+	})
+
+	assert.Same(t, context.Canceled, err)
+	assert.Equal(t, 3, count)
+	assert.Equal(t, int64(3), p.Data.Samples)
+}
+
+func TestPeriodicRunSkipped(t *testing.T) {
+	p := &Periodic{
+		Interval: 10 * time.Millisecond,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := p.Run(ctx, func() {
+		time.Sleep(35 * time.Millisecond)
+		cancel()
+	})
+
+	assert.Same(t, context.Canceled, err)
+	assert.Equal(t, int64(1), p.Overruns())
+	assert.GreaterOrEqual(t, p.Skipped(), int64(3))
+}
+
+func TestPeriodicRunClock(t *testing.T) {
+	p := &Periodic{
+		Interval: 10 * time.Second,
+		Data:     &Data{},
+		Clock:    &FakeClock{Time: time.Unix(1000, 0), Step: 25 * time.Second},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := p.Run(ctx, cancel)
+
+	assert.Same(t, context.Canceled, err)
+	assert.Equal(t, 25*time.Second, p.Data.Mean)
+	assert.Equal(t, int64(1), p.Overruns())
+	assert.Equal(t, int64(5), p.Skipped())
+	assert.Equal(t, int64(1), p.Data.Overruns)
+	assert.Equal(t, int64(5), p.Data.Skipped)
+}
+
+func TestPeriodicRunSchedule(t *testing.T) {
+	p := &Periodic{
+		Schedule: testSchedule,
+		Data:     &Data{},
+		Clock:    &FakeClock{Time: time.Unix(1000, 0), Step: 25 * time.Second},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := p.Run(ctx, cancel)
+
+	assert.Same(t, context.Canceled, err)
+	assert.Equal(t, 25*time.Second, p.Data.Mean)
+	assert.Equal(t, int64(1), p.Data.Overruns)
+	assert.Equal(t, int64(6), p.Data.Skipped)
+}
+
+func TestPeriodicRunBadSchedule(t *testing.T) {
+	p := &Periodic{
+		Schedule: func(t time.Time) time.Time { return t },
+	}
+	called := false
+
+	err := p.Run(context.Background(), func() { called = true })
+
+	assert.ErrorIs(t, err, ErrBadSchedule)
+	assert.False(t, called)
+}
+
+func TestPeriodicRunBadScheduleLater(t *testing.T) {
+	calls := 0
+	p := &Periodic{
+		Schedule: func(t time.Time) time.Time {
+			calls++
+			if calls > 1 {
+				return t
+			}
+			return testSchedule(t)
+		},
+		Clock: &FakeClock{Time: time.Unix(1000, 0), Step: 25 * time.Second},
+	}
+	count := 0
+
+	err := p.Run(context.Background(), func() { count++ })
+
+	assert.ErrorIs(t, err, ErrBadSchedule)
+	assert.Equal(t, 1, count)
+}
+
+func TestPeriodicRunZeroInterval(t *testing.T) {
+	p := &Periodic{
+		Clock: &FakeClock{Time: time.Unix(1000, 0), Step: 30 * time.Second},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := p.Run(ctx, cancel)
+
+	assert.Same(t, context.Canceled, err)
+	assert.Equal(t, int64(0), p.Overruns())
+	assert.Equal(t, int64(1), p.Skipped())
+}
+
+func TestPeriodicRunSkewed(t *testing.T) {
+	p := &Periodic{
+		Interval: time.Second,
+		Clock:    &FakeClock{Time: time.Unix(1000, 0), Step: -10 * time.Second},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := p.Run(ctx, cancel)
+
+	assert.Same(t, context.Canceled, err)
+	assert.Equal(t, int64(0), p.Overruns())
+	assert.Equal(t, int64(0), p.Skipped())
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"sort"
+	"time"
+)
+
+// Default settings for PeriodAnalyzer.
+const (
+	DefaultPeriodResolution = time.Second // Default width of time bins
+	DefaultPeriodThreshold  = 0.3         // Default correlation threshold
+	DefaultPeriodMaxBins    = 10000       // Default limit on the number of time bins
+)
+
+// Periodicity describes a recurring pattern found in a series of
+// samples.
+type Periodicity struct {
+	Period      time.Duration // The period of the recurrence
+	Correlation float64       // Autocorrelation at that period
+}
+
+// PeriodAnalyzer looks for recurring patterns in the latency of
+// timestamped samples, such as those returned by Data.Retained.  The
+// samples are grouped into time bins of the configured resolution,
+// and the autocorrelation of the mean latency of each bin is computed
+// over a range of lags; peaks in the autocorrelation indicate
+// periodic behavior, such as that caused by cron jobs, garbage
+// collection cycles, or noisy neighbors.
+//
+// Computing the autocorrelation takes time proportional to the number
+// of bins multiplied by the number of lags, and the default MaxLag is
+// half the span, so the cost grows with the square of the span.  To
+// bound it, the number of bins is limited to MaxBins; if the samples
+// span more bins than that, the bins are widened to fit, and lags and
+// periods are multiples of the widened bins.  With the default limit,
+// the autocorrelation takes at most about 50 million multiplications.
+type PeriodAnalyzer struct {
+	Resolution time.Duration // Width of time bins; 0 for default
+	MaxLag     time.Duration // Largest lag to consider; 0 for half the span
+	Threshold  float64       // Minimum correlation to report; 0 for default
+	MaxBins    int           // Largest number of time bins; 0 for default
+}
+
+// resolution returns the width of the time bins.
+func (pa *PeriodAnalyzer) resolution() time.Duration {
+	if pa.Resolution <= 0 {
+		return DefaultPeriodResolution
+	}
+
+	return pa.Resolution
+}
+
+// binWidth returns the width of the time bins for samples covering
+// the specified span: the resolution, widened if necessary to the
+// smallest multiple of the resolution for which the number of bins
+// does not exceed MaxBins.
+func (pa *PeriodAnalyzer) binWidth(span time.Duration) time.Duration {
+	maxBins := pa.MaxBins
+	if maxBins <= 0 {
+		maxBins = DefaultPeriodMaxBins
+	} else if maxBins < 2 {
+		maxBins = 2
+	}
+
+	res := pa.resolution()
+	n := span / res
+	if n < time.Duration(maxBins) {
+		return res
+	}
+
+	// Round up so that there are at most maxBins-1 whole bins after
+	// the first
+	return (n + time.Duration(maxBins-2)) / time.Duration(maxBins-1) * res
+}
+
+// threshold returns the correlation threshold.
+func (pa *PeriodAnalyzer) threshold() float64 {
+	if pa.Threshold <= 0 {
+		return DefaultPeriodThreshold
+	}
+
+	return pa.Threshold
+}
+
+// series groups the samples into time bins, returning the deviation
+// of the mean latency of each bin from the overall mean, along with
+// the width of the bins (see binWidth).  Empty bins are treated as
+// having the overall mean latency.
+func (pa *PeriodAnalyzer) series(samples []Sample) ([]float64, time.Duration) {
+	if len(samples) == 0 {
+		return nil, pa.resolution()
+	}
+
+	// Find the time span covered by the samples
+	start, end := samples[0].At, samples[0].At
+	for _, s := range samples[1:] {
+		if s.At.Before(start) {
+			start = s.At
+		}
+		if s.At.After(end) {
+			end = s.At
+		}
+	}
+
+	// Accumulate the samples into bins
+	res := pa.binWidth(end.Sub(start))
+	sums := make([]float64, int(end.Sub(start)/res)+1)
+	counts := make([]int, len(sums))
+	total := 0.0
+	for _, s := range samples {
+		i := int(s.At.Sub(start) / res)
+		sums[i] += float64(s.Duration)
+		counts[i]++
+		total += float64(s.Duration)
+	}
+
+	// Compute the deviation of each bin from the mean
+	mean := total / float64(len(samples))
+	for i := range sums {
+		if counts[i] == 0 {
+			sums[i] = 0
+			continue
+		}
+		sums[i] = sums[i]/float64(counts[i]) - mean
+	}
+
+	return sums, res
+}
+
+// Autocorrelation computes the autocorrelation of the latency of the
+// samples.  Element k of the result is the autocorrelation at a lag
+// of k times the resolution, widened if necessary (see MaxBins);
+// element 0 is always 1.  Returns nil if the samples span fewer than
+// two bins or show no variation.
+func (pa *PeriodAnalyzer) Autocorrelation(samples []Sample) []float64 {
+	result, _ := pa.autocorrelation(samples)
+
+	return result
+}
+
+// autocorrelation computes the autocorrelation of the latency of the
+// samples, returning it along with the width of the time bins.
+func (pa *PeriodAnalyzer) autocorrelation(samples []Sample) ([]float64, time.Duration) {
+	x, res := pa.series(samples)
+	if len(x) < 2 {
+		return nil, res
+	}
+
+	// Compute the denominator
+	denom := 0.0
+	for _, v := range x {
+		denom += v * v
+	}
+	if denom == 0 {
+		return nil, res
+	}
+
+	// Determine the largest lag to consider
+	maxLag := len(x) / 2
+	if pa.MaxLag > 0 {
+		maxLag = int(pa.MaxLag / res)
+	}
+	if maxLag > len(x)-1 {
+		maxLag = len(x) - 1
+	}
+
+	// Compute the autocorrelation at each lag
+	result := make([]float64, maxLag+1)
+	for k := range result {
+		sum := 0.0
+		for t := 0; t+k < len(x); t++ {
+			sum += x[t] * x[t+k]
+		}
+		result[k] = sum / denom
+	}
+
+	return result, res
+}
+
+// Periodicities reports the dominant periods in the latency of the
+// samples, strongest first.  A period is reported if the
+// autocorrelation has a local peak at that lag which meets the
+// threshold.
+func (pa *PeriodAnalyzer) Periodicities(samples []Sample) []Periodicity {
+	r, res := pa.autocorrelation(samples)
+
+	// Find the peaks
+	result := []Periodicity{}
+	for k := 1; k < len(r); k++ {
+		if r[k] < pa.threshold() || r[k] <= r[k-1] || (k+1 < len(r) && r[k] < r[k+1]) {
+			continue
+		}
+		result = append(result, Periodicity{
+			Period:      time.Duration(k) * res,
+			Correlation: r[k],
+		})
+	}
+
+	// Sort by strength
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Correlation > result[j].Correlation
+	})
+
+	return result
+}
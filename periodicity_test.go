@@ -0,0 +1,227 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// spikySamples returns a minute of samples, one per second, with a
+// spike every 10 seconds.
+func spikySamples() []Sample {
+	samples := []Sample{}
+	for i := 0; i < 60; i++ {
+		sample := time.Duration(100)
+		if i%10 == 0 {
+			sample = 1000
+		}
+		samples = append(samples, Sample{
+			At:       time.Unix(int64(1000+i), 0),
+			Duration: sample,
+		})
+	}
+
+	return samples
+}
+
+func TestPeriodAnalyzerResolutionDefault(t *testing.T) {
+	pa := &PeriodAnalyzer{}
+
+	result := pa.resolution()
+
+	assert.Equal(t, DefaultPeriodResolution, result)
+}
+
+func TestPeriodAnalyzerResolutionSet(t *testing.T) {
+	pa := &PeriodAnalyzer{Resolution: time.Minute}
+
+	result := pa.resolution()
+
+	assert.Equal(t, time.Minute, result)
+}
+
+func TestPeriodAnalyzerThresholdDefault(t *testing.T) {
+	pa := &PeriodAnalyzer{}
+
+	result := pa.threshold()
+
+	assert.Equal(t, DefaultPeriodThreshold, result)
+}
+
+func TestPeriodAnalyzerThresholdSet(t *testing.T) {
+	pa := &PeriodAnalyzer{Threshold: 0.9}
+
+	result := pa.threshold()
+
+	assert.Equal(t, 0.9, result)
+}
+
+func TestPeriodAnalyzerSeriesEmpty(t *testing.T) {
+	pa := &PeriodAnalyzer{}
+
+	result, res := pa.series(nil)
+
+	assert.Nil(t, result)
+	assert.Equal(t, DefaultPeriodResolution, res)
+}
+
+func TestPeriodAnalyzerSeriesBins(t *testing.T) {
+	pa := &PeriodAnalyzer{}
+
+	result, res := pa.series([]Sample{
+		{At: time.Unix(1003, 0), Duration: 40},
+		{At: time.Unix(1000, 0), Duration: 10},
+		{At: time.Unix(1000, 500000000), Duration: 30},
+		{At: time.Unix(1001, 0), Duration: 20},
+	})
+
+	assert.Equal(t, []float64{-5, -5, 0, 15}, result)
+	assert.Equal(t, time.Second, res)
+}
+
+func TestPeriodAnalyzerSeriesMaxBins(t *testing.T) {
+	pa := &PeriodAnalyzer{MaxBins: 3}
+
+	result, res := pa.series([]Sample{
+		{At: time.Unix(1003, 0), Duration: 40},
+		{At: time.Unix(1000, 0), Duration: 10},
+		{At: time.Unix(1000, 500000000), Duration: 30},
+		{At: time.Unix(1001, 0), Duration: 20},
+	})
+
+	assert.Equal(t, []float64{-5, 15}, result)
+	assert.Equal(t, 2*time.Second, res)
+}
+
+func TestPeriodAnalyzerBinWidthBase(t *testing.T) {
+	pa := &PeriodAnalyzer{}
+
+	result := pa.binWidth(time.Hour)
+
+	assert.Equal(t, time.Second, result)
+}
+
+func TestPeriodAnalyzerBinWidthWidened(t *testing.T) {
+	pa := &PeriodAnalyzer{}
+
+	result := pa.binWidth(30 * 24 * time.Hour)
+
+	assert.Equal(t, 260*time.Second, result)
+	assert.LessOrEqual(t, int(30*24*time.Hour/result)+1, DefaultPeriodMaxBins)
+}
+
+func TestPeriodAnalyzerBinWidthTooSmall(t *testing.T) {
+	pa := &PeriodAnalyzer{MaxBins: 1}
+
+	result := pa.binWidth(10 * time.Second)
+
+	assert.Equal(t, 10*time.Second, result)
+}
+
+func TestPeriodAnalyzerAutocorrelationShort(t *testing.T) {
+	pa := &PeriodAnalyzer{}
+
+	result := pa.Autocorrelation([]Sample{
+		{At: time.Unix(1000, 0), Duration: 10},
+		{At: time.Unix(1000, 500000000), Duration: 30},
+	})
+
+	assert.Nil(t, result)
+}
+
+func TestPeriodAnalyzerAutocorrelationFlat(t *testing.T) {
+	pa := &PeriodAnalyzer{}
+
+	result := pa.Autocorrelation([]Sample{
+		{At: time.Unix(1000, 0), Duration: 10},
+		{At: time.Unix(1001, 0), Duration: 10},
+		{At: time.Unix(1002, 0), Duration: 10},
+	})
+
+	assert.Nil(t, result)
+}
+
+func TestPeriodAnalyzerAutocorrelationBase(t *testing.T) {
+	pa := &PeriodAnalyzer{}
+
+	result := pa.Autocorrelation(spikySamples())
+
+	assert.Len(t, result, 31)
+	assert.Equal(t, 1.0, result[0])
+	assert.InDelta(t, 0.8333, result[10], 0.0001)
+	assert.InDelta(t, 0.6667, result[20], 0.0001)
+	assert.InDelta(t, 0.5, result[30], 0.0001)
+	assert.Less(t, result[5], 0.0)
+}
+
+func TestPeriodAnalyzerAutocorrelationMaxLag(t *testing.T) {
+	pa := &PeriodAnalyzer{MaxLag: 15 * time.Second}
+
+	result := pa.Autocorrelation(spikySamples())
+
+	assert.Len(t, result, 16)
+}
+
+func TestPeriodAnalyzerAutocorrelationMaxLagClamped(t *testing.T) {
+	pa := &PeriodAnalyzer{MaxLag: time.Hour}
+
+	result := pa.Autocorrelation(spikySamples())
+
+	assert.Len(t, result, 60)
+}
+
+func TestPeriodAnalyzerPeriodicitiesBase(t *testing.T) {
+	pa := &PeriodAnalyzer{}
+
+	result := pa.Periodicities(spikySamples())
+
+	assert.Len(t, result, 3)
+	assert.Equal(t, 10*time.Second, result[0].Period)
+	assert.InDelta(t, 0.8333, result[0].Correlation, 0.0001)
+	assert.Equal(t, 20*time.Second, result[1].Period)
+	assert.Equal(t, 30*time.Second, result[2].Period)
+}
+
+func TestPeriodAnalyzerPeriodicitiesMaxBins(t *testing.T) {
+	pa := &PeriodAnalyzer{MaxBins: 30}
+
+	result := pa.Periodicities(spikySamples())
+
+	// The bins are widened to 3 seconds, so the 10 second period
+	// shows up at 9 seconds
+	assert.Len(t, result, 2)
+	assert.Equal(t, 9*time.Second, result[0].Period)
+	assert.Equal(t, 30*time.Second, result[1].Period)
+}
+
+func TestPeriodAnalyzerPeriodicitiesThreshold(t *testing.T) {
+	pa := &PeriodAnalyzer{Threshold: 0.7}
+
+	result := pa.Periodicities(spikySamples())
+
+	assert.Len(t, result, 1)
+	assert.Equal(t, 10*time.Second, result[0].Period)
+}
+
+func TestPeriodAnalyzerPeriodicitiesNone(t *testing.T) {
+	pa := &PeriodAnalyzer{}
+
+	result := pa.Periodicities(nil)
+
+	assert.Equal(t, []Periodicity{}, result)
+}
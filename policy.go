@@ -0,0 +1,190 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// FailurePolicy describes how a PolicySink handles a failure to emit
+// a snapshot.
+type FailurePolicy int
+
+// Recognized failure policies.
+const (
+	DropOnFailure   FailurePolicy = iota // Drop the snapshot
+	BufferOnFailure                      // Buffer the snapshot for retry
+	BlockOnFailure                       // Retry until the emit succeeds
+)
+
+// DefaultRetryDelay is the default delay between retries for the
+// BlockOnFailure policy.
+const DefaultRetryDelay = time.Second
+
+// PolicySink wraps a Sink, typically one that emits over the
+// network, and applies a FailurePolicy when the sink fails.  With
+// DropOnFailure, failed snapshots are simply counted as dropped.
+// With BufferOnFailure, up to Buffer failed snapshots are kept and
+// emitted, oldest first, before the next snapshot; if the buffer
+// overflows, the oldest snapshots are dropped.  With BlockOnFailure,
+// Emit retries until the sink succeeds, which applies backpressure to
+// the Reporter; EmitContext also gives up when its context is done.
+// Errors and drops are counted so that the telemetry pipeline itself
+// is observable.
+type PolicySink struct {
+	Sink       Sink          // The sink to wrap
+	Policy     FailurePolicy // The failure policy
+	Buffer     int           // Maximum snapshots to buffer; at least 1
+	RetryDelay time.Duration // Delay between retries; 0 for default
+	mu         sync.Mutex
+	pending    []*Snapshot
+	dropped    int64
+	errors     int64
+}
+
+// Dropped returns the number of snapshots that have been dropped.
+func (ps *PolicySink) Dropped() int64 {
+	return atomic.LoadInt64(&ps.dropped)
+}
+
+// Errors returns the number of errors returned by the wrapped sink.
+func (ps *PolicySink) Errors() int64 {
+	return atomic.LoadInt64(&ps.errors)
+}
+
+// emit emits a snapshot to the wrapped sink, counting errors.
+func (ps *PolicySink) emit(snap *Snapshot) error {
+	err := ps.Sink.Emit(snap)
+	if err != nil {
+		atomic.AddInt64(&ps.errors, 1)
+	}
+
+	return err
+}
+
+// emitBuffered buffers the snapshot, then emits buffered snapshots
+// until one fails.
+func (ps *PolicySink) emitBuffered(snap *Snapshot) error {
+	limit := ps.Buffer
+	if limit < 1 {
+		limit = 1
+	}
+	ps.pending = append(ps.pending, snap)
+	if over := len(ps.pending) - limit; over > 0 {
+		atomic.AddInt64(&ps.dropped, int64(over))
+		ps.pending = append(ps.pending[:0], ps.pending[over:]...)
+	}
+
+	for len(ps.pending) > 0 {
+		if err := ps.emit(ps.pending[0]); err != nil {
+			return err
+		}
+		ps.pending[0] = nil
+		ps.pending = ps.pending[1:]
+	}
+
+	return nil
+}
+
+// retryDelay returns the delay between retries.
+func (ps *PolicySink) retryDelay() time.Duration {
+	if ps.RetryDelay <= 0 {
+		return DefaultRetryDelay
+	}
+
+	return ps.RetryDelay
+}
+
+// emitBlocking emits the snapshot, retrying until it succeeds or the
+// context is done.  If the context is done, the snapshot is dropped
+// and the context's error is returned.
+func (ps *PolicySink) emitBlocking(ctx context.Context, snap *Snapshot) error {
+	delay := ps.retryDelay()
+	for ps.emit(snap) != nil {
+		select {
+		case <-ctx.Done():
+			atomic.AddInt64(&ps.dropped, 1)
+			return ctx.Err()
+
+		case <-time.After(delay):
+		}
+	}
+
+	return nil
+}
+
+// Emit emits the snapshot, applying the failure policy.  The error
+// from the wrapped sink is returned, so that it may be reported by
+// the Reporter; with BlockOnFailure, no error is ever returned.
+func (ps *PolicySink) Emit(snap *Snapshot) error {
+	return ps.EmitContext(context.Background(), snap)
+}
+
+// EmitContext is similar to Emit, but with BlockOnFailure, gives up
+// retrying when the context is done, dropping the snapshot and
+// returning the context's error.
+func (ps *PolicySink) EmitContext(ctx context.Context, snap *Snapshot) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	switch ps.Policy {
+	case BufferOnFailure:
+		return ps.emitBuffered(snap)
+
+	case BlockOnFailure:
+		return ps.emitBlocking(ctx, snap)
+
+	case DropOnFailure:
+	}
+
+	err := ps.emit(snap)
+	if err != nil {
+		atomic.AddInt64(&ps.dropped, 1)
+	}
+
+	return err
+}
+
+// Flush emits any snapshots buffered by the BufferOnFailure policy,
+// oldest first, retrying failed snapshots after the retry delay.  If
+// the context is done before all the buffered snapshots are emitted,
+// the remainder are dropped and the context's error is returned.
+func (ps *PolicySink) Flush(ctx context.Context) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	delay := ps.retryDelay()
+	for len(ps.pending) > 0 {
+		if ps.emit(ps.pending[0]) == nil {
+			ps.pending[0] = nil
+			ps.pending = ps.pending[1:]
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			atomic.AddInt64(&ps.dropped, int64(len(ps.pending)))
+			ps.pending = nil
+			return ctx.Err()
+
+		case <-time.After(delay):
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSink is a Sink that fails a configured number of times before
+// succeeding, and records the snapshots it successfully emits.
+type fakeSink struct {
+	failures int
+	emitted  []*Snapshot
+}
+
+func (fs *fakeSink) Emit(snap *Snapshot) error {
+	if fs.failures > 0 {
+		fs.failures--
+		return assert.AnError
+	}
+
+	fs.emitted = append(fs.emitted, snap)
+	return nil
+}
+
+func TestPolicySinkCounters(t *testing.T) {
+	ps := &PolicySink{
+		dropped: 3,
+		errors:  5,
+	}
+
+	assert.Equal(t, int64(3), ps.Dropped())
+	assert.Equal(t, int64(5), ps.Errors())
+}
+
+func TestPolicySinkEmitDropSuccess(t *testing.T) {
+	sink := &fakeSink{}
+	ps := &PolicySink{Sink: sink}
+	snap := &Snapshot{}
+
+	err := ps.Emit(snap)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*Snapshot{snap}, sink.emitted)
+	assert.Equal(t, int64(0), ps.Dropped())
+	assert.Equal(t, int64(0), ps.Errors())
+}
+
+func TestPolicySinkEmitDropFailure(t *testing.T) {
+	sink := &fakeSink{failures: 1}
+	ps := &PolicySink{Sink: sink}
+
+	err := ps.Emit(&Snapshot{})
+
+	assert.Same(t, assert.AnError, err)
+	assert.Nil(t, sink.emitted)
+	assert.Equal(t, int64(1), ps.Dropped())
+	assert.Equal(t, int64(1), ps.Errors())
+}
+
+func TestPolicySinkEmitBuffer(t *testing.T) {
+	sink := &fakeSink{failures: 2}
+	ps := &PolicySink{
+		Sink:   sink,
+		Policy: BufferOnFailure,
+		Buffer: 2,
+	}
+	snap1 := &Snapshot{}
+	snap2 := &Snapshot{}
+	snap3 := &Snapshot{}
+
+	err1 := ps.Emit(snap1)
+	err2 := ps.Emit(snap2)
+	err3 := ps.Emit(snap3)
+
+	assert.Same(t, assert.AnError, err1)
+	assert.Same(t, assert.AnError, err2)
+	assert.NoError(t, err3)
+	assert.Equal(t, []*Snapshot{snap2, snap3}, sink.emitted)
+	assert.Equal(t, int64(1), ps.Dropped())
+	assert.Equal(t, int64(2), ps.Errors())
+	assert.Len(t, ps.pending, 0)
+}
+
+func TestPolicySinkEmitBlock(t *testing.T) {
+	sink := &fakeSink{failures: 2}
+	ps := &PolicySink{
+		Sink:       sink,
+		Policy:     BlockOnFailure,
+		RetryDelay: time.Millisecond,
+	}
+	snap := &Snapshot{}
+
+	err := ps.Emit(snap)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*Snapshot{snap}, sink.emitted)
+	assert.Equal(t, int64(0), ps.Dropped())
+	assert.Equal(t, int64(2), ps.Errors())
+}
+
+func TestPolicySinkEmitBlockDefaultDelay(t *testing.T) {
+	sink := &fakeSink{}
+	ps := &PolicySink{
+		Sink:   sink,
+		Policy: BlockOnFailure,
+	}
+
+	err := ps.Emit(&Snapshot{})
+
+	assert.NoError(t, err)
+	assert.Len(t, sink.emitted, 1)
+}
+
+func TestPolicySinkEmitContextBlockCanceled(t *testing.T) {
+	sink := &fakeSink{failures: 1000}
+	ps := &PolicySink{
+		Sink:       sink,
+		Policy:     BlockOnFailure,
+		RetryDelay: time.Millisecond,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := ps.EmitContext(ctx, &Snapshot{})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, sink.emitted)
+	assert.Equal(t, int64(1), ps.Dropped())
+	assert.Greater(t, ps.Errors(), int64(0))
+}
+
+func TestPolicySinkEmitBufferZero(t *testing.T) {
+	sink := &fakeSink{failures: 1}
+	ps := &PolicySink{
+		Sink:   sink,
+		Policy: BufferOnFailure,
+	}
+	snap1 := &Snapshot{}
+	snap2 := &Snapshot{}
+
+	err1 := ps.Emit(snap1)
+	err2 := ps.Emit(snap2)
+
+	assert.Same(t, assert.AnError, err1)
+	assert.NoError(t, err2)
+	assert.Equal(t, []*Snapshot{snap2}, sink.emitted)
+	assert.Equal(t, int64(1), ps.Dropped())
+}
+
+func TestPolicySinkImplementsFlusher(t *testing.T) {
+	assert.Implements(t, (*Flusher)(nil), &PolicySink{})
+}
+
+func TestPolicySinkFlushBase(t *testing.T) {
+	sink := &fakeSink{failures: 1}
+	snap1 := &Snapshot{}
+	snap2 := &Snapshot{}
+	ps := &PolicySink{
+		Sink:       sink,
+		Policy:     BufferOnFailure,
+		RetryDelay: time.Millisecond,
+		pending:    []*Snapshot{snap1, snap2},
+	}
+
+	err := ps.Flush(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*Snapshot{snap1, snap2}, sink.emitted)
+	assert.Empty(t, ps.pending)
+	assert.Equal(t, int64(0), ps.Dropped())
+	assert.Equal(t, int64(1), ps.Errors())
+}
+
+func TestPolicySinkFlushCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sink := &fakeSink{failures: 1}
+	ps := &PolicySink{
+		Sink:    sink,
+		Policy:  BufferOnFailure,
+		pending: []*Snapshot{{}, {}},
+	}
+
+	err := ps.Flush(ctx)
+
+	assert.Same(t, context.Canceled, err)
+	assert.Empty(t, sink.emitted)
+	assert.Nil(t, ps.pending)
+	assert.Equal(t, int64(2), ps.Dropped())
+	assert.Equal(t, int64(1), ps.Errors())
+}
@@ -0,0 +1,31 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package timeit
+
+import "syscall"
+
+// SetPriority sets the scheduling priority, or "nice" value, used for
+// measurements; lower values indicate a higher priority, and raising
+// the priority generally requires privileges.  On Linux, this only
+// affects the calling thread, so it should be combined with
+// PinThread; on other platforms, it affects the whole process.  An
+// error wrapping ErrUnsupported is returned on platforms that do not
+// support priorities.
+func SetPriority(nice int) error {
+	return syscall.Setpriority(syscall.PRIO_PROCESS, 0, nice)
+}
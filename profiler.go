@@ -0,0 +1,168 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// PathSeparator separates the names of nested regions in the names
+// of the Data reported by a Profiler.
+const PathSeparator = "/"
+
+// goroutinePrefix is the prefix of the first line of a goroutine's
+// stack trace, which is followed by the goroutine's ID.
+var goroutinePrefix = []byte("goroutine ")
+
+// goroutineID returns the ID of the calling goroutine.  The Go
+// runtime deliberately does not expose goroutine IDs, so the ID is
+// parsed from the goroutine's stack trace; this is slow, which is
+// acceptable for exploratory profiling but not for production
+// instrumentation.
+func goroutineID() uint64 {
+	var buf [64]byte
+	line := bytes.TrimPrefix(buf[:runtime.Stack(buf[:], false)], goroutinePrefix)
+	if idx := bytes.IndexByte(line, ' '); idx >= 0 {
+		line = line[:idx]
+	}
+	id, _ := strconv.ParseUint(string(line), 10, 64)
+
+	return id
+}
+
+// profileFrame is a region begun by a goroutine.
+type profileFrame struct {
+	path  string    // Path of the region
+	start time.Time // Time the region began
+}
+
+// Profiler attributes time to named regions, which are begun and
+// ended by each goroutine without threading a Data or context
+// through the calls in between; this is intended for quick
+// exploratory profiling of existing code.  Regions begun while
+// another region is active on the same goroutine are nested within
+// it, and their time is recorded in a Data named by the path of
+// region names, joined by PathSeparator, e.g., "handler/db/query".
+// Goroutines are identified by parsing their stack traces, which is
+// slow; for instrumentation that remains in production code, use
+// TimeItContext instead.  A Profiler is safe for concurrent use.
+type Profiler struct {
+	Clock  Clock // Source of time; nil for WallClock
+	mu     sync.Mutex
+	stacks map[uint64][]profileFrame
+	data   map[string]*Data
+}
+
+// DefaultProfiler is the Profiler used by Begin and End.
+var DefaultProfiler = &Profiler{}
+
+// clock returns the Clock to use for the Profiler.
+func (p *Profiler) clock() Clock {
+	if p.Clock == nil {
+		return WallClock
+	}
+
+	return p.Clock
+}
+
+// Begin begins a region with the specified name on the calling
+// goroutine, nested within the region currently active on the
+// goroutine, if any.  Each call to Begin must be matched by a call to
+// End on the same goroutine.
+func (p *Profiler) Begin(name string) {
+	id := goroutineID()
+	now := p.clock().Now()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stacks == nil {
+		p.stacks = map[uint64][]profileFrame{}
+	}
+	stack := p.stacks[id]
+	path := name
+	if len(stack) > 0 {
+		path = stack[len(stack)-1].path + PathSeparator + name
+	}
+	p.stacks[id] = append(stack, profileFrame{path: path, start: now})
+}
+
+// End ends the region most recently begun on the calling goroutine,
+// recording the time spent in it.  Returns the time spent in the
+// region, or 0 if no region is active on the goroutine.
+func (p *Profiler) End() time.Duration {
+	id := goroutineID()
+	now := p.clock().Now()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stack := p.stacks[id]
+	if len(stack) == 0 {
+		return 0
+	}
+	frame := stack[len(stack)-1]
+	if len(stack) == 1 {
+		delete(p.stacks, id)
+	} else {
+		p.stacks[id] = stack[:len(stack)-1]
+	}
+
+	if p.data == nil {
+		p.data = map[string]*Data{}
+	}
+	d, ok := p.data[frame.path]
+	if !ok {
+		d = &Data{}
+		p.data[frame.path] = d
+	}
+	delta := skewed(now.Sub(frame.start))
+	d.UpdateAt(frame.start, delta)
+
+	return delta
+}
+
+// Dataset returns copies of the Data recorded for each region,
+// keyed by the region's path.  It is suitable for use as the Source
+// of a Reporter.
+func (p *Profiler) Dataset() map[string]*Data {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result := make(map[string]*Data, len(p.data))
+	for path, d := range p.data {
+		c := &Data{}
+		c.Merge(d)
+		result[path] = c
+	}
+
+	return result
+}
+
+// Begin begins a region on the calling goroutine using the
+// DefaultProfiler; see Profiler.Begin.
+func Begin(name string) {
+	DefaultProfiler.Begin(name)
+}
+
+// End ends the region most recently begun on the calling goroutine
+// using the DefaultProfiler; see Profiler.End.
+func End() time.Duration {
+	return DefaultProfiler.End()
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build !timeitnoop
+// +build !timeitnoop
+
+package timeit
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoroutineID(t *testing.T) {
+	ids := make(chan uint64, 2)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids <- goroutineID()
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	first, second := <-ids, <-ids
+	assert.NotEqual(t, uint64(0), first)
+	assert.NotEqual(t, uint64(0), second)
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, goroutineID(), goroutineID())
+}
+
+func TestProfilerClockDefault(t *testing.T) {
+	p := &Profiler{}
+
+	assert.Equal(t, WallClock, p.clock())
+}
+
+func TestProfilerBeginEnd(t *testing.T) {
+	p := &Profiler{Clock: &FakeClock{Time: time.Unix(1000, 0), Step: 10}}
+
+	p.Begin("handler")
+	p.Begin("db")
+	inner := p.End()
+	p.Begin("render")
+	p.End()
+	outer := p.End()
+
+	assert.Equal(t, time.Duration(10), inner)
+	assert.Equal(t, time.Duration(50), outer)
+	assert.Empty(t, p.stacks)
+	dataset := p.Dataset()
+	require.Len(t, dataset, 3)
+	assert.Equal(t, time.Duration(50), dataset["handler"].Mean)
+	assert.Equal(t, time.Duration(10), dataset["handler/db"].Mean)
+	assert.Equal(t, time.Duration(10), dataset["handler/render"].Mean)
+}
+
+func TestProfilerEndUnmatched(t *testing.T) {
+	p := &Profiler{}
+
+	result := p.End()
+
+	assert.Equal(t, time.Duration(0), result)
+	assert.Empty(t, p.Dataset())
+}
+
+// notifyClock is a Clock that signals each time it is read.
+type notifyClock struct {
+	read chan struct{}
+}
+
+func (nc *notifyClock) Now() time.Time {
+	nc.read <- struct{}{}
+	return time.Unix(1000, 0)
+}
+
+func (nc *notifyClock) Since(t time.Time) time.Duration {
+	return nc.Now().Sub(t)
+}
+
+func TestProfilerEndReadsClockUnlocked(t *testing.T) {
+	clock := &notifyClock{read: make(chan struct{}, 1)}
+	p := &Profiler{Clock: clock}
+	done := make(chan struct{})
+	p.mu.Lock()
+
+	go func() {
+		defer close(done)
+		p.End()
+	}()
+
+	select {
+	case <-clock.read:
+	case <-time.After(time.Second):
+		t.Error("clock not read while profiler locked")
+	}
+	p.mu.Unlock()
+	<-done
+}
+
+func TestProfilerGoroutines(t *testing.T) {
+	p := &Profiler{Clock: &FakeClock{Time: time.Unix(1000, 0), Step: 10}}
+	p.Begin("main")
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.Begin("worker")
+			p.End()
+		}()
+	}
+
+	wg.Wait()
+	p.End()
+
+	dataset := p.Dataset()
+	require.Len(t, dataset, 2)
+	assert.Equal(t, int64(1), dataset["main"].Samples)
+	assert.Equal(t, int64(4), dataset["worker"].Samples)
+}
+
+func TestProfilerDatasetCopies(t *testing.T) {
+	p := &Profiler{Clock: &FakeClock{Time: time.Unix(1000, 0), Step: 10}}
+	p.Begin("a")
+	p.End()
+
+	dataset := p.Dataset()
+	dataset["a"].Update(1000)
+
+	assert.Equal(t, int64(1), p.Dataset()["a"].Samples)
+}
+
+func TestBeginEnd(t *testing.T) {
+	defer func(orig *Profiler) {
+		DefaultProfiler = orig
+	}(DefaultProfiler)
+	DefaultProfiler = &Profiler{Clock: &FakeClock{Time: time.Unix(1000, 0), Step: 10}}
+
+	Begin("a")
+	result := End()
+
+	assert.Equal(t, time.Duration(10), result)
+	assert.Equal(t, int64(1), DefaultProfiler.Dataset()["a"].Samples)
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"fmt"
+	"io"
+)
+
+// ProgressFunc is the type of a callback invoked to report the
+// progress of a long-running measurement, such as CmdData.Repeat.  It
+// is passed the number of iterations completed, the total number of
+// iterations, and the data accumulated so far.
+type ProgressFunc func(done, total int, d *Data)
+
+// ProgressWriter returns a ProgressFunc that writes a single-line
+// progress report to the writer, such as a terminal, overwriting the
+// line on each iteration.  The line is terminated once the final
+// iteration is complete.  Errors writing the progress report are
+// ignored.
+func ProgressWriter(w io.Writer) ProgressFunc {
+	return func(done, total int, d *Data) {
+		end := ""
+		if done >= total {
+			end = "\n"
+		}
+
+		fmt.Fprintf(w, "\r%d/%d mean %s stddev %s%s", done, total, humanDuration(d.Mean), humanDuration(d.SampleStdDev()), end)
+	}
+}
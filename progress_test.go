@@ -0,0 +1,38 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build !timeitnoop
+// +build !timeitnoop
+
+package timeit
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	d := &Data{}
+	progress := ProgressWriter(buf)
+
+	d.Update(1000000)
+	progress(1, 2, d)
+	d.Update(3000000)
+	progress(2, 2, d)
+
+	assert.Equal(t, "\r1/2 mean 1.00ms stddev 0s\r2/2 mean 2.00ms stddev 1.41ms\n", buf.String())
+}
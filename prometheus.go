@@ -0,0 +1,269 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// promResponse is a response from the Prometheus query API; only the
+// fields of interest are decoded.
+type promResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		Result []*promSeries `json:"result"`
+	} `json:"data"`
+}
+
+// promSeries is a single series in a Prometheus query result.  Instant
+// queries return a single value, while range queries return a list of
+// values.
+type promSeries struct {
+	Metric map[string]string `json:"metric"`
+	Value  []interface{}     `json:"value"`
+	Values [][]interface{}   `json:"values"`
+}
+
+// value returns the value of the series; for range query results, the
+// most recent value is used.  Returns an error wrapping ErrBadValue if
+// the value cannot be decoded.
+func (ps *promSeries) value() (float64, error) {
+	pair := ps.Value
+	if len(ps.Values) > 0 {
+		pair = ps.Values[len(ps.Values)-1]
+	}
+	if len(pair) != 2 {
+		return 0, fmt.Errorf("%w: %v", ErrBadValue, pair)
+	}
+	text, ok := pair[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("%w: %v", ErrBadValue, pair[1])
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrBadValue, err)
+	}
+
+	return value, nil
+}
+
+// key returns the name of the Data the series applies to, and the
+// role of the series: "sum", "count", "bucket", or "quantile".  The
+// name is the base metric name, followed by any labels other than
+// "le" and "quantile", in the Prometheus style.  Returns an empty
+// role if the series is not part of a summary or histogram.
+func (ps *promSeries) key() (string, string) {
+	name := ps.Metric["__name__"]
+	role := ""
+	switch {
+	case strings.HasSuffix(name, "_bucket") && ps.Metric["le"] != "":
+		name, role = strings.TrimSuffix(name, "_bucket"), "bucket"
+	case strings.HasSuffix(name, "_sum"):
+		name, role = strings.TrimSuffix(name, "_sum"), "sum"
+	case strings.HasSuffix(name, "_count"):
+		name, role = strings.TrimSuffix(name, "_count"), "count"
+	case ps.Metric["quantile"] != "":
+		role = "quantile"
+	}
+
+	// Add the labels
+	labels := []string{}
+	for label, value := range ps.Metric {
+		if label != "__name__" && label != "le" && label != "quantile" {
+			labels = append(labels, fmt.Sprintf("%s=%q", label, value))
+		}
+	}
+	if len(labels) > 0 {
+		sort.Strings(labels)
+		name = fmt.Sprintf("%s{%s}", name, strings.Join(labels, ","))
+	}
+
+	return name, role
+}
+
+// promMetric accumulates the series that make up a single Prometheus
+// summary or histogram.
+type promMetric struct {
+	count     float64
+	sum       float64
+	quantiles map[float64]float64
+	buckets   map[float64]float64
+}
+
+// seconds converts a value in seconds to a time.Duration.
+func seconds(v float64) time.Duration {
+	return time.Duration(v * float64(time.Second))
+}
+
+// toData sets the statistics of the Data from the metric.  The mean
+// is computed exactly from the sum and count.  For summaries, the
+// minimum and maximum are approximated by the most extreme quantiles
+// available, and the variance is not available.  For histograms, the
+// minimum and maximum are approximated by the bounds of the extreme
+// non-empty buckets, and the variance is approximated by assuming
+// each sample lies at the midpoint of its bucket.
+func (pm *promMetric) toData(d *Data) {
+	d.Samples = int64(math.Round(pm.count))
+	d.Mean = 0
+	d.Min = 0
+	d.Max = 0
+	d.m2 = 0
+	mean := 0.0
+	if pm.count > 0 {
+		mean = pm.sum / pm.count
+		d.Mean = seconds(mean)
+	}
+
+	// Approximate min and max from the quantiles
+	if len(pm.quantiles) > 0 {
+		qs := make([]float64, 0, len(pm.quantiles))
+		for q := range pm.quantiles {
+			qs = append(qs, q)
+		}
+		sort.Float64s(qs)
+		d.Min = seconds(pm.quantiles[qs[0]])
+		d.Max = seconds(pm.quantiles[qs[len(qs)-1]])
+	}
+
+	// Approximate min, max, and variance from the buckets
+	if len(pm.buckets) > 0 {
+		bounds := make([]float64, 0, len(pm.buckets))
+		for le := range pm.buckets {
+			bounds = append(bounds, le)
+		}
+		sort.Float64s(bounds)
+
+		first := true
+		lower, prev, m2 := 0.0, 0.0, 0.0
+		for _, le := range bounds {
+			n := pm.buckets[le] - prev
+			upper, mid := le, (lower+le)/2
+			if math.IsInf(le, 1) {
+				upper, mid = lower, lower
+			}
+			if n > 0 {
+				if first {
+					d.Min = seconds(lower)
+					first = false
+				}
+				d.Max = seconds(upper)
+				m2 += n * (mid - mean) * (mid - mean)
+			}
+			lower, prev = le, pm.buckets[le]
+		}
+
+		d.setM2(m2 * float64(time.Second) * float64(time.Second))
+	}
+}
+
+// add adds the value of a series to the metric.  Quantiles of NaN,
+// which Prometheus reports for empty summaries, are skipped; other
+// values that are not finite are rejected with ErrBadValue.
+func (pm *promMetric) add(ps *promSeries, role string) error {
+	value, err := ps.value()
+	if err != nil {
+		return err
+	}
+	if role == "quantile" && math.IsNaN(value) {
+		return nil
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return fmt.Errorf("%w: %v", ErrBadValue, value)
+	}
+
+	switch role {
+	case "sum":
+		pm.sum = value
+	case "count":
+		pm.count = value
+	case "quantile":
+		q, err := strconv.ParseFloat(ps.Metric["quantile"], 64)
+		if err != nil {
+			return err
+		}
+		pm.quantiles[q] = value
+	case "bucket":
+		le, err := strconv.ParseFloat(ps.Metric["le"], 64)
+		if err != nil {
+			return err
+		}
+		pm.buckets[le] = value
+	}
+
+	return nil
+}
+
+// ReadPrometheus reads a response from the Prometheus query API, such
+// as the result of querying for a summary or histogram metric with
+// the regular expression `{__name__=~"metric(_sum|_count|_bucket)?"}`,
+// and sets the Data in the dataset to approximate the statistics
+// observed by Prometheus.  Values are assumed to be in seconds, as
+// is the Prometheus convention.  For range queries, the most recent
+// value of each series is used.  Quantiles of NaN, as reported for
+// empty summaries, are ignored.  The Data are named by the metric
+// name, followed by any labels in the Prometheus style, e.g.,
+// `http_request_duration_seconds{handler="/"}`.  As with ReplayJSON,
+// Data not already present in the dataset will be created, but the
+// statistics of existing Data are replaced.
+func ReadPrometheus(r io.Reader, dataset map[string]*Data) error {
+	resp := &promResponse{}
+	if err := json.NewDecoder(r).Decode(resp); err != nil {
+		return fmt.Errorf("%w: %s", ErrBadImport, err)
+	}
+	if resp.Status != "success" {
+		return fmt.Errorf("%w: query failed: %s", ErrBadImport, resp.Error)
+	}
+
+	// Collect the series into metrics
+	metrics := map[string]*promMetric{}
+	for _, ps := range resp.Data.Result {
+		name, role := ps.key()
+		if role == "" {
+			continue
+		}
+
+		pm, ok := metrics[name]
+		if !ok {
+			pm = &promMetric{
+				quantiles: map[float64]float64{},
+				buckets:   map[float64]float64{},
+			}
+			metrics[name] = pm
+		}
+		if err := pm.add(ps, role); err != nil {
+			return fmt.Errorf("%w: %s: %s", ErrBadImport, name, err)
+		}
+	}
+
+	// Convert the metrics to Data
+	for name, pm := range metrics {
+		d, ok := dataset[name]
+		if !ok {
+			d = &Data{}
+			dataset[name] = d
+		}
+		pm.toData(d)
+	}
+
+	return nil
+}
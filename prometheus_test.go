@@ -0,0 +1,334 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromSeriesValueInstant(t *testing.T) {
+	ps := &promSeries{Value: []interface{}{1000.0, "1.5"}}
+
+	result, err := ps.value()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, result)
+}
+
+func TestPromSeriesValueRange(t *testing.T) {
+	ps := &promSeries{Values: [][]interface{}{
+		{1000.0, "1.5"},
+		{1001.0, "2.5"},
+	}}
+
+	result, err := ps.value()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2.5, result)
+}
+
+func TestPromSeriesValueBadPair(t *testing.T) {
+	ps := &promSeries{Value: []interface{}{1000.0}}
+
+	_, err := ps.value()
+
+	assert.ErrorIs(t, err, ErrBadValue)
+}
+
+func TestPromSeriesValueBadType(t *testing.T) {
+	ps := &promSeries{Value: []interface{}{1000.0, 1.5}}
+
+	_, err := ps.value()
+
+	assert.ErrorIs(t, err, ErrBadValue)
+}
+
+func TestPromSeriesValueBadFloat(t *testing.T) {
+	ps := &promSeries{Value: []interface{}{1000.0, "bad"}}
+
+	_, err := ps.value()
+
+	assert.ErrorIs(t, err, ErrBadValue)
+}
+
+func TestPromSeriesKeyBucket(t *testing.T) {
+	ps := &promSeries{Metric: map[string]string{
+		"__name__": "latency_seconds_bucket",
+		"le":       "0.5",
+		"path":     "/",
+		"method":   "GET",
+	}}
+
+	name, role := ps.key()
+
+	assert.Equal(t, `latency_seconds{method="GET",path="/"}`, name)
+	assert.Equal(t, "bucket", role)
+}
+
+func TestPromSeriesKeySum(t *testing.T) {
+	ps := &promSeries{Metric: map[string]string{"__name__": "latency_seconds_sum"}}
+
+	name, role := ps.key()
+
+	assert.Equal(t, "latency_seconds", name)
+	assert.Equal(t, "sum", role)
+}
+
+func TestPromSeriesKeyCount(t *testing.T) {
+	ps := &promSeries{Metric: map[string]string{"__name__": "latency_seconds_count"}}
+
+	name, role := ps.key()
+
+	assert.Equal(t, "latency_seconds", name)
+	assert.Equal(t, "count", role)
+}
+
+func TestPromSeriesKeyQuantile(t *testing.T) {
+	ps := &promSeries{Metric: map[string]string{
+		"__name__": "latency_seconds",
+		"quantile": "0.99",
+	}}
+
+	name, role := ps.key()
+
+	assert.Equal(t, "latency_seconds", name)
+	assert.Equal(t, "quantile", role)
+}
+
+func TestPromSeriesKeyOther(t *testing.T) {
+	ps := &promSeries{Metric: map[string]string{"__name__": "up"}}
+
+	name, role := ps.key()
+
+	assert.Equal(t, "up", name)
+	assert.Equal(t, "", role)
+}
+
+func TestSeconds(t *testing.T) {
+	result := seconds(1.5)
+
+	assert.Equal(t, 1500*time.Millisecond, result)
+}
+
+func TestPromMetricToDataSummary(t *testing.T) {
+	pm := &promMetric{
+		count: 4,
+		sum:   2,
+		quantiles: map[float64]float64{
+			0.5:  0.4,
+			0.99: 0.9,
+			0.9:  0.8,
+		},
+	}
+	d := &Data{Flags: StdDev}
+
+	pm.toData(d)
+
+	assert.Equal(t, &Data{
+		Samples: 4,
+		Mean:    500 * time.Millisecond,
+		Min:     400 * time.Millisecond,
+		Max:     900 * time.Millisecond,
+		Flags:   StdDev,
+	}, d)
+}
+
+func TestPromMetricToDataHistogram(t *testing.T) {
+	pm := &promMetric{
+		count: 5,
+		sum:   2.5,
+		buckets: map[float64]float64{
+			0.1:         1,
+			0.5:         3,
+			1:           4,
+			math.Inf(1): 5,
+		},
+	}
+	d := &Data{}
+
+	pm.toData(d)
+
+	assert.Equal(t, int64(5), d.Samples)
+	assert.Equal(t, 500*time.Millisecond, d.Mean)
+	assert.Equal(t, time.Duration(0), d.Min)
+	assert.Equal(t, time.Second, d.Max)
+	assert.InDelta(t, 0.595e18, float64(d.m2), 1e6)
+	assert.False(t, d.Overflowed)
+}
+
+func TestPromMetricToDataHistogramEmptyBuckets(t *testing.T) {
+	pm := &promMetric{
+		count: 2,
+		sum:   0.6,
+		buckets: map[float64]float64{
+			0.1:         0,
+			0.5:         2,
+			1:           2,
+			math.Inf(1): 2,
+		},
+	}
+	d := &Data{}
+
+	pm.toData(d)
+
+	assert.Equal(t, 100*time.Millisecond, d.Min)
+	assert.Equal(t, 500*time.Millisecond, d.Max)
+	assert.InDelta(t, 0, float64(d.m2), 1e6)
+}
+
+func TestPromMetricToDataHistogramOverflow(t *testing.T) {
+	pm := &promMetric{
+		count: 1e9,
+		sum:   1e10,
+		buckets: map[float64]float64{
+			1:           5e8,
+			math.Inf(1): 1e9,
+		},
+	}
+	d := &Data{}
+
+	pm.toData(d)
+
+	assert.Equal(t, time.Duration(math.MaxInt64), d.m2)
+	assert.True(t, d.Overflowed)
+}
+
+func TestPromMetricToDataEmpty(t *testing.T) {
+	pm := &promMetric{
+		buckets: map[float64]float64{
+			math.Inf(1): 0,
+		},
+	}
+	d := &Data{Samples: 5, Mean: 5, Min: 5, Max: 5, m2: 5}
+
+	pm.toData(d)
+
+	assert.Equal(t, &Data{}, d)
+}
+
+func TestReadPrometheusBase(t *testing.T) {
+	text := `{
+	"status": "success",
+	"data": {
+		"resultType": "vector",
+		"result": [
+			{"metric": {"__name__": "rpc_seconds", "quantile": "0.5"}, "value": [1000, "0.2"]},
+			{"metric": {"__name__": "rpc_seconds", "quantile": "0.99"}, "value": [1000, "0.7"]},
+			{"metric": {"__name__": "rpc_seconds_sum"}, "value": [1000, "3"]},
+			{"metric": {"__name__": "rpc_seconds_count"}, "value": [1000, "10"]},
+			{"metric": {"__name__": "http_seconds_bucket", "le": "0.5", "path": "/"}, "value": [1000, "1"]},
+			{"metric": {"__name__": "http_seconds_bucket", "le": "+Inf", "path": "/"}, "value": [1000, "2"]},
+			{"metric": {"__name__": "http_seconds_sum", "path": "/"}, "value": [1000, "1"]},
+			{"metric": {"__name__": "http_seconds_count", "path": "/"}, "value": [1000, "2"]},
+			{"metric": {"__name__": "up"}, "value": [1000, "1"]}
+		]
+	}
+}`
+	existing := &Data{Flags: StdDev}
+	dataset := map[string]*Data{"rpc_seconds": existing}
+
+	err := ReadPrometheus(strings.NewReader(text), dataset)
+
+	assert.NoError(t, err)
+	assert.Len(t, dataset, 2)
+	assert.Same(t, existing, dataset["rpc_seconds"])
+	assert.Equal(t, &Data{
+		Samples: 10,
+		Mean:    300 * time.Millisecond,
+		Min:     200 * time.Millisecond,
+		Max:     700 * time.Millisecond,
+		Flags:   StdDev,
+	}, existing)
+	d := dataset[`http_seconds{path="/"}`]
+	assert.Equal(t, int64(2), d.Samples)
+	assert.Equal(t, 500*time.Millisecond, d.Mean)
+	assert.Equal(t, time.Duration(0), d.Min)
+	assert.Equal(t, 500*time.Millisecond, d.Max)
+}
+
+func TestReadPrometheusEmptySummary(t *testing.T) {
+	text := `{"status": "success", "data": {"result": [
+		{"metric": {"__name__": "rpc_seconds", "quantile": "0.5"}, "value": [1000, "NaN"]},
+		{"metric": {"__name__": "rpc_seconds", "quantile": "0.99"}, "value": [1000, "NaN"]},
+		{"metric": {"__name__": "rpc_seconds_sum"}, "value": [1000, "0"]},
+		{"metric": {"__name__": "rpc_seconds_count"}, "value": [1000, "0"]}
+	]}}`
+	dataset := map[string]*Data{}
+
+	err := ReadPrometheus(strings.NewReader(text), dataset)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]*Data{"rpc_seconds": {}}, dataset)
+}
+
+func TestReadPrometheusNotFinite(t *testing.T) {
+	text := `{"status": "success", "data": {"result": [
+		{"metric": {"__name__": "rpc_seconds_sum"}, "value": [1000, "+Inf"]}
+	]}}`
+
+	err := ReadPrometheus(strings.NewReader(text), map[string]*Data{})
+
+	assert.ErrorIs(t, err, ErrBadImport)
+	assert.EqualError(t, err, "invalid imported data: rpc_seconds: malformed value: +Inf")
+}
+
+func TestReadPrometheusDecodeError(t *testing.T) {
+	err := ReadPrometheus(strings.NewReader("bad"), map[string]*Data{})
+
+	assert.ErrorIs(t, err, ErrBadImport)
+}
+
+func TestReadPrometheusQueryError(t *testing.T) {
+	err := ReadPrometheus(strings.NewReader(`{"status": "error", "error": "bad query"}`), map[string]*Data{})
+
+	assert.ErrorIs(t, err, ErrBadImport)
+	assert.EqualError(t, err, "invalid imported data: query failed: bad query")
+}
+
+func TestReadPrometheusBadValue(t *testing.T) {
+	text := `{"status": "success", "data": {"result": [
+		{"metric": {"__name__": "rpc_seconds_sum"}, "value": [1000]}
+	]}}`
+
+	err := ReadPrometheus(strings.NewReader(text), map[string]*Data{})
+
+	assert.ErrorIs(t, err, ErrBadImport)
+	assert.EqualError(t, err, "invalid imported data: rpc_seconds: malformed value: [1000]")
+}
+
+func TestReadPrometheusBadQuantile(t *testing.T) {
+	text := `{"status": "success", "data": {"result": [
+		{"metric": {"__name__": "rpc_seconds", "quantile": "bad"}, "value": [1000, "1"]}
+	]}}`
+
+	err := ReadPrometheus(strings.NewReader(text), map[string]*Data{})
+
+	assert.ErrorIs(t, err, ErrBadImport)
+}
+
+func TestReadPrometheusBadBucket(t *testing.T) {
+	text := `{"status": "success", "data": {"result": [
+		{"metric": {"__name__": "rpc_seconds_bucket", "le": "bad"}, "value": [1000, "1"]}
+	]}}`
+
+	err := ReadPrometheus(strings.NewReader(text), map[string]*Data{})
+
+	assert.ErrorIs(t, err, ErrBadImport)
+}
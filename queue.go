@@ -0,0 +1,100 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"sync"
+	"time"
+)
+
+// Queue times jobs passing through a job queue or worker pool,
+// recording the time each job spent waiting in the queue separately
+// from the time spent processing it.  A Queue may be shared by
+// multiple producer and worker goroutines; updates to the Data are
+// serialized by an internal mutex.
+type Queue struct {
+	Wait  *Data // Data to update with queue wait times; may be nil
+	Work  *Data // Data to update with processing times; may be nil
+	Clock Clock // Source of time; nil for WallClock
+	mu    sync.Mutex
+}
+
+// Job represents a single job that has been enqueued on a Queue.
+type Job struct {
+	Queue    *Queue    // The queue the job was enqueued on
+	Enqueued time.Time // The time the job was enqueued
+}
+
+// clock returns the Clock to use for the Queue.
+func (q *Queue) clock() Clock {
+	if q.Clock == nil {
+		return WallClock
+	}
+
+	return q.Clock
+}
+
+// update updates the specified data.
+func (q *Queue) update(d *Data, sample time.Duration) {
+	if d == nil {
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	d.Update(sample)
+}
+
+// Enqueue returns a Job recording the time it was enqueued.  The Job
+// should be passed through the queue along with the work to perform.
+func (q *Queue) Enqueue() Job {
+	return Job{
+		Queue:    q,
+		Enqueued: q.clock().Now(),
+	}
+}
+
+// Run records the time the job spent waiting in the queue, then runs
+// the function, recording the time it took to execute.  It returns
+// both the wait time and the processing time.
+func (j Job) Run(fn func()) (wait, work time.Duration) {
+	clock := j.Queue.clock()
+	start := clock.Now()
+	wait = skewed(start.Sub(j.Enqueued))
+	j.Queue.update(j.Queue.Wait, wait)
+
+	defer func() {
+		work = elapsed(clock, start)
+		j.Queue.update(j.Queue.Work, work)
+	}()
+
+	fn()
+
+	return
+}
+
+// Handle is used by message consumers to time the handling of a
+// message or event produced at the specified time, such as a Kafka
+// record timestamp.  The age of the message when processing begins is
+// recorded into Wait, and the time taken by the handler into Work.
+// Note that the age may be affected by clock skew between the
+// producer and the consumer.
+func (q *Queue) Handle(produced time.Time, fn func()) (age, work time.Duration) {
+	return Job{
+		Queue:    q,
+		Enqueued: produced,
+	}.Run(fn)
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build !timeitnoop
+// +build !timeitnoop
+
+package timeit
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueueClockDefault(t *testing.T) {
+	q := &Queue{}
+
+	result := q.clock()
+
+	assert.Equal(t, WallClock, result)
+}
+
+func TestQueueClockSet(t *testing.T) {
+	clock := &testClock{}
+	q := &Queue{Clock: clock}
+
+	result := q.clock()
+
+	assert.Same(t, clock, result)
+}
+
+func TestQueueUpdateNil(t *testing.T) {
+	q := &Queue{}
+
+	q.update(nil, 50)
+}
+
+func TestQueueUpdate(t *testing.T) {
+	d := &Data{}
+	q := &Queue{}
+
+	q.update(d, 50)
+
+	assert.Equal(t, int64(1), d.Samples)
+}
+
+func TestQueueEnqueue(t *testing.T) {
+	q := &Queue{Clock: &testClock{now: time.Unix(1000, 0)}}
+
+	result := q.Enqueue()
+
+	assert.Equal(t, Job{
+		Queue:    q,
+		Enqueued: time.Unix(1000, 0),
+	}, result)
+}
+
+func TestJobRun(t *testing.T) {
+	q := &Queue{
+		Wait:  &Data{},
+		Work:  &Data{},
+		Clock: &stepClock{now: time.Unix(1000, 0), step: 10},
+	}
+	job := q.Enqueue()
+	called := false
+
+	wait, work := job.Run(func() { called = true })
+
+	assert.True(t, called)
+	assert.Equal(t, time.Duration(10), wait)
+	assert.Equal(t, time.Duration(10), work)
+	assert.Equal(t, int64(1), q.Wait.Samples)
+	assert.Equal(t, time.Duration(10), q.Wait.Mean)
+	assert.Equal(t, int64(1), q.Work.Samples)
+	assert.Equal(t, time.Duration(10), q.Work.Mean)
+}
+
+func TestJobRunConcurrent(t *testing.T) {
+	q := &Queue{
+		Wait: &Data{},
+		Work: &Data{},
+	}
+	jobs := make(chan Job, 10)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				job.Run(func() {})
+			}
+		}()
+	}
+
+	for i := 0; i < 10; i++ {
+		jobs <- q.Enqueue()
+	}
+	close(jobs)
+	wg.Wait()
+
+	assert.Equal(t, int64(10), q.Wait.Samples)
+	assert.Equal(t, int64(10), q.Work.Samples)
+}
+
+func TestQueueHandle(t *testing.T) {
+	q := &Queue{
+		Wait:  &Data{},
+		Work:  &Data{},
+		Clock: &stepClock{now: time.Unix(1000, 0), step: 10},
+	}
+	called := false
+
+	age, work := q.Handle(time.Unix(999, 0), func() { called = true })
+
+	assert.True(t, called)
+	assert.Equal(t, time.Second, age)
+	assert.Equal(t, time.Duration(10), work)
+	assert.Equal(t, time.Second, q.Wait.Mean)
+	assert.Equal(t, time.Duration(10), q.Work.Mean)
+}
+
+func TestQueueHandleSkewed(t *testing.T) {
+	q := &Queue{
+		Wait:  &Data{Invalid: DropInvalid},
+		Work:  &Data{},
+		Clock: &stepClock{now: time.Unix(1000, 0), step: 10},
+	}
+
+	age, _ := q.Handle(time.Unix(1001, 0), func() {})
+
+	assert.Equal(t, -time.Second, age)
+	assert.Equal(t, int64(0), q.Wait.Samples)
+	assert.Equal(t, int64(1), q.Wait.InvalidSamples)
+}
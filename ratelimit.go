@@ -0,0 +1,152 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RateLimiter is a token-bucket rate limiter.  Tokens are added to
+// the bucket at the configured Rate, up to Burst tokens, and each
+// allowed event consumes one token; events arriving when the bucket
+// is empty are denied and counted.  The wrappers returned by
+// Anomalies, Burns, and Sink apply a RateLimiter to observers and
+// sinks, so that threshold callbacks and network emission cannot
+// amplify an incident by firing on every one of thousands of slow
+// samples per second.  Each observer or sink to be limited
+// independently should have its own RateLimiter.  A RateLimiter is
+// safe for concurrent use.
+type RateLimiter struct {
+	Rate   float64 // Events allowed per second
+	Burst  int     // Maximum burst of events; at least 1
+	Clock  Clock   // Source of time; nil for WallClock
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	denied int64
+}
+
+// clock returns the Clock to use for the RateLimiter.
+func (rl *RateLimiter) clock() Clock {
+	if rl.Clock == nil {
+		return WallClock
+	}
+
+	return rl.Clock
+}
+
+// Denied returns the number of events that have been denied.
+func (rl *RateLimiter) Denied() int64 {
+	return atomic.LoadInt64(&rl.denied)
+}
+
+// Allow tests whether an event is allowed, consuming a token if it
+// is.  The bucket starts full.
+func (rl *RateLimiter) Allow() bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	burst := float64(rl.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+
+	// Refill the bucket
+	now := rl.clock().Now()
+	if rl.last.IsZero() {
+		rl.tokens = burst
+	} else if elapsed := corrected(now.Sub(rl.last)); elapsed > 0 {
+		rl.tokens += elapsed.Seconds() * rl.Rate
+		if rl.tokens > burst {
+			rl.tokens = burst
+		}
+	}
+	rl.last = now
+
+	// Consume a token
+	if rl.tokens < 1 {
+		atomic.AddInt64(&rl.denied, 1)
+		return false
+	}
+	rl.tokens--
+
+	return true
+}
+
+// Anomalies wraps an AnomalyFunc so that it is only invoked when the
+// RateLimiter allows it.
+func (rl *RateLimiter) Anomalies(fn AnomalyFunc) AnomalyFunc {
+	return func(a Anomaly) {
+		if rl.Allow() {
+			fn(a)
+		}
+	}
+}
+
+// Burns wraps a BurnFunc so that it is only invoked when the
+// RateLimiter allows it.
+func (rl *RateLimiter) Burns(fn BurnFunc) BurnFunc {
+	return func(a BurnAlert) {
+		if rl.Allow() {
+			fn(a)
+		}
+	}
+}
+
+// Sink wraps a Sink so that snapshots are only emitted when the
+// RateLimiter allows it.  Denied snapshots are discarded without
+// error; they are counted by Denied.  The returned Sink implements
+// ContextSink and Flusher, forwarding to the wrapped Sink if it does.
+func (rl *RateLimiter) Sink(sink Sink) Sink {
+	return &limitedSink{rl: rl, sink: sink}
+}
+
+// limitedSink is the Sink returned by RateLimiter.Sink.
+type limitedSink struct {
+	rl   *RateLimiter
+	sink Sink
+}
+
+// Emit emits the snapshot if the RateLimiter allows it.
+func (ls *limitedSink) Emit(snap *Snapshot) error {
+	return ls.EmitContext(context.Background(), snap)
+}
+
+// EmitContext emits the snapshot if the RateLimiter allows it,
+// passing the context on if the wrapped Sink is a ContextSink.
+func (ls *limitedSink) EmitContext(ctx context.Context, snap *Snapshot) error {
+	if !ls.rl.Allow() {
+		return nil
+	}
+
+	if cs, ok := ls.sink.(ContextSink); ok {
+		return cs.EmitContext(ctx, snap)
+	}
+
+	return ls.sink.Emit(snap)
+}
+
+// Flush flushes the wrapped Sink if it is a Flusher.  Flushing is not
+// subject to the RateLimiter.
+func (ls *limitedSink) Flush(ctx context.Context) error {
+	if f, ok := ls.sink.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+
+	return nil
+}
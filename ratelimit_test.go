@@ -0,0 +1,172 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiterClockDefault(t *testing.T) {
+	rl := &RateLimiter{}
+
+	assert.Equal(t, WallClock, rl.clock())
+}
+
+func TestRateLimiterAllowBase(t *testing.T) {
+	clock := &FakeClock{Time: time.Unix(1000, 0)}
+	rl := &RateLimiter{Rate: 2, Burst: 3, Clock: clock}
+	results := []bool{}
+
+	for i := 0; i < 4; i++ {
+		results = append(results, rl.Allow())
+	}
+	clock.Time = clock.Time.Add(500 * time.Millisecond)
+	results = append(results, rl.Allow(), rl.Allow())
+	clock.Time = clock.Time.Add(time.Hour)
+	for i := 0; i < 4; i++ {
+		results = append(results, rl.Allow())
+	}
+
+	assert.Equal(t, []bool{true, true, true, false, true, false, true, true, true, false}, results)
+	assert.Equal(t, int64(3), rl.Denied())
+}
+
+func TestRateLimiterAllowZeroBurst(t *testing.T) {
+	clock := &FakeClock{Time: time.Unix(1000, 0)}
+	rl := &RateLimiter{Rate: 1, Clock: clock}
+
+	first := rl.Allow()
+	second := rl.Allow()
+
+	assert.True(t, first)
+	assert.False(t, second)
+}
+
+func TestRateLimiterAnomalies(t *testing.T) {
+	clock := &FakeClock{Time: time.Unix(1000, 0)}
+	rl := &RateLimiter{Rate: 1, Clock: clock}
+	var anomalies []Anomaly
+	fn := rl.Anomalies(func(a Anomaly) { anomalies = append(anomalies, a) })
+
+	fn(Anomaly{Sample: 1})
+	fn(Anomaly{Sample: 2})
+
+	assert.Equal(t, []Anomaly{{Sample: 1}}, anomalies)
+}
+
+func TestRateLimiterBurns(t *testing.T) {
+	clock := &FakeClock{Time: time.Unix(1000, 0)}
+	rl := &RateLimiter{Rate: 1, Clock: clock}
+	var alerts []BurnAlert
+	fn := rl.Burns(func(a BurnAlert) { alerts = append(alerts, a) })
+
+	fn(BurnAlert{LongRate: 1})
+	fn(BurnAlert{LongRate: 2})
+
+	assert.Equal(t, []BurnAlert{{LongRate: 1}}, alerts)
+}
+
+func TestRateLimiterSink(t *testing.T) {
+	clock := &FakeClock{Time: time.Unix(1000, 0)}
+	rl := &RateLimiter{Rate: 1, Clock: clock}
+	inner := &fakeSink{failures: 1}
+	sink := rl.Sink(inner)
+
+	err1 := sink.Emit(&Snapshot{})
+	err2 := sink.Emit(&Snapshot{})
+
+	assert.Same(t, assert.AnError, err1)
+	assert.NoError(t, err2)
+	assert.Empty(t, inner.emitted)
+	assert.Equal(t, int64(1), rl.Denied())
+}
+
+type fakeContextSink struct {
+	fakeSink
+	ctx context.Context
+}
+
+func (fcs *fakeContextSink) EmitContext(ctx context.Context, snap *Snapshot) error {
+	fcs.ctx = ctx
+	return fcs.Emit(snap)
+}
+
+func TestRateLimiterSinkEmitContext(t *testing.T) {
+	clock := &FakeClock{Time: time.Unix(1000, 0)}
+	rl := &RateLimiter{Rate: 1, Clock: clock}
+	inner := &fakeContextSink{}
+	sink := rl.Sink(inner)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err1 := sink.(ContextSink).EmitContext(ctx, &Snapshot{})
+	err2 := sink.(ContextSink).EmitContext(ctx, &Snapshot{})
+
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.Len(t, inner.emitted, 1)
+	assert.Equal(t, ctx, inner.ctx)
+	assert.Equal(t, int64(1), rl.Denied())
+}
+
+func TestRateLimiterSinkEmitContextPlain(t *testing.T) {
+	clock := &FakeClock{Time: time.Unix(1000, 0)}
+	rl := &RateLimiter{Rate: 1, Clock: clock}
+	inner := &fakeSink{}
+	sink := rl.Sink(inner)
+
+	err := sink.(ContextSink).EmitContext(context.Background(), &Snapshot{})
+
+	assert.NoError(t, err)
+	assert.Len(t, inner.emitted, 1)
+}
+
+func TestRateLimiterSinkFlush(t *testing.T) {
+	rl := &RateLimiter{Rate: 1}
+	inner := &fakeFlusher{err: assert.AnError}
+	sink := rl.Sink(inner)
+
+	err := sink.(Flusher).Flush(context.Background())
+
+	assert.Same(t, assert.AnError, err)
+}
+
+func TestRateLimiterSinkFlushPlain(t *testing.T) {
+	rl := &RateLimiter{Rate: 1}
+	sink := rl.Sink(&fakeSink{})
+
+	err := sink.(Flusher).Flush(context.Background())
+
+	assert.NoError(t, err)
+}
+
+func TestRateLimiterAllowClockStepped(t *testing.T) {
+	before := SkewCorrections()
+	clock := &FakeClock{Time: time.Unix(1000, 0)}
+	rl := &RateLimiter{Rate: 1, Burst: 1, Clock: clock}
+	results := []bool{rl.Allow()}
+
+	clock.Time = time.Unix(900, 0)
+	results = append(results, rl.Allow())
+	clock.Time = time.Unix(901, 0)
+	results = append(results, rl.Allow())
+
+	assert.Equal(t, []bool{true, false, true}, results)
+	assert.Equal(t, before+1, SkewCorrections())
+}
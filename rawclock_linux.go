@@ -0,0 +1,99 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build linux
+// +build linux
+
+package timeit
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// clockMonotonicRaw is the Linux clock ID of CLOCK_MONOTONIC_RAW.
+const clockMonotonicRaw = 4
+
+// clockGettime reads the specified clock, in nanoseconds.
+func clockGettime(id uintptr) (int64, error) {
+	var ts syscall.Timespec
+	_, _, errno := syscall.RawSyscall(syscall.SYS_CLOCK_GETTIME, id, uintptr(unsafe.Pointer(&ts)), 0) //nolint:gosec
+	if errno != 0 {
+		return 0, errno
+	}
+
+	return ts.Nano(), nil
+}
+
+// readMonotonicRaw reads CLOCK_MONOTONIC_RAW, in nanoseconds.
+func readMonotonicRaw() int64 {
+	ns, _ := clockGettime(clockMonotonicRaw)
+	return ns
+}
+
+// rawClock is a Clock that reads a raw clock.  Times are reported
+// relative to the wall clock time at which the rawClock was created.
+type rawClock struct {
+	base   time.Time    // Wall clock time corresponding to origin
+	origin int64        // Reading of the raw clock at base
+	read   func() int64 // Reads the raw clock, in nanoseconds
+}
+
+// newRawClock constructs a rawClock using the specified function to
+// read the raw clock.
+func newRawClock(read func() int64) *rawClock {
+	return &rawClock{
+		base:   time.Now().Round(0),
+		origin: read(),
+		read:   read,
+	}
+}
+
+// Now returns the current time.
+func (rc *rawClock) Now() time.Time {
+	return rc.base.Add(time.Duration(rc.read() - rc.origin))
+}
+
+// Since returns the time elapsed since the specified time, which
+// should have been returned by the same Clock.
+func (rc *rawClock) Since(t time.Time) time.Duration {
+	return rc.Now().Sub(t)
+}
+
+// RawClock returns a Clock that reads CLOCK_MONOTONIC_RAW, the raw
+// hardware-based monotonic clock, which unlike the clock used by
+// time.Now is not subject to frequency adjustments by NTP.  This is
+// intended for measuring very short operations, where those
+// adjustments and the behavior of the vDSO can distort results.
+// The Go runtime only reads CLOCK_MONOTONIC and CLOCK_REALTIME
+// through the vDSO, so each reading is a system call, typically
+// several times slower than time.Now (compare BenchmarkRawClockNow
+// with BenchmarkWallClockNow).  The cost is stable, however, so it
+// should be measured (see Overhead) and subtracted; RawClock is the
+// better choice only when frequency stability matters more than the
+// cost of reading the clock.  Times returned by the Clock are only
+// comparable with other times returned by the same Clock.  Returns an
+// error wrapping ErrUnsupported if the clock is not available on this
+// platform.  RawClock is only implemented on Linux; in particular,
+// mach_absolute_time is not used on macOS, where RawClock always
+// returns an error.
+func RawClock() (Clock, error) {
+	if _, err := clockGettime(clockMonotonicRaw); err != nil {
+		return nil, fmt.Errorf("%w: CLOCK_MONOTONIC_RAW: %s", ErrUnsupported, err)
+	}
+
+	return newRawClock(readMonotonicRaw), nil
+}
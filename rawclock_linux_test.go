@@ -0,0 +1,89 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build linux
+// +build linux
+
+package timeit
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClockGettimeBase(t *testing.T) {
+	first, err := clockGettime(clockMonotonicRaw)
+	require.NoError(t, err)
+	second, err := clockGettime(clockMonotonicRaw)
+	require.NoError(t, err)
+
+	assert.GreaterOrEqual(t, second, first)
+}
+
+func TestClockGettimeError(t *testing.T) {
+	result, err := clockGettime(1 << 20)
+
+	assert.ErrorIs(t, err, syscall.EINVAL)
+	assert.Equal(t, int64(0), result)
+}
+
+func TestRawClockNow(t *testing.T) {
+	var now int64 = 1000
+	rc := newRawClock(func() int64 { return now })
+	base := rc.base
+
+	now += 250
+
+	assert.Equal(t, base.Add(250), rc.Now())
+}
+
+func TestRawClockSince(t *testing.T) {
+	var now int64 = 1000
+	rc := newRawClock(func() int64 { return now })
+	start := rc.Now()
+
+	now += 250
+
+	assert.Equal(t, time.Duration(250), rc.Since(start))
+}
+
+func TestRawClockBase(t *testing.T) {
+	clock, err := RawClock()
+	require.NoError(t, err)
+
+	start := clock.Now()
+	time.Sleep(time.Millisecond)
+
+	assert.GreaterOrEqual(t, clock.Since(start), time.Millisecond)
+}
+
+func BenchmarkWallClockNow(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		WallClock.Now()
+	}
+}
+
+func BenchmarkRawClockNow(b *testing.B) {
+	clock, err := RawClock()
+	require.NoError(b, err)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		clock.Now()
+	}
+}
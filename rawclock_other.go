@@ -0,0 +1,29 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build !linux
+// +build !linux
+
+package timeit
+
+import "fmt"
+
+// RawClock returns a Clock that reads a raw, high-resolution
+// monotonic clock; no such clock is supported on this platform, so
+// an error wrapping ErrUnsupported is returned.  In particular,
+// mach_absolute_time is not implemented on macOS, since reading it
+// requires cgo; WallClock should be used there instead.
+func RawClock() (Clock, error) {
+	return nil, fmt.Errorf("%w: raw clock", ErrUnsupported)
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// region tracks the time spent in regions nested within a region
+// timed by TimeItContext or SelfTimeIt.
+type region struct {
+	nested int64 // Total time of nested regions
+}
+
+// regionKey is the context key for the enclosing region.
+type regionKey struct{}
+
+// TimeItContext is similar to TimeIt, but passes the function a
+// context identifying the timed region.  Regions timed with the
+// context, or with contexts derived from it, are nested within this
+// region, and their time is attributed to it; see SelfTimeIt.  Note
+// that the function's time is always attributed to any region
+// enclosing this one.
+func (d *Data) TimeItContext(ctx context.Context, fn func(ctx context.Context)) time.Duration {
+	return d.timeRegion(ctx, false, fn)
+}
+
+// SelfTimeIt is similar to TimeItContext, but updates the data with
+// the self time of the function: the time it took to execute, less
+// the time attributed to regions nested within it.  This allows
+// layered instrumentation, in which a timed function calls other
+// timed functions, to report the time spent in each layer.  If
+// nested regions run concurrently, their total time may exceed that
+// of the enclosing region, in which case the self time is 0.  It
+// returns the self time.
+func (d *Data) SelfTimeIt(ctx context.Context, fn func(ctx context.Context)) time.Duration {
+	return d.timeRegion(ctx, true, fn)
+}
+
+// timeRegion runs a function as a region nested within any region
+// identified by the context, updating the data with the time it
+// took, or with its self time if requested.
+func (d *Data) timeRegion(ctx context.Context, self bool, fn func(ctx context.Context)) (delta time.Duration) {
+	if !Enabled() {
+		fn(ctx)
+		return 0
+	}
+
+	// Get the current time and arrange to update the data
+	r := &region{}
+	clock := d.clock()
+	start := clock.Now()
+	defer func() {
+		delta = elapsed(clock, start)
+		if parent, ok := ctx.Value(regionKey{}).(*region); ok && delta > 0 {
+			atomic.AddInt64(&parent.nested, int64(delta))
+		}
+		if self && delta > 0 {
+			delta -= time.Duration(atomic.LoadInt64(&r.nested))
+			if delta < 0 {
+				delta = 0
+			}
+		}
+		d.updateSample(Sample{At: start, Duration: delta})
+	}()
+
+	// Invoke the function
+	fn(context.WithValue(ctx, regionKey{}, r))
+
+	return
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build !timeitnoop
+// +build !timeitnoop
+
+package timeit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataTimeItContextBase(t *testing.T) {
+	d := &Data{Clock: &testClock{now: time.Unix(1000, 0), delta: 5}}
+	ctx := context.Background()
+	var r *region
+
+	result := d.TimeItContext(ctx, func(ctx context.Context) {
+		r, _ = ctx.Value(regionKey{}).(*region)
+	})
+
+	assert.Equal(t, time.Duration(5), result)
+	assert.Equal(t, int64(1), d.Samples)
+	assert.Equal(t, time.Duration(5), d.Mean)
+	assert.NotNil(t, r)
+}
+
+func TestDataTimeItContextNested(t *testing.T) {
+	clock := &FakeClock{Time: time.Unix(1000, 0), Step: 10}
+	outer := &Data{Clock: clock}
+	inner := &Data{Clock: clock}
+
+	result := outer.TimeItContext(context.Background(), func(ctx context.Context) {
+		inner.TimeItContext(ctx, func(ctx context.Context) {})
+	})
+
+	assert.Equal(t, time.Duration(30), result)
+	assert.Equal(t, time.Duration(30), outer.Mean)
+	assert.Equal(t, time.Duration(10), inner.Mean)
+}
+
+func TestDataSelfTimeItNested(t *testing.T) {
+	clock := &FakeClock{Time: time.Unix(1000, 0), Step: 10}
+	outer := &Data{Clock: clock}
+	middle := &Data{Clock: clock}
+	inner := &Data{Clock: clock}
+
+	result := outer.SelfTimeIt(context.Background(), func(ctx context.Context) {
+		middle.SelfTimeIt(ctx, func(ctx context.Context) {
+			inner.TimeItContext(ctx, func(ctx context.Context) {})
+		})
+	})
+
+	assert.Equal(t, time.Duration(20), result)
+	assert.Equal(t, time.Duration(20), outer.Mean)
+	assert.Equal(t, time.Duration(20), middle.Mean)
+	assert.Equal(t, time.Duration(10), inner.Mean)
+}
+
+func TestDataSelfTimeItSkewed(t *testing.T) {
+	clock := &stepClock{now: time.Unix(1000, 0), step: -10}
+	outer := &Data{Clock: clock, Invalid: DropInvalid}
+	inner := &Data{Clock: clock, Invalid: DropInvalid}
+
+	result := outer.SelfTimeIt(context.Background(), func(ctx context.Context) {
+		inner.TimeItContext(ctx, func(ctx context.Context) {})
+	})
+
+	assert.Equal(t, time.Duration(-30), result)
+	assert.Equal(t, int64(1), outer.InvalidSamples)
+	assert.Equal(t, int64(1), inner.InvalidSamples)
+}
+
+func TestDataSelfTimeItConcurrent(t *testing.T) {
+	clock := &FakeClock{Time: time.Unix(1000, 0), Step: 10}
+	outer := &Data{Clock: clock}
+
+	result := outer.SelfTimeIt(context.Background(), func(ctx context.Context) {
+		r, _ := ctx.Value(regionKey{}).(*region)
+		r.nested = 1000
+	})
+
+	assert.Equal(t, time.Duration(0), result)
+	assert.Equal(t, int64(1), outer.Samples)
+	assert.Equal(t, time.Duration(0), outer.Mean)
+}
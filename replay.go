@@ -0,0 +1,161 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record describes a single recorded sample, such as may be logged by
+// an application for later offline analysis.
+type Record struct {
+	Timestamp time.Time     `json:"timestamp"` // When the sample was taken
+	Duration  time.Duration `json:"duration"`  // The sample itself
+	Name      string        `json:"name"`      // Name of the timer
+}
+
+// replay applies a record to the appropriate Data in the dataset,
+// creating the Data if necessary.
+func (r *Record) replay(dataset map[string]*Data) {
+	d, ok := dataset[r.Name]
+	if !ok {
+		d = &Data{}
+		dataset[r.Name] = d
+	}
+
+	d.updateSample(Sample{At: r.Timestamp, Duration: r.Duration})
+}
+
+// ReplayJSON reads a stream of JSON-encoded Record objects, one per
+// line, and updates the Data in the dataset with the recorded
+// samples.  Data not already present in the dataset will be created;
+// this allows callers to preconfigure Data, e.g., with Flags or Next.
+// Blank lines are ignored.
+func ReplayJSON(r io.Reader, dataset map[string]*Data) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		// Skip blank lines
+		text := scanner.Bytes()
+		if len(text) == 0 {
+			continue
+		}
+
+		// Decode the record
+		rec := &Record{}
+		if err := json.Unmarshal(text, rec); err != nil {
+			return fmt.Errorf("%w: line %d: %s", ErrBadRecord, line, err)
+		}
+
+		rec.replay(dataset)
+	}
+
+	return scanner.Err()
+}
+
+// parseDuration parses a duration from a CSV field.  The duration
+// may either be an integer number of nanoseconds or a string
+// acceptable to time.ParseDuration.
+func parseDuration(text string) (time.Duration, error) {
+	if ns, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return time.Duration(ns), nil
+	}
+
+	return time.ParseDuration(text)
+}
+
+// ReplayCSV reads a stream of CSV records and updates the Data in the
+// dataset with the recorded samples.  Each record must have three
+// fields: the timestamp, in RFC 3339 format; the duration, as either
+// an integer number of nanoseconds or a string such as "1.5ms"; and
+// the name of the timer.  A header line beginning with "timestamp"
+// is ignored.  As with ReplayJSON, Data not already present in the
+// dataset will be created.
+func ReplayCSV(r io.Reader, dataset map[string]*Data) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+	for line := 1; ; line++ {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%w: %s", ErrBadRecord, err)
+		}
+
+		// Skip the header line
+		if line == 1 && fields[0] == "timestamp" {
+			continue
+		}
+
+		// Convert the record
+		rec := &Record{Name: fields[2]}
+		if rec.Timestamp, err = time.Parse(time.RFC3339Nano, fields[0]); err != nil {
+			return fmt.Errorf("%w: line %d: %s", ErrBadRecord, line, err)
+		}
+		if rec.Duration, err = parseDuration(fields[1]); err != nil {
+			return fmt.Errorf("%w: line %d: %s", ErrBadRecord, line, err)
+		}
+
+		rec.replay(dataset)
+	}
+}
+
+// ReplayFile reads a file of recorded samples and updates the Data in
+// the dataset with the recorded samples.  The format of the file is
+// selected by its extension: files ending in ".json" or ".jsonl" are
+// read with ReplayJSON, files ending in ".csv" are read with
+// ReplayCSV, and all other files are read with ReplayBinary.  If the
+// file name has an additional ".gz" extension, the file is
+// transparently decompressed.  As with ReplayJSON, Data not already
+// present in the dataset will be created.
+func ReplayFile(name string, dataset map[string]*Data) error {
+	f, err := os.Open(name) //nolint:gosec
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck
+
+	// Decompress the file if necessary
+	var r io.Reader = f
+	base := strings.TrimSuffix(name, gzipExt)
+	if base != name {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		r = gz
+	}
+
+	// Select the format
+	switch filepath.Ext(base) {
+	case ".json", ".jsonl":
+		return ReplayJSON(r, dataset)
+
+	case ".csv":
+		return ReplayCSV(r, dataset)
+	}
+
+	return ReplayBinary(r, dataset)
+}
@@ -0,0 +1,287 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordReplayExisting(t *testing.T) {
+	d := &Data{Flags: StdDev}
+	dataset := map[string]*Data{"timer": d}
+	rec := &Record{
+		Timestamp: time.Unix(1000, 0),
+		Duration:  time.Duration(50),
+		Name:      "timer",
+	}
+
+	rec.replay(dataset)
+
+	assert.Equal(t, map[string]*Data{
+		"timer": {
+			Samples: 1,
+			Mean:    time.Duration(50),
+			Max:     time.Duration(50),
+			Min:     time.Duration(50),
+			Flags:   StdDev,
+		},
+	}, dataset)
+	assert.Same(t, d, dataset["timer"])
+}
+
+func TestRecordReplayTimestamp(t *testing.T) {
+	d := &Data{Retain: 1}
+	dataset := map[string]*Data{"timer": d}
+	rec := &Record{
+		Timestamp: time.Unix(1000, 0),
+		Duration:  time.Duration(50),
+		Name:      "timer",
+	}
+
+	rec.replay(dataset)
+
+	assert.Equal(t, []Sample{
+		{At: time.Unix(1000, 0), Duration: 50},
+	}, d.Retained())
+}
+
+func TestRecordReplayNew(t *testing.T) {
+	dataset := map[string]*Data{}
+	rec := &Record{
+		Timestamp: time.Unix(1000, 0),
+		Duration:  time.Duration(50),
+		Name:      "timer",
+	}
+
+	rec.replay(dataset)
+
+	assert.Equal(t, map[string]*Data{
+		"timer": {
+			Samples: 1,
+			Mean:    time.Duration(50),
+			Max:     time.Duration(50),
+			Min:     time.Duration(50),
+		},
+	}, dataset)
+}
+
+func TestReplayJSONBase(t *testing.T) {
+	text := bytes.NewBufferString(`{"timestamp": "2020-01-01T00:00:00Z", "duration": 50, "name": "t1"}
+
+{"timestamp": "2020-01-01T00:00:01Z", "duration": 25, "name": "t1"}
+{"timestamp": "2020-01-01T00:00:02Z", "duration": 75, "name": "t2"}
+`)
+	dataset := map[string]*Data{}
+
+	err := ReplayJSON(text, dataset)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*Data{
+		"t1": {
+			Samples: 2,
+			Mean:    time.Duration(38),
+			Max:     time.Duration(50),
+			Min:     time.Duration(25),
+			m2:      time.Duration(325),
+		},
+		"t2": {
+			Samples: 1,
+			Mean:    time.Duration(75),
+			Max:     time.Duration(75),
+			Min:     time.Duration(75),
+		},
+	}, dataset)
+}
+
+func TestReplayJSONBadRecord(t *testing.T) {
+	text := bytes.NewBufferString(`{"timestamp": "2020-01-01T00:00:00Z", "duration": 50, "name": "t1"}
+{"timestamp": "2020-01-01T00:00:01Z", "duration": "25", "name": "t1"}
+`)
+	dataset := map[string]*Data{}
+
+	err := ReplayJSON(text, dataset)
+
+	assert.ErrorIs(t, err, ErrBadRecord)
+	assert.Contains(t, err.Error(), "line 2")
+	assert.Equal(t, int64(1), dataset["t1"].Samples)
+}
+
+func TestParseDurationInteger(t *testing.T) {
+	result, err := parseDuration("50")
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(50), result)
+}
+
+func TestParseDurationString(t *testing.T) {
+	result, err := parseDuration("1.5ms")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1500*time.Microsecond, result)
+}
+
+func TestParseDurationError(t *testing.T) {
+	_, err := parseDuration("bogus")
+
+	assert.Error(t, err)
+}
+
+func TestReplayCSVBase(t *testing.T) {
+	text := strings.NewReader(`timestamp,duration,name
+2020-01-01T00:00:00Z,50,t1
+2020-01-01T00:00:01Z, 25ns, t1
+2020-01-01T00:00:02Z,75,t2
+`)
+	dataset := map[string]*Data{}
+
+	err := ReplayCSV(text, dataset)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*Data{
+		"t1": {
+			Samples: 2,
+			Mean:    time.Duration(38),
+			Max:     time.Duration(50),
+			Min:     time.Duration(25),
+			m2:      time.Duration(325),
+		},
+		"t2": {
+			Samples: 1,
+			Mean:    time.Duration(75),
+			Max:     time.Duration(75),
+			Min:     time.Duration(75),
+		},
+	}, dataset)
+}
+
+func TestReplayCSVNoHeader(t *testing.T) {
+	text := strings.NewReader(`2020-01-01T00:00:00Z,50,t1
+`)
+	dataset := map[string]*Data{}
+
+	err := ReplayCSV(text, dataset)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), dataset["t1"].Samples)
+}
+
+func TestReplayCSVBadFields(t *testing.T) {
+	text := strings.NewReader(`2020-01-01T00:00:00Z,50
+`)
+	dataset := map[string]*Data{}
+
+	err := ReplayCSV(text, dataset)
+
+	assert.ErrorIs(t, err, ErrBadRecord)
+	assert.Equal(t, map[string]*Data{}, dataset)
+}
+
+func TestReplayCSVBadTimestamp(t *testing.T) {
+	text := strings.NewReader(`yesterday,50,t1
+`)
+	dataset := map[string]*Data{}
+
+	err := ReplayCSV(text, dataset)
+
+	assert.ErrorIs(t, err, ErrBadRecord)
+	assert.Contains(t, err.Error(), "line 1")
+	assert.Equal(t, map[string]*Data{}, dataset)
+}
+
+func TestReplayCSVBadDuration(t *testing.T) {
+	text := strings.NewReader(`2020-01-01T00:00:00Z,forever,t1
+`)
+	dataset := map[string]*Data{}
+
+	err := ReplayCSV(text, dataset)
+
+	assert.ErrorIs(t, err, ErrBadRecord)
+	assert.Contains(t, err.Error(), "line 1")
+	assert.Equal(t, map[string]*Data{}, dataset)
+}
+
+func TestReplayFileJSON(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "samples.jsonl")
+	require.NoError(t, os.WriteFile(name, []byte(`{"timestamp":"2020-01-01T00:00:00Z","duration":50,"name":"timer"}
+`), 0o600))
+	dataset := map[string]*Data{}
+
+	err := ReplayFile(name, dataset)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), dataset["timer"].Samples)
+}
+
+func TestReplayFileCSV(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "samples.csv")
+	require.NoError(t, os.WriteFile(name, []byte("2020-01-01T00:00:00Z,50,timer\n"), 0o600))
+	dataset := map[string]*Data{}
+
+	err := ReplayFile(name, dataset)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), dataset["timer"].Samples)
+}
+
+func TestReplayFileBinary(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "samples")
+	require.NoError(t, os.WriteFile(name, (&Record{Timestamp: time.Unix(1000, 0), Duration: 50, Name: "timer"}).frame(), 0o600))
+	dataset := map[string]*Data{}
+
+	err := ReplayFile(name, dataset)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), dataset["timer"].Samples)
+}
+
+func TestReplayFileCompressed(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "samples.csv.gz")
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	_, err := gz.Write([]byte("2020-01-01T00:00:00Z,50,timer\n"))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	require.NoError(t, os.WriteFile(name, buf.Bytes(), 0o600))
+	dataset := map[string]*Data{}
+
+	err = ReplayFile(name, dataset)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), dataset["timer"].Samples)
+}
+
+func TestReplayFileMissing(t *testing.T) {
+	err := ReplayFile(filepath.Join(t.TempDir(), "missing"), map[string]*Data{})
+
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestReplayFileBadCompression(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "samples.gz")
+	require.NoError(t, os.WriteFile(name, []byte("not compressed"), 0o600))
+
+	err := ReplayFile(name, map[string]*Data{})
+
+	assert.ErrorIs(t, err, gzip.ErrHeader)
+}
@@ -0,0 +1,241 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"time"
+)
+
+// Snapshot is a point-in-time view of a set of named Data, along with
+// the values of any counters, gauges, and derived values reported
+// with them.  A Snapshot may be marshaled as JSON or YAML to export
+// all of these together.
+type Snapshot struct {
+	Time     time.Time          `json:"time" yaml:"time"`                             // The time the snapshot was taken
+	Data     map[string]*Data   `json:"data" yaml:"data"`                             // The data; must not be modified
+	Counters map[string]int64   `json:"counters,omitempty" yaml:"counters,omitempty"` // Counter values
+	Gauges   map[string]float64 `json:"gauges,omitempty" yaml:"gauges,omitempty"`     // Gauge values
+	Derived  map[string]float64 `json:"derived,omitempty" yaml:"derived,omitempty"`   // Derived values
+}
+
+// Sink describes a destination for snapshots emitted by a Reporter.
+type Sink interface {
+	// Emit emits the snapshot.
+	Emit(snap *Snapshot) error
+}
+
+// Flusher is implemented by sinks that buffer snapshots, such as a
+// PolicySink with BufferOnFailure, so that the buffered snapshots can
+// be emitted before the application exits; see Reporter.Shutdown.
+type Flusher interface {
+	// Flush emits any buffered snapshots, giving up when the
+	// context is done.
+	Flush(ctx context.Context) error
+}
+
+// ContextSink is implemented by sinks whose Emit may block, such as a
+// PolicySink with BlockOnFailure, so that a Reporter can stop waiting
+// for them when its context is done; see Reporter.ReportContext.
+type ContextSink interface {
+	// EmitContext emits the snapshot, giving up when the context
+	// is done.
+	EmitContext(ctx context.Context, snap *Snapshot) error
+}
+
+// SinkFunc is an adaptor allowing an ordinary function to be used as
+// a Sink.
+type SinkFunc func(snap *Snapshot) error
+
+// Emit emits the snapshot.
+func (f SinkFunc) Emit(snap *Snapshot) error {
+	return f(snap)
+}
+
+// WriterSink is a Sink that writes each snapshot to a writer using an
+// Encoder.  Since Encoders only encode Data, counters, gauges, and
+// derived values are not written; use a SinkFunc marshaling the
+// entire Snapshot to include them.
+type WriterSink struct {
+	Writer  io.Writer // The writer to write to
+	Encoder Encoder   // The encoder to use
+}
+
+// Emit emits the snapshot.
+func (ws *WriterSink) Emit(snap *Snapshot) error {
+	return ws.Encoder.Encode(ws.Writer, snap.Data)
+}
+
+// LoggerSink is a Sink that logs a summary line for each Data,
+// counter, gauge, and derived value in the snapshot.
+type LoggerSink struct {
+	Logger *log.Logger // The logger to use; nil for the standard logger
+}
+
+// Emit emits the snapshot.
+func (ls *LoggerSink) Emit(snap *Snapshot) error {
+	logger := ls.Logger
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	names := make([]string, 0, len(snap.Data))
+	for name := range snap.Data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		d := snap.Data[name]
+		logger.Printf("%s: samples=%d mean=%s min=%s max=%s", name, d.Samples, d.Mean, d.Min, d.Max)
+	}
+
+	names = names[:0]
+	for name := range snap.Counters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		logger.Printf("%s: count=%d", name, snap.Counters[name])
+	}
+
+	logValues(logger, snap.Gauges)
+	logValues(logger, snap.Derived)
+
+	return nil
+}
+
+// logValues logs a line for each of a set of named values, sorted by
+// name.
+func logValues(logger *log.Logger, values map[string]float64) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		logger.Printf("%s: value=%g", name, values[name])
+	}
+}
+
+// Reporter periodically takes snapshots of a set of named Data and
+// emits them to a set of sinks, along with the values of any
+// counters, gauges, and derived values.  Since Data is not safe for
+// concurrent use, the Source function is responsible for returning
+// data that may safely be read while the application continues to
+// update its Data, e.g., copies made while holding a lock.  Derived
+// values are computed after the rest of the snapshot is taken.
+type Reporter struct {
+	Interval time.Duration           // Interval between reports; 0 for default
+	Source   func() map[string]*Data // Source of the data to report
+	Counters map[string]*Counter     // Counters to report; may be nil
+	Gauges   map[string]*Gauge       // Gauges to report; may be nil
+	Derived  map[string]Derivation   // Derived values to report; may be nil
+	Sinks    []Sink                  // Sinks to emit snapshots to
+	OnError  func(err error)         // Called on sink errors; may be nil
+	Clock    Clock                   // Source of time; nil for WallClock
+}
+
+// clock returns the Clock to use for the Reporter.
+func (r *Reporter) clock() Clock {
+	if r.Clock == nil {
+		return WallClock
+	}
+
+	return r.Clock
+}
+
+// forEach invokes a function on each of the sinks.  Each error is
+// passed to OnError, if set; the first error is returned.
+func (r *Reporter) forEach(fn func(sink Sink) error) error {
+	var first error
+	for i, sink := range r.Sinks {
+		if err := fn(sink); err != nil {
+			err = fmt.Errorf("sink %d: %w", i, err)
+			if r.OnError != nil {
+				r.OnError(err)
+			}
+			if first == nil {
+				first = err
+			}
+		}
+	}
+
+	return first
+}
+
+// Report takes a snapshot and emits it to all the sinks.  Each sink
+// error is passed to OnError, if set; the first error is returned.
+func (r *Reporter) Report() error {
+	return r.ReportContext(context.Background())
+}
+
+// ReportContext is similar to Report, but sinks that implement
+// ContextSink give up emitting the snapshot when the context is done.
+func (r *Reporter) ReportContext(ctx context.Context) error {
+	snap := &Snapshot{
+		Time:     r.clock().Now(),
+		Data:     r.Source(),
+		Counters: counterValues(r.Counters),
+		Gauges:   gaugeValues(r.Gauges),
+	}
+	snap.Derived = derivedValues(snap, r.Derived)
+
+	return r.forEach(func(sink Sink) error {
+		if cs, ok := sink.(ContextSink); ok {
+			return cs.EmitContext(ctx, snap)
+		}
+		return sink.Emit(snap)
+	})
+}
+
+// Shutdown emits a final snapshot to all the sinks, then flushes
+// those sinks that implement Flusher, so that the last interval of
+// data is not lost when the application exits.  It is intended to be
+// called after Run returns, with a context bounding how long the
+// application is willing to wait; the context also bounds the final
+// snapshot, as for ReportContext.  As with Report, each error is
+// passed to OnError, if set; the first error is returned.
+func (r *Reporter) Shutdown(ctx context.Context) error {
+	err := r.ReportContext(ctx)
+	if flushErr := r.forEach(func(sink Sink) error {
+		if f, ok := sink.(Flusher); ok {
+			return f.Flush(ctx)
+		}
+		return nil
+	}); err == nil {
+		err = flushErr
+	}
+
+	return err
+}
+
+// Run reports at the configured interval until the context is
+// canceled, then returns the context's error.  The first report is
+// made immediately.  Each report is made with ReportContext, so that
+// a blocked sink does not delay Run's return once the context is
+// canceled.
+func (r *Reporter) Run(ctx context.Context) error {
+	p := &Periodic{Interval: r.Interval, Clock: r.Clock}
+
+	return p.Run(ctx, func() {
+		_ = r.ReportContext(ctx)
+	})
+}
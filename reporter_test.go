@@ -0,0 +1,376 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSinkFuncImplementsSink(t *testing.T) {
+	assert.Implements(t, (*Sink)(nil), SinkFunc(func(*Snapshot) error { return nil }))
+}
+
+func TestSinkFuncEmit(t *testing.T) {
+	snap := &Snapshot{}
+	f := SinkFunc(func(s *Snapshot) error {
+		assert.Same(t, snap, s)
+		return assert.AnError
+	})
+
+	err := f.Emit(snap)
+
+	assert.Same(t, assert.AnError, err)
+}
+
+func TestWriterSinkEmit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ws := &WriterSink{
+		Writer:  buf,
+		Encoder: EncoderFunc(WriteBenchmarks),
+	}
+
+	err := ws.Emit(&Snapshot{Data: map[string]*Data{
+		"a": {Samples: 30, Mean: 40},
+	}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "BenchmarkA\t30\t40 ns/op\n", buf.String())
+}
+
+func TestLoggerSinkEmitBase(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ls := &LoggerSink{Logger: log.New(buf, "", 0)}
+
+	err := ls.Emit(&Snapshot{Data: map[string]*Data{
+		"b": {Samples: 1, Mean: 10, Min: 10, Max: 10},
+		"a": {Samples: 3, Mean: 50, Min: 25, Max: 75},
+	}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, `a: samples=3 mean=50ns min=25ns max=75ns
+b: samples=1 mean=10ns min=10ns max=10ns
+`, buf.String())
+}
+
+func TestLoggerSinkEmitMetrics(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ls := &LoggerSink{Logger: log.New(buf, "", 0)}
+
+	err := ls.Emit(&Snapshot{
+		Data:     map[string]*Data{"a": {Samples: 1, Mean: 10, Min: 10, Max: 10}},
+		Counters: map[string]int64{"requests": 5, "errors": 1},
+		Gauges:   map[string]float64{"depth": 2.5},
+		Derived:  map[string]float64{"error_rate": 0.2},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, `a: samples=1 mean=10ns min=10ns max=10ns
+errors: count=1
+requests: count=5
+depth: value=2.5
+error_rate: value=0.2
+`, buf.String())
+}
+
+func TestLoggerSinkEmitDefault(t *testing.T) {
+	buf := &bytes.Buffer{}
+	orig := log.Writer()
+	flags := log.Flags()
+	defer func() {
+		log.SetOutput(orig)
+		log.SetFlags(flags)
+	}()
+	log.SetOutput(buf)
+	log.SetFlags(0)
+	ls := &LoggerSink{}
+
+	err := ls.Emit(&Snapshot{Data: map[string]*Data{
+		"a": {Samples: 3, Mean: 50, Min: 25, Max: 75},
+	}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "a: samples=3 mean=50ns min=25ns max=75ns\n", buf.String())
+}
+
+func TestReporterClockDefault(t *testing.T) {
+	r := &Reporter{}
+
+	result := r.clock()
+
+	assert.Equal(t, WallClock, result)
+}
+
+func TestReporterClockSet(t *testing.T) {
+	clock := &testClock{}
+	r := &Reporter{Clock: clock}
+
+	result := r.clock()
+
+	assert.Same(t, clock, result)
+}
+
+func TestReporterReportClock(t *testing.T) {
+	var snap *Snapshot
+	r := &Reporter{
+		Source: func() map[string]*Data { return nil },
+		Sinks: []Sink{
+			SinkFunc(func(s *Snapshot) error {
+				snap = s
+				return nil
+			}),
+		},
+		Clock: &FakeClock{Time: time.Unix(1000, 0)},
+	}
+
+	err := r.Report()
+
+	assert.NoError(t, err)
+	require.NotNil(t, snap)
+	assert.Equal(t, time.Unix(1000, 0), snap.Time)
+}
+
+func TestReporterReportBase(t *testing.T) {
+	dataset := map[string]*Data{"a": {Samples: 1}}
+	var snaps []*Snapshot
+	r := &Reporter{
+		Source: func() map[string]*Data { return dataset },
+		Sinks: []Sink{
+			SinkFunc(func(s *Snapshot) error {
+				snaps = append(snaps, s)
+				return nil
+			}),
+			SinkFunc(func(s *Snapshot) error {
+				snaps = append(snaps, s)
+				return nil
+			}),
+		},
+	}
+
+	err := r.Report()
+
+	assert.NoError(t, err)
+	require.Len(t, snaps, 2)
+	assert.Same(t, snaps[0], snaps[1])
+	assert.Equal(t, dataset, snaps[0].Data)
+	assert.False(t, snaps[0].Time.IsZero())
+}
+
+func TestReporterReportMetrics(t *testing.T) {
+	requests := &Counter{}
+	requests.Add(5)
+	depth := &Gauge{}
+	depth.Set(2.5)
+	perDepth := func(s *Snapshot) (float64, bool) {
+		return float64(s.Counters["requests"]) / s.Gauges["depth"], true
+	}
+	var snap *Snapshot
+	r := &Reporter{
+		Source:   func() map[string]*Data { return nil },
+		Counters: map[string]*Counter{"requests": requests},
+		Gauges:   map[string]*Gauge{"depth": depth},
+		Derived:  map[string]Derivation{"per_depth": perDepth},
+		Sinks: []Sink{
+			SinkFunc(func(s *Snapshot) error {
+				snap = s
+				return nil
+			}),
+		},
+	}
+
+	err := r.Report()
+
+	assert.NoError(t, err)
+	require.NotNil(t, snap)
+	assert.Equal(t, map[string]int64{"requests": 5}, snap.Counters)
+	assert.Equal(t, map[string]float64{"depth": 2.5}, snap.Gauges)
+	assert.Equal(t, map[string]float64{"per_depth": 2}, snap.Derived)
+}
+
+func TestSnapshotMarshalJSON(t *testing.T) {
+	snap := &Snapshot{
+		Time:     time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Data:     map[string]*Data{"a": {Samples: 1, Mean: 10, Max: 10, Min: 10, Flags: StdDev}},
+		Counters: map[string]int64{"requests": 5},
+		Gauges:   map[string]float64{"depth": 2.5},
+	}
+
+	result, err := json.Marshal(snap)
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+	"time": "2020-01-02T03:04:05Z",
+	"data": {"a": {"samples": 1, "mean": 10, "max": 10, "min": 10, "std_dev": 0}},
+	"counters": {"requests": 5},
+	"gauges": {"depth": 2.5}
+}`, string(result))
+}
+
+func TestReporterReportErrors(t *testing.T) {
+	var errs []error
+	r := &Reporter{
+		Source: func() map[string]*Data { return nil },
+		Sinks: []Sink{
+			SinkFunc(func(s *Snapshot) error { return assert.AnError }),
+			SinkFunc(func(s *Snapshot) error { return nil }),
+			SinkFunc(func(s *Snapshot) error { return assert.AnError }),
+		},
+		OnError: func(err error) { errs = append(errs, err) },
+	}
+
+	err := r.Report()
+
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.EqualError(t, err, "sink 0: "+assert.AnError.Error())
+	require.Len(t, errs, 2)
+	assert.EqualError(t, errs[1], "sink 2: "+assert.AnError.Error())
+}
+
+func TestReporterReportErrorNoHandler(t *testing.T) {
+	r := &Reporter{
+		Source: func() map[string]*Data { return nil },
+		Sinks: []Sink{
+			SinkFunc(func(s *Snapshot) error { return assert.AnError }),
+		},
+	}
+
+	err := r.Report()
+
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestReporterRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	count := 0
+	r := &Reporter{
+		Interval: 1,
+		Source:   func() map[string]*Data { return nil },
+		Sinks: []Sink{
+			SinkFunc(func(s *Snapshot) error {
+				count++
+				if count >= 3 {
+					cancel()
+				}
+				return nil
+			}),
+		},
+	}
+
+	err := r.Run(ctx)
+
+	assert.Same(t, context.Canceled, err)
+	assert.Equal(t, 3, count)
+}
+
+type fakeFlusher struct {
+	SinkFunc
+	err error
+}
+
+func (ff *fakeFlusher) Flush(ctx context.Context) error {
+	return ff.err
+}
+
+func TestReporterShutdownBase(t *testing.T) {
+	emitted := 0
+	sink := &fakeSink{failures: 1}
+	r := &Reporter{
+		Source: func() map[string]*Data { return nil },
+		Sinks: []Sink{
+			SinkFunc(func(s *Snapshot) error {
+				emitted++
+				return nil
+			}),
+			&PolicySink{
+				Sink:       sink,
+				Policy:     BufferOnFailure,
+				Buffer:     2,
+				RetryDelay: time.Millisecond,
+				pending:    []*Snapshot{{}},
+			},
+		},
+	}
+
+	err := r.Shutdown(context.Background())
+
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, 1, emitted)
+	assert.Len(t, sink.emitted, 2)
+}
+
+func TestReporterShutdownFlushError(t *testing.T) {
+	var errs []error
+	r := &Reporter{
+		Source: func() map[string]*Data { return nil },
+		Sinks: []Sink{
+			&fakeFlusher{
+				SinkFunc: func(s *Snapshot) error { return nil },
+				err:      assert.AnError,
+			},
+		},
+		OnError: func(err error) { errs = append(errs, err) },
+	}
+
+	err := r.Shutdown(context.Background())
+
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.EqualError(t, err, "sink 0: "+assert.AnError.Error())
+	assert.Len(t, errs, 1)
+}
+
+func TestReporterShutdownReportError(t *testing.T) {
+	r := &Reporter{
+		Source: func() map[string]*Data { return nil },
+		Sinks: []Sink{
+			&fakeFlusher{
+				SinkFunc: func(s *Snapshot) error { return assert.AnError },
+				err:      context.Canceled,
+			},
+		},
+	}
+
+	err := r.Shutdown(context.Background())
+
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestReporterShutdownBlocked(t *testing.T) {
+	sink := &fakeSink{failures: 1000}
+	r := &Reporter{
+		Source: func() map[string]*Data { return nil },
+		Sinks: []Sink{
+			&PolicySink{
+				Sink:       sink,
+				Policy:     BlockOnFailure,
+				RetryDelay: time.Millisecond,
+			},
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := r.Shutdown(ctx)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, sink.emitted)
+}
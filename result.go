@@ -0,0 +1,137 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Result bundles timing data with the metadata needed to interpret
+// it: a description of the environment in which it was collected and
+// a set of labels, such as the backend or configuration being
+// measured.  A Result may be marshaled to JSON or YAML as a single
+// self-describing artifact.
+type Result struct {
+	Name    string            `json:"name" yaml:"name"`
+	Data    *Data             `json:"data" yaml:"data"`
+	RunInfo *RunInfo          `json:"run_info,omitempty" yaml:"run_info,omitempty"`
+	Labels  map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+}
+
+// NewResult constructs a Result for the named Data, capturing a
+// description of the current environment with CaptureRunInfo.
+func NewResult(name string, d *Data) *Result {
+	return &Result{
+		Name:    name,
+		Data:    d,
+		RunInfo: CaptureRunInfo(),
+		Labels:  map[string]string{},
+	}
+}
+
+// Dataset returns the Result's Data as a dataset, suitable for
+// passing to an Encoder.
+func (r *Result) Dataset() map[string]*Data {
+	return map[string]*Data{r.Name: r.Data}
+}
+
+// configKey converts a label name into a configuration key for the
+// Go benchmark format by lower-casing it and replacing white space
+// and colons with hyphens, so that "Host Name" becomes "host-name".
+// Returns "" if the result is still not a valid key, e.g., because
+// it does not begin with a letter.
+func configKey(label string) string {
+	key := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == ':' {
+			return '-'
+		}
+		return unicode.ToLower(r)
+	}, label)
+	if !validConfigKey(key) {
+		return ""
+	}
+
+	return key
+}
+
+// Config returns the Result's metadata as a set of configuration
+// keys suitable for passing to WriteBenchmarkConfig.  This consists
+// of the RunInfo configuration, if any, and the labels; labels take
+// precedence over the RunInfo.  Label names are lower-cased, with
+// white space and colons replaced by hyphens, to form valid keys;
+// labels that still do not form a valid key, such as those not
+// beginning with a letter, are omitted.
+func (r *Result) Config() map[string]string {
+	config := map[string]string{}
+	if r.RunInfo != nil {
+		config = r.RunInfo.Config()
+	}
+	for label, value := range r.Labels {
+		if key := configKey(label); key != "" {
+			config[key] = value
+		}
+	}
+
+	return config
+}
+
+// encodeResultJSON encodes the Result as a JSON object.
+func encodeResultJSON(w io.Writer, r *Result) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+// encodeResultYAML encodes the Result as a YAML document.
+func encodeResultYAML(w io.Writer, r *Result) error {
+	enc := yaml.NewEncoder(w)
+	defer enc.Close()
+
+	return enc.Encode(r)
+}
+
+// encodeResultBenchmark encodes the Result in the Go benchmark
+// format, preceding the benchmark line with configuration lines
+// describing the metadata.
+func encodeResultBenchmark(w io.Writer, r *Result) error {
+	if err := WriteBenchmarkConfig(w, r.Config()); err != nil {
+		return err
+	}
+
+	return WriteBenchmarks(w, r.Dataset())
+}
+
+// Report writes the Result to the writer in the named output format.
+// If the format's Encoder implements ResultEncoder, as the default
+// formats do, it encodes the full Result: the "json" and "yaml"
+// formats include the metadata, and the "benchmark" format precedes
+// the benchmark line with configuration lines describing the
+// metadata.  Other formats write only the Data, as for a dataset.
+// An error wrapping ErrUnknownFormat is returned if the format has
+// not been registered.
+func (r *Result) Report(w io.Writer, format string) error {
+	enc, err := LookupFormat(format)
+	if err != nil {
+		return err
+	}
+	if re, ok := enc.(ResultEncoder); ok {
+		return re.EncodeResult(w, r)
+	}
+
+	return enc.Encode(w, r.Dataset())
+}
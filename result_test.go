@@ -0,0 +1,266 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+// testResult returns a Result for testing.
+func testResult() *Result {
+	return &Result{
+		Name: "a",
+		Data: &Data{
+			Samples: 3,
+			Mean:    50,
+			Max:     75,
+			Min:     25,
+			Flags:   StdDev,
+			m2:      1250,
+		},
+		RunInfo: &RunInfo{
+			GoVersion:  "go1.17",
+			GOOS:       "linux",
+			GOARCH:     "amd64",
+			NumCPU:     4,
+			GOMAXPROCS: 4,
+			Timestamp:  time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		Labels: map[string]string{
+			"backend": "memory",
+			"goos":    "plan9",
+		},
+	}
+}
+
+func TestNewResult(t *testing.T) {
+	d := &Data{}
+
+	result := NewResult("a", d)
+
+	assert.Equal(t, "a", result.Name)
+	assert.Same(t, d, result.Data)
+	assert.NotNil(t, result.RunInfo)
+	assert.Equal(t, map[string]string{}, result.Labels)
+}
+
+func TestResultDataset(t *testing.T) {
+	r := testResult()
+
+	result := r.Dataset()
+
+	assert.Equal(t, map[string]*Data{"a": r.Data}, result)
+	assert.Same(t, r.Data, result["a"])
+}
+
+func TestResultConfigBase(t *testing.T) {
+	r := testResult()
+
+	result := r.Config()
+
+	assert.Equal(t, map[string]string{
+		"backend":    "memory",
+		"date":       "2020-01-01T00:00:00Z",
+		"go":         "go1.17",
+		"goarch":     "amd64",
+		"gomaxprocs": "4",
+		"goos":       "plan9",
+		"numcpu":     "4",
+	}, result)
+}
+
+func TestResultConfigNoRunInfo(t *testing.T) {
+	r := testResult()
+	r.RunInfo = nil
+
+	result := r.Config()
+
+	assert.Equal(t, map[string]string{
+		"backend": "memory",
+		"goos":    "plan9",
+	}, result)
+}
+
+func TestResultConfigLabelConverted(t *testing.T) {
+	r := testResult()
+	r.RunInfo = nil
+	r.Labels["Host Name"] = "db1"
+	r.Labels["Cache:Size"] = "large"
+
+	result := r.Config()
+
+	assert.Equal(t, map[string]string{
+		"backend":    "memory",
+		"cache-size": "large",
+		"goos":       "plan9",
+		"host-name":  "db1",
+	}, result)
+}
+
+func TestResultConfigLabelInvalid(t *testing.T) {
+	r := testResult()
+	r.RunInfo = nil
+	r.Labels["9 Lives"] = "cat"
+	r.Labels[""] = "empty"
+
+	result := r.Config()
+
+	assert.Equal(t, map[string]string{
+		"backend": "memory",
+		"goos":    "plan9",
+	}, result)
+}
+
+func TestResultReportJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	r := testResult()
+
+	err := r.Report(buf, "json")
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": "a",
+		"data": {"samples": 3, "mean": 50, "max": 75, "min": 25, "std_dev": 20},
+		"run_info": {
+			"go_version": "go1.17",
+			"goos": "linux",
+			"goarch": "amd64",
+			"num_cpu": 4,
+			"gomaxprocs": 4,
+			"timestamp": "2020-01-01T00:00:00Z"
+		},
+		"labels": {"backend": "memory", "goos": "plan9"}
+	}`, buf.String())
+	rt := &Result{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), rt))
+	assert.Equal(t, r.Labels, rt.Labels)
+	assert.Equal(t, r.Data.Samples, rt.Data.Samples)
+}
+
+func TestResultReportYAML(t *testing.T) {
+	buf := &bytes.Buffer{}
+	r := testResult()
+
+	err := r.Report(buf, "yaml")
+
+	require.NoError(t, err)
+	assert.Equal(t, `name: a
+data:
+  samples: 3
+  mean: 50ns
+  max: 75ns
+  min: 25ns
+  std_dev: 20ns
+run_info:
+  go_version: go1.17
+  goos: linux
+  goarch: amd64
+  num_cpu: 4
+  gomaxprocs: 4
+  timestamp: 2020-01-01T00:00:00Z
+labels:
+  backend: memory
+  goos: plan9
+`, buf.String())
+	rt := &Result{}
+	require.NoError(t, yaml.Unmarshal(buf.Bytes(), rt))
+	assert.Equal(t, r.Labels, rt.Labels)
+	assert.Equal(t, r.Data.Samples, rt.Data.Samples)
+}
+
+func TestResultReportBenchmark(t *testing.T) {
+	buf := &bytes.Buffer{}
+	r := testResult()
+
+	err := r.Report(buf, "benchmark")
+
+	require.NoError(t, err)
+	assert.Equal(t, `backend: memory
+date: 2020-01-01T00:00:00Z
+go: go1.17
+goarch: amd64
+gomaxprocs: 4
+goos: plan9
+numcpu: 4
+BenchmarkA	3	50 ns/op
+`, buf.String())
+}
+
+func TestResultReportBenchmarkLabelConverted(t *testing.T) {
+	buf := &bytes.Buffer{}
+	r := testResult()
+	r.RunInfo = nil
+	r.Labels["Host Name"] = "db1"
+
+	err := r.Report(buf, "benchmark")
+
+	assert.NoError(t, err)
+	assert.Equal(t, `backend: memory
+goos: plan9
+host-name: db1
+BenchmarkA	3	50 ns/op
+`, buf.String())
+}
+
+func TestResultReportOther(t *testing.T) {
+	defer func() {
+		formatsMu.Lock()
+		defer formatsMu.Unlock()
+		delete(formats, "test")
+	}()
+	RegisterFormat("test", EncoderFunc(encodeJSON))
+	buf := &bytes.Buffer{}
+	r := testResult()
+
+	err := r.Report(buf, "test")
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"a": {"samples": 3, "mean": 50, "max": 75, "min": 25, "std_dev": 20}}`, buf.String())
+}
+
+func TestResultReportUnknown(t *testing.T) {
+	buf := &bytes.Buffer{}
+	r := testResult()
+
+	err := r.Report(buf, "unknown")
+
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestResultReportReplaced(t *testing.T) {
+	orig, err := LookupFormat("json")
+	require.NoError(t, err)
+	defer RegisterFormat("json", orig)
+	RegisterFormat("json", EncoderFunc(func(w io.Writer, dataset map[string]*Data) error {
+		_, err := fmt.Fprintf(w, "%d timers\n", len(dataset))
+		return err
+	}))
+	buf := &bytes.Buffer{}
+	r := testResult()
+
+	err = r.Report(buf, "json")
+
+	require.NoError(t, err)
+	assert.Equal(t, "1 timers\n", buf.String())
+}
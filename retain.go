@@ -0,0 +1,73 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"sort"
+	"time"
+)
+
+// Sample describes a single retained sample, along with the time at
+// which it was taken and any tag associated with it.
+type Sample struct {
+	At       time.Time     // When the sample was taken
+	Duration time.Duration // The sample itself
+	Tag      interface{}   // Opaque tag, such as a request ID
+	Items    int64         // Items processed; see UpdateThroughput
+	Bytes    int64         // Bytes processed; see UpdateThroughput
+}
+
+// retain records a sample in the Data's retention buffer, if
+// retention is enabled.  If the sample's timestamp is the zero time,
+// the Data's Clock is consulted.
+func (d *Data) retain(s Sample) {
+	if d.Retain <= 0 {
+		return
+	}
+	if s.At.IsZero() {
+		s.At = d.clock().Now()
+	}
+
+	// Append until the buffer is full, then overwrite the oldest
+	if len(d.retained) < d.Retain {
+		d.retained = append(d.retained, s)
+		return
+	}
+	d.retainPos %= len(d.retained)
+	d.retained[d.retainPos] = s
+	d.retainPos++
+}
+
+// Retained returns the samples currently held in the retention
+// buffer, ordered by the time at which they were taken.  Retention is
+// enabled by setting Retain to the number of samples to keep; once
+// the buffer is full, the oldest samples are discarded.  Unlike the
+// summary statistics, the retained samples preserve the time
+// structure of the data, so bursts and periodic slowdowns can be
+// identified.
+func (d *Data) Retained() []Sample {
+	result := make([]Sample, 0, len(d.retained))
+	if d.retainPos < len(d.retained) {
+		result = append(result, d.retained[d.retainPos:]...)
+	}
+	result = append(result, d.retained[:d.retainPos]...)
+
+	// Samples may be supplied out of order, e.g., by UpdateAt
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].At.Before(result[j].At)
+	})
+
+	return result
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataRetainDisabled(t *testing.T) {
+	d := &Data{}
+
+	d.retain(Sample{At: time.Unix(1000, 0), Duration: 50})
+
+	assert.Nil(t, d.retained)
+}
+
+func TestDataRetainAppend(t *testing.T) {
+	d := &Data{Retain: 3}
+
+	d.retain(Sample{At: time.Unix(1000, 0), Duration: 50})
+	d.retain(Sample{At: time.Unix(1001, 0), Duration: 60})
+
+	assert.Equal(t, []Sample{
+		{At: time.Unix(1000, 0), Duration: 50},
+		{At: time.Unix(1001, 0), Duration: 60},
+	}, d.retained)
+	assert.Equal(t, 0, d.retainPos)
+}
+
+func TestDataRetainWrap(t *testing.T) {
+	d := &Data{Retain: 2}
+
+	d.retain(Sample{At: time.Unix(1000, 0), Duration: 50})
+	d.retain(Sample{At: time.Unix(1001, 0), Duration: 60})
+	d.retain(Sample{At: time.Unix(1002, 0), Duration: 70})
+
+	assert.Equal(t, []Sample{
+		{At: time.Unix(1002, 0), Duration: 70},
+		{At: time.Unix(1001, 0), Duration: 60},
+	}, d.retained)
+	assert.Equal(t, 1, d.retainPos)
+}
+
+func TestDataRetainShrunk(t *testing.T) {
+	d := &Data{
+		Retain: 1,
+		retained: []Sample{
+			{At: time.Unix(1000, 0), Duration: 50},
+			{At: time.Unix(1001, 0), Duration: 60},
+		},
+		retainPos: 2,
+	}
+
+	d.retain(Sample{At: time.Unix(1002, 0), Duration: 70})
+
+	assert.Equal(t, []Sample{
+		{At: time.Unix(1002, 0), Duration: 70},
+		{At: time.Unix(1001, 0), Duration: 60},
+	}, d.retained)
+	assert.Equal(t, 1, d.retainPos)
+}
+
+func TestDataRetainClock(t *testing.T) {
+	clock := &stepClock{now: time.Unix(1000, 0), step: time.Second}
+	d := &Data{Retain: 1, Clock: clock}
+
+	d.retain(Sample{Duration: 50})
+
+	assert.Equal(t, []Sample{
+		{At: time.Unix(1000, 0), Duration: 50},
+	}, d.retained)
+}
+
+func TestDataRetainedEmpty(t *testing.T) {
+	d := &Data{}
+
+	result := d.Retained()
+
+	assert.Equal(t, []Sample{}, result)
+}
+
+func TestDataRetainedOrdered(t *testing.T) {
+	d := &Data{Retain: 3}
+	d.updateSample(Sample{At: time.Unix(1000, 0), Duration: 50})
+	d.updateSample(Sample{At: time.Unix(1001, 0), Duration: 60})
+	d.updateSample(Sample{At: time.Unix(1002, 0), Duration: 70})
+	d.updateSample(Sample{At: time.Unix(1003, 0), Duration: 80})
+
+	result := d.Retained()
+
+	assert.Equal(t, []Sample{
+		{At: time.Unix(1001, 0), Duration: 60},
+		{At: time.Unix(1002, 0), Duration: 70},
+		{At: time.Unix(1003, 0), Duration: 80},
+	}, result)
+}
+
+func TestDataRetainedOutOfOrder(t *testing.T) {
+	d := &Data{Retain: 3}
+	d.updateSample(Sample{At: time.Unix(1002, 0), Duration: 70})
+	d.updateSample(Sample{At: time.Unix(1000, 0), Duration: 50})
+	d.updateSample(Sample{At: time.Unix(1001, 0), Duration: 60})
+
+	result := d.Retained()
+
+	assert.Equal(t, []Sample{
+		{At: time.Unix(1000, 0), Duration: 50},
+		{At: time.Unix(1001, 0), Duration: 60},
+		{At: time.Unix(1002, 0), Duration: 70},
+	}, result)
+}
+
+func TestDataRetainedCopy(t *testing.T) {
+	d := &Data{Retain: 1}
+	d.updateSample(Sample{At: time.Unix(1000, 0), Duration: 50})
+
+	result := d.Retained()
+	result[0].Duration = 100
+
+	assert.Equal(t, time.Duration(50), d.retained[0].Duration)
+}
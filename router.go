@@ -0,0 +1,165 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// RouteLabel returns a routing function for a Router that partitions
+// updates by the value of the specified label, such as "tenant" or
+// "region".  Updates without the label are routed to the partition
+// named by the empty string.
+func RouteLabel(label string) func(labels map[string]string) string {
+	return func(labels map[string]string) string {
+		return labels[label]
+	}
+}
+
+// Router directs updates to one of several partitions, each holding
+// its own set of named Data, based on the labels of the update.  This
+// allows a multi-tenant service to keep the timing data of each
+// tenant or region separate, with its own retention, as configured by
+// the New function, and its own sinks, as configured by the Sinks
+// function.  The partitions and their Data are created the first time
+// they are seen.  A Router may be shared by multiple goroutines;
+// updates to the Data are serialized by an internal mutex.
+type Router struct {
+	Route      func(labels map[string]string) string // Returns the partition for a set of labels
+	New        func(partition, name string) *Data    // Creates the Data for a timer; nil for an empty Data
+	Sinks      func(partition string) []Sink         // Returns the sinks for a partition; may be nil
+	Clock      Clock                                 // Source of time; nil for WallClock
+	mu         sync.Mutex
+	partitions map[string]map[string]*Data
+}
+
+// clock returns the Clock to use for the Router.
+func (r *Router) clock() Clock {
+	if r.Clock == nil {
+		return WallClock
+	}
+
+	return r.Clock
+}
+
+// update routes the sample to the named Data in the partition
+// selected by the labels.
+func (r *Router) update(labels map[string]string, name string, s Sample) {
+	if !Enabled() {
+		return
+	}
+
+	partition := r.Route(labels)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.partitions == nil {
+		r.partitions = map[string]map[string]*Data{}
+	}
+	dataset, ok := r.partitions[partition]
+	if !ok {
+		dataset = map[string]*Data{}
+		r.partitions[partition] = dataset
+	}
+	d, ok := dataset[name]
+	if !ok {
+		if r.New != nil {
+			d = r.New(partition, name)
+		} else {
+			d = &Data{}
+		}
+		dataset[name] = d
+	}
+	d.updateSample(s)
+}
+
+// Update routes a sample for the named timer to the partition
+// selected by the labels.
+func (r *Router) Update(labels map[string]string, name string, sample time.Duration) {
+	r.update(labels, name, Sample{Duration: sample})
+}
+
+// TimeIt runs a function and routes the time it took, as a sample for
+// the named timer, to the partition selected by the labels.  It
+// returns the time it took for the function to execute.
+func (r *Router) TimeIt(labels map[string]string, name string, fn func()) (delta time.Duration) {
+	if !Enabled() {
+		fn()
+		return 0
+	}
+
+	// Get the current time and arrange to update the data
+	clock := r.clock()
+	start := clock.Now()
+	defer func() {
+		delta = elapsed(clock, start)
+		r.update(labels, name, Sample{At: start, Duration: delta})
+	}()
+
+	// Invoke the function
+	fn()
+
+	return
+}
+
+// Partitions returns the sorted names of the partitions seen so far.
+func (r *Router) Partitions() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.partitions))
+	for partition := range r.partitions {
+		names = append(names, partition)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Dataset returns copies of the Data in the partition, keyed by timer
+// name; see Data.Clone.  An empty dataset is returned if the
+// partition has not been seen.
+func (r *Router) Dataset(partition string) map[string]*Data {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	dataset := r.partitions[partition]
+	result := make(map[string]*Data, len(dataset))
+	for name, d := range dataset {
+		result[name] = d.Clone()
+	}
+
+	return result
+}
+
+// Reporter returns a Reporter for the partition, whose Source is the
+// partition's dataset and whose Sinks are those returned by the Sinks
+// function, if set.  The caller may set the Interval and any other
+// fields before running the Reporter.
+func (r *Router) Reporter(partition string) *Reporter {
+	rep := &Reporter{
+		Source: func() map[string]*Data {
+			return r.Dataset(partition)
+		},
+	}
+	if r.Sinks != nil {
+		rep.Sinks = r.Sinks(partition)
+	}
+
+	return rep
+}
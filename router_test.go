@@ -0,0 +1,167 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build !timeitnoop
+// +build !timeitnoop
+
+package timeit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouteLabelBase(t *testing.T) {
+	route := RouteLabel("tenant")
+
+	assert.Equal(t, "acme", route(map[string]string{"tenant": "acme", "region": "eu"}))
+}
+
+func TestRouteLabelMissing(t *testing.T) {
+	route := RouteLabel("tenant")
+
+	assert.Equal(t, "", route(map[string]string{"region": "eu"}))
+}
+
+func TestRouterClockDefault(t *testing.T) {
+	r := &Router{}
+
+	assert.Equal(t, WallClock, r.clock())
+}
+
+func TestRouterUpdate(t *testing.T) {
+	r := &Router{Route: RouteLabel("tenant")}
+
+	r.Update(map[string]string{"tenant": "acme"}, "get", 10)
+	r.Update(map[string]string{"tenant": "acme"}, "get", 30)
+	r.Update(map[string]string{"tenant": "acme"}, "put", 50)
+	r.Update(map[string]string{"tenant": "globex"}, "get", 70)
+
+	assert.Equal(t, []string{"acme", "globex"}, r.Partitions())
+	acme := r.Dataset("acme")
+	require.Len(t, acme, 2)
+	assert.Equal(t, int64(2), acme["get"].Samples)
+	assert.Equal(t, time.Duration(20), acme["get"].Mean)
+	assert.Equal(t, int64(1), acme["put"].Samples)
+	globex := r.Dataset("globex")
+	require.Len(t, globex, 1)
+	assert.Equal(t, time.Duration(70), globex["get"].Mean)
+}
+
+func TestRouterNew(t *testing.T) {
+	created := []string{}
+	r := &Router{
+		Route: RouteLabel("tenant"),
+		New: func(partition, name string) *Data {
+			created = append(created, partition+"/"+name)
+			if partition == "acme" {
+				return &Data{Retain: 10}
+			}
+			return &Data{}
+		},
+	}
+
+	r.Update(map[string]string{"tenant": "acme"}, "get", 10)
+	r.Update(map[string]string{"tenant": "acme"}, "get", 20)
+	r.Update(map[string]string{"tenant": "globex"}, "get", 30)
+
+	assert.Equal(t, []string{"acme/get", "globex/get"}, created)
+	assert.Len(t, r.partitions["acme"]["get"].Retained(), 2)
+	assert.Empty(t, r.partitions["globex"]["get"].Retained())
+}
+
+func TestRouterTimeIt(t *testing.T) {
+	r := &Router{
+		Route: RouteLabel("tenant"),
+		Clock: &testClock{now: time.Unix(1000, 0), delta: 5},
+	}
+
+	result := r.TimeIt(map[string]string{"tenant": "acme"}, "get", func() {})
+
+	assert.Equal(t, time.Duration(5), result)
+	assert.Equal(t, int64(1), r.Dataset("acme")["get"].Samples)
+}
+
+func TestRouterDatasetUnknown(t *testing.T) {
+	r := &Router{Route: RouteLabel("tenant")}
+
+	assert.Empty(t, r.Dataset("acme"))
+}
+
+func TestRouterDatasetCopies(t *testing.T) {
+	r := &Router{Route: RouteLabel("tenant")}
+	r.Update(map[string]string{"tenant": "acme"}, "get", 10)
+
+	r.Dataset("acme")["get"].Update(1000)
+
+	assert.Equal(t, int64(1), r.Dataset("acme")["get"].Samples)
+}
+
+func TestRouterReporterBase(t *testing.T) {
+	var snaps []*Snapshot
+	r := &Router{
+		Route: RouteLabel("tenant"),
+		Sinks: func(partition string) []Sink {
+			if partition != "acme" {
+				return nil
+			}
+			return []Sink{SinkFunc(func(snap *Snapshot) error {
+				snaps = append(snaps, snap)
+				return nil
+			})}
+		},
+	}
+	r.Update(map[string]string{"tenant": "acme"}, "get", 10)
+	r.Update(map[string]string{"tenant": "globex"}, "put", 20)
+
+	err := r.Reporter("acme").Report()
+	require.NoError(t, err)
+	err = r.Reporter("globex").Report()
+	require.NoError(t, err)
+
+	require.Len(t, snaps, 1)
+	require.Len(t, snaps[0].Data, 1)
+	assert.Equal(t, int64(1), snaps[0].Data["get"].Samples)
+}
+
+func TestRouterReporterNoSinks(t *testing.T) {
+	r := &Router{Route: RouteLabel("tenant")}
+	r.Update(map[string]string{"tenant": "acme"}, "get", 10)
+
+	rep := r.Reporter("acme")
+
+	assert.Nil(t, rep.Sinks)
+	assert.Len(t, rep.Source(), 1)
+}
+
+func TestRouterDatasetConfig(t *testing.T) {
+	r := &Router{
+		Route: RouteLabel("tenant"),
+		New: func(partition, name string) *Data {
+			return &Data{Retain: 10, Flags: SampleStdDev}
+		},
+	}
+	r.Update(map[string]string{"tenant": "acme"}, "get", 10)
+	r.Update(map[string]string{"tenant": "acme"}, "get", 20)
+
+	d := r.Dataset("acme")["get"]
+
+	assert.Equal(t, SampleStdDev, d.Flags)
+	assert.Equal(t, 10, d.Retain)
+	assert.Len(t, d.Retained(), 2)
+	assert.Equal(t, time.Duration(20), d.Percentile(99))
+}
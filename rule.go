@@ -0,0 +1,226 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Rule is an alert condition over the entries of a snapshot.  Rules
+// are usually constructed with ParseRule from text of the form
+// "metric op threshold [for n]", e.g., "db.query.p99 > 200ms for 3".
+// The metric names a counter, gauge, or derived value of the
+// snapshot, or a field of a Data, given as the name of the Data
+// followed by a dot and one of "samples", "mean", "min", "max",
+// "stddev", or a percentile such as "p99" or "p99.9"; "stddev" is
+// the sample standard deviation (see SampleStdDev), and percentiles
+// are computed from the retained samples (see Retained).  Durations
+// are compared in nanoseconds.
+type Rule struct {
+	Metric    string  // The metric to compare
+	Op        string  // The comparison: ">", ">=", "<", or "<="
+	Threshold float64 // The threshold to compare against
+	For       int     // Consecutive snapshots required; 0 for 1
+}
+
+// ParseRule parses a Rule from its textual form.  The threshold may
+// be a number or a duration, such as "200ms".
+func ParseRule(text string) (*Rule, error) {
+	fields := strings.Fields(text)
+	if len(fields) != 3 && (len(fields) != 5 || fields[3] != "for") {
+		return nil, fmt.Errorf("%w: %q: expected \"metric op threshold [for n]\"", ErrBadRule, text)
+	}
+
+	r := &Rule{Metric: fields[0], Op: fields[1]}
+	switch r.Op {
+	case ">", ">=", "<", "<=":
+	default:
+		return nil, fmt.Errorf("%w: %q: unknown comparison %q", ErrBadRule, text, r.Op)
+	}
+
+	if d, err := time.ParseDuration(fields[2]); err == nil {
+		r.Threshold = float64(d)
+	} else if v, err := strconv.ParseFloat(fields[2], 64); err == nil {
+		r.Threshold = v
+	} else {
+		return nil, fmt.Errorf("%w: %q: invalid threshold %q", ErrBadRule, text, fields[2])
+	}
+
+	if len(fields) == 5 {
+		n, err := strconv.Atoi(fields[4])
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("%w: %q: invalid count %q", ErrBadRule, text, fields[4])
+		}
+		r.For = n
+	}
+
+	return r, nil
+}
+
+// String returns the textual form of the rule.
+func (r *Rule) String() string {
+	text := fmt.Sprintf("%s %s %g", r.Metric, r.Op, r.Threshold)
+	if r.For > 1 {
+		text += fmt.Sprintf(" for %d", r.For)
+	}
+
+	return text
+}
+
+// value returns the value of the rule's metric in the snapshot.
+// Returns false if the metric is not present.
+func (r *Rule) value(snap *Snapshot) (float64, bool) {
+	if v, ok := snap.Counters[r.Metric]; ok {
+		return float64(v), true
+	}
+	if v, ok := snap.Gauges[r.Metric]; ok {
+		return v, true
+	}
+	if v, ok := snap.Derived[r.Metric]; ok {
+		return v, true
+	}
+
+	// Data names may themselves contain dots, as may percentiles
+	for idx := strings.LastIndex(r.Metric, "."); idx >= 0; idx = strings.LastIndex(r.Metric[:idx], ".") {
+		if d, ok := snap.Data[r.Metric[:idx]]; ok {
+			return dataField(d, r.Metric[idx+1:])
+		}
+	}
+
+	return 0, false
+}
+
+// dataField returns the value of a named field of a Data, for use by
+// rules.  Returns false if the field is unknown or unavailable.
+func dataField(d *Data, field string) (float64, bool) {
+	switch field {
+	case "samples":
+		return float64(d.Samples), true
+	case "mean":
+		return float64(d.Mean), true
+	case "min":
+		return float64(d.Min), true
+	case "max":
+		return float64(d.Max), true
+	case "stddev":
+		return float64(d.SampleStdDev()), true
+	}
+
+	if !strings.HasPrefix(field, "p") || len(d.retained) == 0 {
+		return 0, false
+	}
+	p, err := strconv.ParseFloat(field[1:], 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return float64(d.Percentile(p)), true
+}
+
+// holds reports whether the rule's condition holds for a value.
+func (r *Rule) holds(v float64) bool {
+	switch r.Op {
+	case ">":
+		return v > r.Threshold
+	case ">=":
+		return v >= r.Threshold
+	case "<":
+		return v < r.Threshold
+	case "<=":
+		return v <= r.Threshold
+	}
+
+	return false
+}
+
+// RuleAlert describes a rule whose condition has held for the
+// required number of consecutive snapshots.
+type RuleAlert struct {
+	Rule  *Rule   // The rule
+	Value float64 // The value of the metric in the latest snapshot
+}
+
+// RuleFunc is the type of an observer callback invoked by a RuleSet
+// when a rule fires.
+type RuleFunc func(a RuleAlert)
+
+// RuleSet checks a set of rules against each snapshot, invoking the
+// observers for each rule whose condition has held for the required
+// number of consecutive snapshots; a rule continues to fire on each
+// snapshot until its condition no longer holds.  A snapshot in which
+// a rule's metric is missing resets the count.  A RuleSet is a Sink,
+// so it may be added to a Reporter's sinks to provide self-contained
+// alerting.  As with Detector, panics in the observers are recovered
+// and counted, and passed to OnPanic if it is set.  A RuleSet is safe
+// for concurrent use.
+type RuleSet struct {
+	Rules     []*Rule    // The rules to check
+	Observers []RuleFunc // Callbacks to invoke on alerts
+	OnPanic   PanicFunc  // Called with recovered panics; may be nil
+	mu        sync.Mutex
+	streaks   map[*Rule]int
+	panics    int64
+}
+
+// Panics returns the number of panics recovered from the observers.
+func (rs *RuleSet) Panics() int64 {
+	return atomic.LoadInt64(&rs.panics)
+}
+
+// Check checks the rules against the snapshot, invoking the
+// observers for each rule that fires.  Returns the alerts.
+func (rs *RuleSet) Check(snap *Snapshot) []RuleAlert {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.streaks == nil {
+		rs.streaks = map[*Rule]int{}
+	}
+
+	result := []RuleAlert{}
+	for _, r := range rs.Rules {
+		v, ok := r.value(snap)
+		if !ok || !r.holds(v) {
+			delete(rs.streaks, r)
+			continue
+		}
+		if rs.streaks[r] < r.For {
+			rs.streaks[r]++
+		}
+		if rs.streaks[r] < r.For {
+			continue
+		}
+
+		a := RuleAlert{Rule: r, Value: v}
+		for _, obs := range rs.Observers {
+			callObserver(func() { obs(a) }, &rs.panics, rs.OnPanic)
+		}
+		result = append(result, a)
+	}
+
+	return result
+}
+
+// Emit checks the rules against the snapshot; it implements Sink.
+func (rs *RuleSet) Emit(snap *Snapshot) error {
+	rs.Check(snap)
+
+	return nil
+}
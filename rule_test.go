@@ -0,0 +1,282 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var ruleSnapshot = &Snapshot{
+	Data: map[string]*Data{
+		"db.query": {
+			Samples: 4,
+			Mean:    25,
+			Max:     40,
+			Min:     10,
+			m2:      500,
+			retained: []Sample{
+				{Duration: 10},
+				{Duration: 20},
+				{Duration: 30},
+				{Duration: 40},
+			},
+		},
+		"empty": {},
+	},
+	Counters: map[string]int64{"requests": 10},
+	Gauges:   map[string]float64{"depth": 2.5},
+	Derived:  map[string]float64{"error_rate": 0.1},
+}
+
+func TestParseRuleBase(t *testing.T) {
+	result, err := ParseRule("db.query.p99 > 200ms for 3")
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Rule{
+		Metric:    "db.query.p99",
+		Op:        ">",
+		Threshold: float64(200 * time.Millisecond),
+		For:       3,
+	}, result)
+}
+
+func TestParseRuleNumber(t *testing.T) {
+	result, err := ParseRule("error_rate >= 0.05")
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Rule{Metric: "error_rate", Op: ">=", Threshold: 0.05}, result)
+}
+
+func TestParseRuleBadForm(t *testing.T) {
+	result, err := ParseRule("error_rate >= 0.05 during 3")
+
+	assert.ErrorIs(t, err, ErrBadRule)
+	assert.Nil(t, result)
+}
+
+func TestParseRuleBadOp(t *testing.T) {
+	result, err := ParseRule("error_rate == 0.05")
+
+	assert.ErrorIs(t, err, ErrBadRule)
+	assert.Nil(t, result)
+}
+
+func TestParseRuleBadThreshold(t *testing.T) {
+	result, err := ParseRule("error_rate > lots")
+
+	assert.ErrorIs(t, err, ErrBadRule)
+	assert.Nil(t, result)
+}
+
+func TestParseRuleBadCount(t *testing.T) {
+	result, err := ParseRule("error_rate > 0.05 for 0")
+
+	assert.ErrorIs(t, err, ErrBadRule)
+	assert.Nil(t, result)
+}
+
+func TestRuleStringBase(t *testing.T) {
+	r := &Rule{Metric: "error_rate", Op: ">", Threshold: 0.05}
+
+	assert.Equal(t, "error_rate > 0.05", r.String())
+}
+
+func TestRuleStringFor(t *testing.T) {
+	r := &Rule{Metric: "db.query.mean", Op: "<=", Threshold: 1e6, For: 3}
+
+	assert.Equal(t, "db.query.mean <= 1e+06 for 3", r.String())
+}
+
+func TestRuleValueCounter(t *testing.T) {
+	v, ok := (&Rule{Metric: "requests"}).value(ruleSnapshot)
+
+	assert.True(t, ok)
+	assert.Equal(t, 10.0, v)
+}
+
+func TestRuleValueGauge(t *testing.T) {
+	v, ok := (&Rule{Metric: "depth"}).value(ruleSnapshot)
+
+	assert.True(t, ok)
+	assert.Equal(t, 2.5, v)
+}
+
+func TestRuleValueDerived(t *testing.T) {
+	v, ok := (&Rule{Metric: "error_rate"}).value(ruleSnapshot)
+
+	assert.True(t, ok)
+	assert.Equal(t, 0.1, v)
+}
+
+func TestRuleValueSamples(t *testing.T) {
+	v, ok := (&Rule{Metric: "db.query.samples"}).value(ruleSnapshot)
+
+	assert.True(t, ok)
+	assert.Equal(t, 4.0, v)
+}
+
+func TestRuleValueMean(t *testing.T) {
+	v, ok := (&Rule{Metric: "db.query.mean"}).value(ruleSnapshot)
+
+	assert.True(t, ok)
+	assert.Equal(t, 25.0, v)
+}
+
+func TestRuleValueMin(t *testing.T) {
+	v, ok := (&Rule{Metric: "db.query.min"}).value(ruleSnapshot)
+
+	assert.True(t, ok)
+	assert.Equal(t, 10.0, v)
+}
+
+func TestRuleValueMax(t *testing.T) {
+	v, ok := (&Rule{Metric: "db.query.max"}).value(ruleSnapshot)
+
+	assert.True(t, ok)
+	assert.Equal(t, 40.0, v)
+}
+
+func TestRuleValueStdDev(t *testing.T) {
+	v, ok := (&Rule{Metric: "db.query.stddev"}).value(ruleSnapshot)
+
+	assert.True(t, ok)
+	assert.Equal(t, 12.0, v)
+}
+
+func TestRuleValuePercentile(t *testing.T) {
+	v, ok := (&Rule{Metric: "db.query.p50"}).value(ruleSnapshot)
+
+	assert.True(t, ok)
+	assert.Equal(t, 20.0, v)
+}
+
+func TestRuleValuePercentileFraction(t *testing.T) {
+	v, ok := (&Rule{Metric: "db.query.p99.9"}).value(ruleSnapshot)
+
+	assert.True(t, ok)
+	assert.Equal(t, 40.0, v)
+}
+
+func TestRuleValueMissing(t *testing.T) {
+	_, ok := (&Rule{Metric: "missing"}).value(ruleSnapshot)
+
+	assert.False(t, ok)
+}
+
+func TestRuleValueMissingData(t *testing.T) {
+	_, ok := (&Rule{Metric: "missing.mean"}).value(ruleSnapshot)
+
+	assert.False(t, ok)
+}
+
+func TestRuleValueUnknownField(t *testing.T) {
+	_, ok := (&Rule{Metric: "db.query.median"}).value(ruleSnapshot)
+
+	assert.False(t, ok)
+}
+
+func TestRuleValueBadPercentile(t *testing.T) {
+	_, ok := (&Rule{Metric: "db.query.pxx"}).value(ruleSnapshot)
+
+	assert.False(t, ok)
+}
+
+func TestRuleValueNoRetained(t *testing.T) {
+	_, ok := (&Rule{Metric: "empty.p99"}).value(ruleSnapshot)
+
+	assert.False(t, ok)
+}
+
+func TestRuleHolds(t *testing.T) {
+	assert.True(t, (&Rule{Op: ">", Threshold: 1}).holds(2))
+	assert.False(t, (&Rule{Op: ">", Threshold: 1}).holds(1))
+	assert.True(t, (&Rule{Op: ">=", Threshold: 1}).holds(1))
+	assert.True(t, (&Rule{Op: "<", Threshold: 1}).holds(0))
+	assert.False(t, (&Rule{Op: "<", Threshold: 1}).holds(1))
+	assert.True(t, (&Rule{Op: "<=", Threshold: 1}).holds(1))
+	assert.False(t, (&Rule{Op: "!=", Threshold: 1}).holds(2))
+}
+
+func TestRuleSetImplementsSink(t *testing.T) {
+	assert.Implements(t, (*Sink)(nil), &RuleSet{})
+}
+
+func TestRuleSetCheckBase(t *testing.T) {
+	rule := &Rule{Metric: "depth", Op: ">", Threshold: 2}
+	var alerts []RuleAlert
+	rs := &RuleSet{
+		Rules:     []*Rule{rule, {Metric: "requests", Op: ">", Threshold: 100}},
+		Observers: []RuleFunc{func(a RuleAlert) { alerts = append(alerts, a) }},
+	}
+
+	result := rs.Check(ruleSnapshot)
+
+	assert.Equal(t, []RuleAlert{{Rule: rule, Value: 2.5}}, result)
+	assert.Equal(t, result, alerts)
+}
+
+func TestRuleSetCheckFor(t *testing.T) {
+	rule := &Rule{Metric: "depth", Op: ">", Threshold: 2, For: 3}
+	rs := &RuleSet{Rules: []*Rule{rule}}
+
+	assert.Empty(t, rs.Check(ruleSnapshot))
+	assert.Empty(t, rs.Check(ruleSnapshot))
+	assert.Len(t, rs.Check(ruleSnapshot), 1)
+	assert.Len(t, rs.Check(ruleSnapshot), 1)
+	assert.Equal(t, 3, rs.streaks[rule])
+}
+
+func TestRuleSetCheckReset(t *testing.T) {
+	rule := &Rule{Metric: "depth", Op: ">", Threshold: 2, For: 2}
+	rs := &RuleSet{Rules: []*Rule{rule}}
+
+	assert.Empty(t, rs.Check(ruleSnapshot))
+	assert.Empty(t, rs.Check(&Snapshot{}))
+	assert.Empty(t, rs.Check(ruleSnapshot))
+	assert.Len(t, rs.Check(ruleSnapshot), 1)
+}
+
+func TestRuleSetCheckPanic(t *testing.T) {
+	var recovered []interface{}
+	rs := &RuleSet{
+		Rules:     []*Rule{{Metric: "depth", Op: ">", Threshold: 2}},
+		Observers: []RuleFunc{func(a RuleAlert) { panic("oops") }},
+		OnPanic:   func(v interface{}) { recovered = append(recovered, v) },
+	}
+
+	result := rs.Check(ruleSnapshot)
+
+	assert.Len(t, result, 1)
+	assert.Equal(t, int64(1), rs.Panics())
+	assert.Equal(t, []interface{}{"oops"}, recovered)
+}
+
+func TestRuleSetEmit(t *testing.T) {
+	var alerts []RuleAlert
+	rs := &RuleSet{
+		Rules:     []*Rule{{Metric: "depth", Op: ">", Threshold: 2}},
+		Observers: []RuleFunc{func(a RuleAlert) { alerts = append(alerts, a) }},
+	}
+
+	err := rs.Emit(ruleSnapshot)
+
+	assert.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, 2.5, alerts[0].Value)
+}
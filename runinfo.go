@@ -0,0 +1,233 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"bufio"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cpuInfoFile is the file from which the CPU model is read.  It is a
+// variable so that it may be overridden by tests.
+var cpuInfoFile = "/proc/cpuinfo"
+
+// RunInfo describes the environment in which timing data was
+// collected.  Comparing timing data without knowing what machine and
+// build produced it is meaningless, so a RunInfo should be saved
+// alongside the data.
+type RunInfo struct {
+	GoVersion  string        `json:"go_version" yaml:"go_version"`
+	GOOS       string        `json:"goos" yaml:"goos"`
+	GOARCH     string        `json:"goarch" yaml:"goarch"`
+	CPU        string        `json:"cpu,omitempty" yaml:"cpu,omitempty"`
+	NumCPU     int           `json:"num_cpu" yaml:"num_cpu"`
+	GOMAXPROCS int           `json:"gomaxprocs" yaml:"gomaxprocs"`
+	Revision   string        `json:"revision,omitempty" yaml:"revision,omitempty"`
+	Modified   bool          `json:"modified,omitempty" yaml:"modified,omitempty"`
+	Hostname   string        `json:"hostname,omitempty" yaml:"hostname,omitempty"`
+	Timestamp  time.Time     `json:"timestamp" yaml:"timestamp"`
+	Reference  time.Duration `json:"reference,omitempty" yaml:"reference,omitempty"`
+	Noise      time.Duration `json:"noise,omitempty" yaml:"noise,omitempty"`
+	Ballast    int64         `json:"ballast,omitempty" yaml:"ballast,omitempty"`
+	HeapWarm   int64         `json:"heap_warm,omitempty" yaml:"heap_warm,omitempty"`
+}
+
+// Calibration settings.
+const (
+	calibrationRuns = 3       // Number of runs of the reference workload
+	noiseRuns       = 1000    // Number of runs of the empty workload
+	referenceSize   = 1 << 20 // Size of the reference workload buffer
+	referencePasses = 4       // Number of passes over the buffer
+)
+
+// referenceSink prevents the compiler from optimizing away the
+// reference workload.
+var referenceSink uint64
+
+// referenceWorkload is a fixed workload, mixing arithmetic and memory
+// access, used to estimate the relative speed of a machine.
+func referenceWorkload() {
+	buf := make([]byte, referenceSize)
+	hash := uint64(14695981039346656037)
+	for pass := 0; pass < referencePasses; pass++ {
+		for i := range buf {
+			hash ^= uint64(buf[i])
+			hash *= 1099511628211
+			buf[i] = byte(hash)
+		}
+	}
+	referenceSink = hash
+}
+
+// cpuModel returns the CPU model name, if it can be determined.
+func cpuModel() string {
+	f, err := os.Open(cpuInfoFile)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), ":", 2)
+		if len(fields) == 2 && strings.TrimSpace(fields[0]) == "model name" {
+			return strings.TrimSpace(fields[1])
+		}
+	}
+
+	return ""
+}
+
+// CaptureRunInfo captures a RunInfo describing the current process.
+// The revision of the version control system and whether the working
+// tree was modified are only available if the binary was built with
+// Go 1.18 or later from within a version control checkout.
+func CaptureRunInfo() *RunInfo {
+	ri := &RunInfo{
+		GoVersion:  runtime.Version(),
+		GOOS:       runtime.GOOS,
+		GOARCH:     runtime.GOARCH,
+		CPU:        cpuModel(),
+		NumCPU:     runtime.NumCPU(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		Timestamp:  time.Now(),
+	}
+	ri.Revision, ri.Modified = vcsInfo()
+	ri.Hostname, _ = os.Hostname()
+
+	return ri
+}
+
+// Calibrate times a fixed reference workload several times and
+// stores the fastest time in Reference.  This provides a
+// machine-speed factor that can be used to normalize timing data
+// collected on different machines; see Normalize and Scale.
+func (ri *RunInfo) Calibrate() {
+	ri.Reference = timeRuns(calibrationRuns, referenceWorkload).Min
+}
+
+// EstimateNoise times an empty workload many times and stores the
+// standard deviation of the times in Noise.  This estimates the noise
+// floor of measurements made under the current conditions, including
+// timer resolution and interference from other activity on the
+// machine, so that readers of a report can tell whether a difference
+// between results is meaningful.
+func (ri *RunInfo) EstimateNoise() {
+	ri.Noise = timeRuns(noiseRuns, func() {}).SampleStdDev()
+}
+
+// timeRuns times n runs of the function with the WallClock, returning
+// the Data.  The runs are timed even if instrumentation is disabled
+// (see Enable), since the results describe the machine rather than
+// the code being instrumented.
+func timeRuns(n int, fn func()) *Data {
+	d := &Data{}
+	for i := 0; i < n; i++ {
+		start := WallClock.Now()
+		fn()
+		d.updateSample(Sample{At: start, Duration: elapsed(WallClock, start)})
+	}
+
+	return d
+}
+
+// Normalize expresses a duration in units of the reference workload
+// time, making it roughly comparable across machines.  Returns 0 if
+// the RunInfo has not been calibrated.
+func (ri *RunInfo) Normalize(delta time.Duration) float64 {
+	if ri.Reference <= 0 {
+		return 0
+	}
+
+	return float64(delta) / float64(ri.Reference)
+}
+
+// Scale converts a duration measured on the machine described by the
+// RunInfo into an estimate of the duration on the machine described
+// by another RunInfo, using the ratio of their reference workload
+// times.  The duration is returned unchanged if either RunInfo has
+// not been calibrated.
+func (ri *RunInfo) Scale(delta time.Duration, to *RunInfo) time.Duration {
+	if ri.Reference <= 0 || to.Reference <= 0 {
+		return delta
+	}
+
+	return time.Duration(float64(delta) * float64(to.Reference) / float64(ri.Reference))
+}
+
+// ScaleData is similar to Scale, but returns a copy of the Data with
+// all statistics scaled.  Only the statistics are copied; in
+// particular, the copy has no Next.
+func (ri *RunInfo) ScaleData(d *Data, to *RunInfo) *Data {
+	factor := 1.0
+	if ri.Reference > 0 && to.Reference > 0 {
+		factor = float64(to.Reference) / float64(ri.Reference)
+	}
+
+	return &Data{
+		Samples: d.Samples,
+		Mean:    time.Duration(float64(d.Mean) * factor),
+		Max:     time.Duration(float64(d.Max) * factor),
+		Min:     time.Duration(float64(d.Min) * factor),
+		MinAt:   d.MinAt,
+		MaxAt:   d.MaxAt,
+		Flags:   d.Flags,
+		m2:      time.Duration(float64(d.m2) * factor * factor),
+	}
+}
+
+// Config returns the RunInfo as a set of configuration keys suitable
+// for passing to WriteBenchmarkConfig.  Empty values are omitted.
+func (ri *RunInfo) Config() map[string]string {
+	config := map[string]string{
+		"go":         ri.GoVersion,
+		"goos":       ri.GOOS,
+		"goarch":     ri.GOARCH,
+		"cpu":        ri.CPU,
+		"numcpu":     strconv.Itoa(ri.NumCPU),
+		"gomaxprocs": strconv.Itoa(ri.GOMAXPROCS),
+		"commit":     ri.Revision,
+		"host":       ri.Hostname,
+	}
+	if ri.Modified {
+		config["commit-modified"] = "true"
+	}
+	if !ri.Timestamp.IsZero() {
+		config["date"] = ri.Timestamp.Format(time.RFC3339)
+	}
+	if ri.Reference > 0 {
+		config["reference"] = ri.Reference.String()
+	}
+	if ri.Noise > 0 {
+		config["noise"] = ri.Noise.String()
+	}
+	if ri.Ballast > 0 {
+		config["ballast"] = strconv.FormatInt(ri.Ballast, 10)
+	}
+	if ri.HeapWarm > 0 {
+		config["heap-warm"] = strconv.FormatInt(ri.HeapWarm, 10)
+	}
+	for key, value := range config {
+		if value == "" {
+			delete(config, key)
+		}
+	}
+
+	return config
+}
@@ -0,0 +1,225 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCPUModelBase(t *testing.T) {
+	defer func(orig string) { cpuInfoFile = orig }(cpuInfoFile)
+	cpuInfoFile = "testdata/cpuinfo"
+
+	result := cpuModel()
+
+	assert.Equal(t, "Intel(R) Xeon(R) CPU @ 2.20GHz", result)
+}
+
+func TestCPUModelMissing(t *testing.T) {
+	defer func(orig string) { cpuInfoFile = orig }(cpuInfoFile)
+	cpuInfoFile = "testdata/nonexistent"
+
+	result := cpuModel()
+
+	assert.Equal(t, "", result)
+}
+
+func TestCPUModelNoModel(t *testing.T) {
+	defer func(orig string) { cpuInfoFile = orig }(cpuInfoFile)
+	cpuInfoFile = "testdata/empty"
+
+	result := cpuModel()
+
+	assert.Equal(t, "", result)
+}
+
+func TestCaptureRunInfo(t *testing.T) {
+	defer func(orig string) { cpuInfoFile = orig }(cpuInfoFile)
+	cpuInfoFile = "testdata/cpuinfo"
+	hostname, _ := os.Hostname()
+
+	result := CaptureRunInfo()
+
+	assert.Equal(t, runtime.Version(), result.GoVersion)
+	assert.Equal(t, runtime.GOOS, result.GOOS)
+	assert.Equal(t, runtime.GOARCH, result.GOARCH)
+	assert.Equal(t, "Intel(R) Xeon(R) CPU @ 2.20GHz", result.CPU)
+	assert.Equal(t, runtime.NumCPU(), result.NumCPU)
+	assert.Equal(t, runtime.GOMAXPROCS(0), result.GOMAXPROCS)
+	assert.Equal(t, hostname, result.Hostname)
+	assert.False(t, result.Timestamp.IsZero())
+}
+
+func TestVCSInfo(t *testing.T) {
+	revision, modified := vcsInfo()
+
+	// Test binaries are not stamped with version control
+	// information
+	assert.Equal(t, "", revision)
+	assert.False(t, modified)
+}
+
+func TestRunInfoConfigBase(t *testing.T) {
+	ri := &RunInfo{
+		GoVersion:  "go1.19",
+		GOOS:       "linux",
+		GOARCH:     "amd64",
+		CPU:        "Intel(R) Xeon(R) CPU @ 2.20GHz",
+		NumCPU:     8,
+		GOMAXPROCS: 4,
+		Revision:   "1234abcd",
+		Modified:   true,
+		Hostname:   "example",
+		Timestamp:  time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		Reference:  5 * time.Millisecond,
+		Noise:      50 * time.Nanosecond,
+		Ballast:    1024,
+		HeapWarm:   2048,
+	}
+
+	result := ri.Config()
+
+	assert.Equal(t, map[string]string{
+		"go":              "go1.19",
+		"goos":            "linux",
+		"goarch":          "amd64",
+		"cpu":             "Intel(R) Xeon(R) CPU @ 2.20GHz",
+		"numcpu":          "8",
+		"gomaxprocs":      "4",
+		"commit":          "1234abcd",
+		"commit-modified": "true",
+		"host":            "example",
+		"date":            "2020-01-01T00:00:00Z",
+		"reference":       "5ms",
+		"noise":           "50ns",
+		"ballast":         "1024",
+		"heap-warm":       "2048",
+	}, result)
+}
+
+func TestRunInfoConfigEmpty(t *testing.T) {
+	ri := &RunInfo{}
+
+	result := ri.Config()
+
+	assert.Equal(t, map[string]string{
+		"numcpu":     "0",
+		"gomaxprocs": "0",
+	}, result)
+}
+
+func TestReferenceWorkload(t *testing.T) {
+	referenceSink = 0
+
+	referenceWorkload()
+
+	assert.NotEqual(t, uint64(0), referenceSink)
+}
+
+func TestRunInfoCalibrate(t *testing.T) {
+	ri := &RunInfo{}
+
+	ri.Calibrate()
+
+	assert.Greater(t, int64(ri.Reference), int64(0))
+}
+
+func TestRunInfoEstimateNoise(t *testing.T) {
+	ri := &RunInfo{}
+
+	ri.EstimateNoise()
+
+	assert.Greater(t, ri.Noise, time.Duration(0))
+}
+
+func TestRunInfoNormalizeBase(t *testing.T) {
+	ri := &RunInfo{Reference: 10 * time.Millisecond}
+
+	result := ri.Normalize(25 * time.Millisecond)
+
+	assert.Equal(t, 2.5, result)
+}
+
+func TestRunInfoNormalizeUncalibrated(t *testing.T) {
+	ri := &RunInfo{}
+
+	result := ri.Normalize(25 * time.Millisecond)
+
+	assert.Equal(t, 0.0, result)
+}
+
+func TestRunInfoScaleBase(t *testing.T) {
+	ri := &RunInfo{Reference: 10 * time.Millisecond}
+	to := &RunInfo{Reference: 20 * time.Millisecond}
+
+	result := ri.Scale(25*time.Millisecond, to)
+
+	assert.Equal(t, 50*time.Millisecond, result)
+}
+
+func TestRunInfoScaleUncalibrated(t *testing.T) {
+	ri := &RunInfo{Reference: 10 * time.Millisecond}
+	to := &RunInfo{}
+
+	result := ri.Scale(25*time.Millisecond, to)
+
+	assert.Equal(t, 25*time.Millisecond, result)
+}
+
+func TestRunInfoScaleDataBase(t *testing.T) {
+	ri := &RunInfo{Reference: 10 * time.Millisecond}
+	to := &RunInfo{Reference: 20 * time.Millisecond}
+	d := &Data{
+		Samples: 3,
+		Mean:    50,
+		Max:     75,
+		Min:     25,
+		Flags:   StdDev,
+		Next:    &Data{},
+		m2:      1250,
+	}
+
+	result := ri.ScaleData(d, to)
+
+	assert.Equal(t, &Data{
+		Samples: 3,
+		Mean:    100,
+		Max:     150,
+		Min:     50,
+		Flags:   StdDev,
+		m2:      5000,
+	}, result)
+}
+
+func TestRunInfoScaleDataUncalibrated(t *testing.T) {
+	ri := &RunInfo{}
+	to := &RunInfo{Reference: 20 * time.Millisecond}
+	d := &Data{
+		Samples: 3,
+		Mean:    50,
+		Max:     75,
+		Min:     25,
+		m2:      1250,
+	}
+
+	result := ri.ScaleData(d, to)
+
+	assert.Equal(t, d, result)
+}
@@ -0,0 +1,397 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxFrameSize is the largest frame that ReplayBinary will accept.
+const maxFrameSize = 1 << 16
+
+// rotateFormat is the format of the timestamp appended to the names
+// of rotated sample files.
+const rotateFormat = "20060102T150405.000000000Z"
+
+// gzipExt is the file extension indicating gzip compression.
+const gzipExt = ".gz"
+
+// frame encodes a Record in the binary sample file format.  Each
+// frame consists of a uvarint length, followed by that many bytes of
+// payload; the payload contains the varint-encoded timestamp, in
+// nanoseconds since the Unix epoch, followed by the varint-encoded
+// duration, followed by the name of the timer.
+func (r *Record) frame() []byte {
+	var tmp [binary.MaxVarintLen64]byte
+
+	payload := make([]byte, 0, 2*binary.MaxVarintLen64+len(r.Name))
+	n := binary.PutVarint(tmp[:], r.Timestamp.UnixNano())
+	payload = append(payload, tmp[:n]...)
+	n = binary.PutVarint(tmp[:], int64(r.Duration))
+	payload = append(payload, tmp[:n]...)
+	payload = append(payload, r.Name...)
+
+	buf := make([]byte, 0, binary.MaxVarintLen64+len(payload))
+	n = binary.PutUvarint(tmp[:], uint64(len(payload)))
+	buf = append(buf, tmp[:n]...)
+
+	return append(buf, payload...)
+}
+
+// unframe decodes the payload of a frame into the Record.
+func (r *Record) unframe(payload []byte) bool {
+	ts, n := binary.Varint(payload)
+	if n <= 0 {
+		return false
+	}
+	payload = payload[n:]
+	dur, n := binary.Varint(payload)
+	if n <= 0 {
+		return false
+	}
+
+	r.Timestamp = time.Unix(0, ts)
+	r.Duration = time.Duration(dur)
+	r.Name = string(payload[n:])
+
+	return true
+}
+
+// ReplayBinary reads a stream of Record objects in the binary format
+// written by SampleFile, and updates the Data in the dataset with the
+// recorded samples.  As with ReplayJSON, Data not already present in
+// the dataset will be created.
+func ReplayBinary(r io.Reader, dataset map[string]*Data) error {
+	reader := bufio.NewReader(r)
+	for frame := 1; ; frame++ {
+		// Read the frame length
+		size, err := binary.ReadUvarint(reader)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%w: frame %d: %s", ErrBadRecord, frame, err)
+		} else if size > maxFrameSize {
+			return fmt.Errorf("%w: frame %d: frame too large", ErrBadRecord, frame)
+		}
+
+		// Read and decode the payload
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			return fmt.Errorf("%w: frame %d: %s", ErrBadRecord, frame, err)
+		}
+		rec := &Record{}
+		if !rec.unframe(payload) {
+			return fmt.Errorf("%w: frame %d: malformed payload", ErrBadRecord, frame)
+		}
+
+		rec.replay(dataset)
+	}
+}
+
+// countingWriter is a writer that counts the bytes written through
+// it.
+type countingWriter struct {
+	w io.Writer
+	n *int64
+}
+
+// Write writes the bytes to the underlying writer.
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	*cw.n += int64(n)
+
+	return n, err
+}
+
+// SampleFile appends samples to a file in a compact binary format
+// that preserves the full fidelity of each sample, for later
+// analysis with ReplayBinary or ReplayFile.  The file may be rotated
+// when it exceeds a size or age limit; rotated files are renamed by
+// inserting a timestamp into the file name, and may optionally be
+// compressed with gzip.  If the Path has the ".gz" extension, the
+// file itself is compressed with gzip; the compressed stream is
+// flushed after each sample, but the file must be closed before the
+// final samples can be read back.  A SampleFile is safe for
+// concurrent use.
+//
+// If Lock is set, the file may also be shared by SampleFile objects in
+// other processes, such as the workers of a pre-forked server: each
+// Write holds an exclusive advisory lock on the file, takes the size
+// of the file from the file itself rather than from what this process
+// has written, and reopens the file if another process has rotated
+// it.  The MaxAge is still measured from when this process opened the
+// file.  When locking, a compressed file is written as a series of
+// gzip members, one per sample, so that the compressed streams of
+// different processes are not interleaved; such a file may be read
+// back without closing it.  Locking is only supported on Unix-like
+// platforms; elsewhere, Write returns an error wrapping
+// ErrUnsupported.
+type SampleFile struct {
+	Path     string        // Path of the sample file
+	MaxSize  int64         // Size at which to rotate; 0 for no limit
+	MaxAge   time.Duration // Age at which to rotate; 0 for no limit
+	Compress bool          // Set to compress rotated files
+	Lock     bool          // Set to lock the file for sharing with other processes
+	Clock    Clock         // Source of time for rotation; nil for WallClock
+	mu       sync.Mutex
+	file     *os.File
+	gz       *gzip.Writer
+	out      io.Writer
+	size     int64
+	opened   time.Time
+}
+
+// clock returns the Clock to use for the SampleFile.
+func (sf *SampleFile) clock() Clock {
+	if sf.Clock == nil {
+		return WallClock
+	}
+
+	return sf.Clock
+}
+
+// open opens the sample file, if it is not already open.
+func (sf *SampleFile) open() error {
+	if sf.file != nil {
+		return nil
+	}
+
+	f, err := os.OpenFile(sf.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	sf.file = f
+	sf.size = info.Size()
+	sf.out = &countingWriter{w: f, n: &sf.size}
+	if !sf.Lock && strings.HasSuffix(sf.Path, gzipExt) {
+		sf.gz = gzip.NewWriter(sf.out)
+		sf.out = sf.gz
+	}
+	sf.opened = sf.clock().Now()
+
+	return nil
+}
+
+// lock opens the sample file, if it is not already open, and locks
+// it.  If another process has rotated the file since it was opened,
+// the file is closed and the new file opened in its place.  The size
+// of the file is updated to include samples written by other
+// processes.
+func (sf *SampleFile) lock() error {
+	for {
+		if err := sf.open(); err != nil {
+			return err
+		}
+		if err := lockFile(sf.file); err != nil {
+			_ = sf.closeFile()
+			return err
+		}
+
+		// Make sure the file hasn't been rotated
+		info, err := sf.file.Stat()
+		if err != nil {
+			_ = sf.closeFile()
+			return err
+		}
+		current, err := os.Stat(sf.Path)
+		if err != nil && !os.IsNotExist(err) {
+			_ = sf.closeFile()
+			return err
+		} else if err == nil && os.SameFile(info, current) {
+			sf.size = info.Size()
+			return nil
+		}
+
+		if err := sf.closeFile(); err != nil {
+			return err
+		}
+	}
+}
+
+// unlock releases the lock on the sample file, if it is open.  If the
+// lock cannot be released, the file is closed, which releases it.
+func (sf *SampleFile) unlock() {
+	if sf.file == nil {
+		return
+	}
+
+	if err := unlockFile(sf.file); err != nil {
+		_ = sf.closeFile()
+	}
+}
+
+// closeFile closes the sample file, completing the compressed stream
+// if necessary.
+func (sf *SampleFile) closeFile() error {
+	var err error
+	if sf.gz != nil {
+		err = sf.gz.Close()
+	}
+	if closeErr := sf.file.Close(); err == nil {
+		err = closeErr
+	}
+	sf.file = nil
+	sf.gz = nil
+	sf.out = nil
+
+	return err
+}
+
+// rotatedName returns the name to which the sample file should be
+// renamed when it is rotated.  The timestamp is inserted before the
+// ".gz" extension, if any.
+func (sf *SampleFile) rotatedName() string {
+	base := strings.TrimSuffix(sf.Path, gzipExt)
+	ext := sf.Path[len(base):]
+
+	return fmt.Sprintf("%s.%s%s", base, sf.clock().Now().UTC().Format(rotateFormat), ext)
+}
+
+// rotate closes and renames the sample file, compressing it if
+// requested and not already compressed.  When locking, the file is
+// renamed before it is closed, so that other processes waiting for
+// the lock will see that it has been rotated.
+func (sf *SampleFile) rotate() error {
+	name := sf.rotatedName()
+	if sf.Lock {
+		if err := os.Rename(sf.Path, name); err != nil {
+			return err
+		}
+		if err := sf.closeFile(); err != nil {
+			return err
+		}
+	} else {
+		if err := sf.closeFile(); err != nil {
+			return err
+		}
+		if err := os.Rename(sf.Path, name); err != nil {
+			return err
+		}
+	}
+
+	if sf.Compress && !strings.HasSuffix(name, gzipExt) {
+		return compressFile(name)
+	}
+
+	return nil
+}
+
+// compressFile compresses a file with gzip, replacing it with a file
+// with the ".gz" extension.
+func compressFile(name string) error {
+	src, err := os.Open(name) //nolint:gosec
+	if err != nil {
+		return err
+	}
+	defer src.Close() //nolint:errcheck
+
+	dst, err := os.OpenFile(name+gzipExt, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	_, err = io.Copy(gz, src)
+	if closeErr := gz.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Remove(name)
+}
+
+// Write appends a Record to the sample file, opening the file and
+// rotating it as necessary.
+func (sf *SampleFile) Write(rec *Record) error {
+	frame := rec.frame()
+
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+
+	acquire := sf.open
+	if sf.Lock {
+		acquire = sf.lock
+		defer sf.unlock()
+	}
+	if err := acquire(); err != nil {
+		return err
+	}
+
+	// Rotate the file if it's too big or too old
+	if sf.size > 0 && ((sf.MaxSize > 0 && sf.size+int64(len(frame)) > sf.MaxSize) ||
+		(sf.MaxAge > 0 && sf.clock().Since(sf.opened) >= sf.MaxAge)) {
+		if err := sf.rotate(); err != nil {
+			return err
+		}
+		if err := acquire(); err != nil {
+			return err
+		}
+	}
+
+	return sf.write(frame)
+}
+
+// write writes a frame to the open sample file.  When locking, a
+// compressed file receives each frame as a complete gzip member.
+func (sf *SampleFile) write(frame []byte) error {
+	if sf.Lock && strings.HasSuffix(sf.Path, gzipExt) {
+		gz := gzip.NewWriter(sf.out)
+		if _, err := gz.Write(frame); err != nil {
+			return err
+		}
+
+		return gz.Close()
+	}
+
+	if _, err := sf.out.Write(frame); err != nil {
+		return err
+	}
+	if sf.gz != nil {
+		return sf.gz.Flush()
+	}
+
+	return nil
+}
+
+// Close closes the sample file.  The file will be reopened by the
+// next call to Write.
+func (sf *SampleFile) Close() error {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+
+	if sf.file == nil {
+		return nil
+	}
+
+	return sf.closeFile()
+}
@@ -0,0 +1,399 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRecord is a record used by the sample file tests.
+var testRecord = &Record{Timestamp: time.Unix(1000, 0), Duration: 50, Name: "timer"}
+
+func TestRecordFrameRoundTrip(t *testing.T) {
+	rec := &Record{
+		Timestamp: time.Unix(1000, 500),
+		Duration:  50 * time.Millisecond,
+		Name:      "timer",
+	}
+
+	frame := rec.frame()
+	result := &Record{}
+	ok := result.unframe(frame[1:])
+
+	assert.Equal(t, byte(len(frame)-1), frame[0])
+	assert.True(t, ok)
+	assert.True(t, rec.Timestamp.Equal(result.Timestamp))
+	assert.Equal(t, rec.Duration, result.Duration)
+	assert.Equal(t, rec.Name, result.Name)
+}
+
+func TestRecordUnframeBadTimestamp(t *testing.T) {
+	rec := &Record{}
+
+	ok := rec.unframe([]byte{0x80})
+
+	assert.False(t, ok)
+}
+
+func TestRecordUnframeBadDuration(t *testing.T) {
+	rec := &Record{}
+
+	ok := rec.unframe([]byte{0x02, 0x80})
+
+	assert.False(t, ok)
+}
+
+func TestReplayBinaryBase(t *testing.T) {
+	buf := &bytes.Buffer{}
+	buf.Write((&Record{Timestamp: time.Unix(1000, 0), Duration: 50, Name: "timer1"}).frame())
+	buf.Write((&Record{Timestamp: time.Unix(1001, 0), Duration: 70, Name: "timer2"}).frame())
+	buf.Write((&Record{Timestamp: time.Unix(1002, 0), Duration: 30, Name: "timer1"}).frame())
+	dataset := map[string]*Data{}
+
+	err := ReplayBinary(buf, dataset)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), dataset["timer1"].Samples)
+	assert.Equal(t, time.Duration(40), dataset["timer1"].Mean)
+	assert.Equal(t, int64(1), dataset["timer2"].Samples)
+}
+
+func TestReplayBinaryBadLength(t *testing.T) {
+	err := ReplayBinary(bytes.NewReader([]byte{0x80}), map[string]*Data{})
+
+	assert.ErrorIs(t, err, ErrBadRecord)
+	assert.EqualError(t, err, "invalid sample record: frame 1: unexpected EOF")
+}
+
+func TestReplayBinaryTooLarge(t *testing.T) {
+	err := ReplayBinary(bytes.NewReader([]byte{0x80, 0x80, 0x08}), map[string]*Data{})
+
+	assert.ErrorIs(t, err, ErrBadRecord)
+	assert.EqualError(t, err, "invalid sample record: frame 1: frame too large")
+}
+
+func TestReplayBinaryShort(t *testing.T) {
+	err := ReplayBinary(bytes.NewReader([]byte{0x05, 0x02}), map[string]*Data{})
+
+	assert.ErrorIs(t, err, ErrBadRecord)
+	assert.EqualError(t, err, "invalid sample record: frame 1: unexpected EOF")
+}
+
+func TestReplayBinaryMalformed(t *testing.T) {
+	err := ReplayBinary(bytes.NewReader([]byte{0x01, 0x80}), map[string]*Data{})
+
+	assert.ErrorIs(t, err, ErrBadRecord)
+	assert.EqualError(t, err, "invalid sample record: frame 1: malformed payload")
+}
+
+func TestSampleFileClockDefault(t *testing.T) {
+	sf := &SampleFile{}
+
+	result := sf.clock()
+
+	assert.Equal(t, WallClock, result)
+}
+
+func TestSampleFileClockSet(t *testing.T) {
+	clock := &testClock{}
+	sf := &SampleFile{Clock: clock}
+
+	result := sf.clock()
+
+	assert.Same(t, clock, result)
+}
+
+// replayFile replays a sample file.
+func replayFile(t *testing.T, name string) map[string]*Data {
+	dataset := map[string]*Data{}
+	require.NoError(t, ReplayFile(name, dataset))
+
+	return dataset
+}
+
+// rotatedFiles returns the names of the rotated files.
+func rotatedFiles(t *testing.T, path string) []string {
+	names, err := filepath.Glob(path + ".*")
+	require.NoError(t, err)
+	sort.Strings(names)
+
+	return names
+}
+
+func TestCountingWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	n := int64(5)
+	cw := &countingWriter{w: buf, n: &n}
+
+	result, err := cw.Write([]byte("data"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, result)
+	assert.Equal(t, int64(9), n)
+	assert.Equal(t, "data", buf.String())
+}
+
+func TestSampleFileRotatedNameBase(t *testing.T) {
+	sf := &SampleFile{
+		Path:  "samples",
+		Clock: &stepClock{now: time.Unix(1000, 0)},
+	}
+
+	result := sf.rotatedName()
+
+	assert.Equal(t, "samples.19700101T001640.000000000Z", result)
+}
+
+func TestSampleFileRotatedNameCompressed(t *testing.T) {
+	sf := &SampleFile{
+		Path:  "samples.gz",
+		Clock: &stepClock{now: time.Unix(1000, 0)},
+	}
+
+	result := sf.rotatedName()
+
+	assert.Equal(t, "samples.19700101T001640.000000000Z.gz", result)
+}
+
+func TestSampleFileWriteBase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples")
+	sf := &SampleFile{Path: path}
+
+	err1 := sf.Write(&Record{Timestamp: time.Unix(1000, 0), Duration: 50, Name: "timer"})
+	err2 := sf.Write(&Record{Timestamp: time.Unix(1001, 0), Duration: 30, Name: "timer"})
+	err3 := sf.Close()
+
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+	dataset := replayFile(t, path)
+	assert.Equal(t, int64(2), dataset["timer"].Samples)
+	assert.Equal(t, time.Duration(40), dataset["timer"].Mean)
+	assert.Empty(t, rotatedFiles(t, path))
+}
+
+func TestSampleFileWriteAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples")
+	sf := &SampleFile{Path: path}
+	require.NoError(t, sf.Write(&Record{Timestamp: time.Unix(1000, 0), Duration: 50, Name: "timer"}))
+	require.NoError(t, sf.Close())
+	sf = &SampleFile{Path: path}
+
+	err := sf.Write(&Record{Timestamp: time.Unix(1001, 0), Duration: 30, Name: "timer"})
+
+	assert.NoError(t, err)
+	assert.NoError(t, sf.Close())
+	dataset := replayFile(t, path)
+	assert.Equal(t, int64(2), dataset["timer"].Samples)
+}
+
+func TestSampleFileWriteCompressed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples.gz")
+	sf := &SampleFile{Path: path}
+	require.NoError(t, sf.Write(&Record{Timestamp: time.Unix(1000, 0), Duration: 50, Name: "timer"}))
+	require.NoError(t, sf.Write(&Record{Timestamp: time.Unix(1001, 0), Duration: 30, Name: "timer"}))
+	require.NoError(t, sf.Close())
+
+	err := sf.Write(&Record{Timestamp: time.Unix(1002, 0), Duration: 40, Name: "timer"})
+
+	assert.NoError(t, err)
+	assert.NoError(t, sf.Close())
+	dataset := replayFile(t, path)
+	assert.Equal(t, int64(3), dataset["timer"].Samples)
+	assert.Equal(t, time.Duration(40), dataset["timer"].Mean)
+}
+
+func TestSampleFileWriteOpenError(t *testing.T) {
+	sf := &SampleFile{Path: filepath.Join(t.TempDir(), "missing", "samples")}
+
+	err := sf.Write(&Record{Timestamp: time.Unix(1000, 0), Duration: 50, Name: "timer"})
+
+	assert.Error(t, err)
+}
+
+func TestSampleFileWriteRotateSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples")
+	sf := &SampleFile{
+		Path:    path,
+		MaxSize: 20,
+		Clock:   &stepClock{now: time.Unix(1000, 0).UTC(), step: time.Second},
+	}
+
+	for i := 0; i < 3; i++ {
+		err := sf.Write(&Record{Timestamp: time.Unix(1000, 0), Duration: 50, Name: "timer"})
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, sf.Close())
+
+	rotated := rotatedFiles(t, path)
+	assert.Equal(t, []string{
+		path + ".19700101T001641.000000000Z",
+		path + ".19700101T001643.000000000Z",
+	}, rotated)
+	for _, name := range append(rotated, path) {
+		assert.Equal(t, int64(1), replayFile(t, name)["timer"].Samples)
+	}
+}
+
+func TestSampleFileWriteRotateAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples")
+	sf := &SampleFile{
+		Path:   path,
+		MaxAge: 2 * time.Second,
+		Clock:  &stepClock{now: time.Unix(1000, 0).UTC(), step: time.Second},
+	}
+
+	for i := 0; i < 3; i++ {
+		err := sf.Write(&Record{Timestamp: time.Unix(1000, 0), Duration: 50, Name: "timer"})
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, sf.Close())
+
+	rotated := rotatedFiles(t, path)
+	assert.Equal(t, []string{
+		path + ".19700101T001643.000000000Z",
+	}, rotated)
+	assert.Equal(t, int64(2), replayFile(t, rotated[0])["timer"].Samples)
+	assert.Equal(t, int64(1), replayFile(t, path)["timer"].Samples)
+}
+
+func TestSampleFileWriteRotateCompress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples")
+	sf := &SampleFile{
+		Path:     path,
+		MaxSize:  20,
+		Compress: true,
+		Clock:    &stepClock{now: time.Unix(1000, 0).UTC(), step: time.Second},
+	}
+
+	for i := 0; i < 2; i++ {
+		err := sf.Write(&Record{Timestamp: time.Unix(1000, 0), Duration: 50, Name: "timer"})
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, sf.Close())
+
+	rotated := rotatedFiles(t, path)
+	assert.Equal(t, []string{
+		path + ".19700101T001641.000000000Z.gz",
+	}, rotated)
+	assert.Equal(t, int64(1), replayFile(t, rotated[0])["timer"].Samples)
+}
+
+func TestSampleFileWriteRotateCompressed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples.gz")
+	sf := &SampleFile{
+		Path:     path,
+		MaxSize:  20,
+		Compress: true,
+		Clock:    &stepClock{now: time.Unix(1000, 0).UTC(), step: time.Second},
+	}
+
+	for i := 0; i < 2; i++ {
+		err := sf.Write(&Record{Timestamp: time.Unix(1000, 0), Duration: 50, Name: "timer"})
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, sf.Close())
+
+	rotated, err := filepath.Glob(filepath.Join(filepath.Dir(path), "samples.*.gz"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(filepath.Dir(path), "samples.19700101T001641.000000000Z.gz"),
+	}, rotated)
+	assert.Equal(t, int64(1), replayFile(t, rotated[0])["timer"].Samples)
+	assert.Equal(t, int64(1), replayFile(t, path)["timer"].Samples)
+}
+
+func TestSampleFileWriteRotateError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples")
+	sf := &SampleFile{
+		Path:    path,
+		MaxSize: 20,
+		Clock:   &stepClock{now: time.Unix(1000, 0).UTC(), step: time.Second},
+	}
+	require.NoError(t, sf.Write(&Record{Timestamp: time.Unix(1000, 0), Duration: 50, Name: "timer"}))
+	require.NoError(t, os.Mkdir(path+".19700101T001641.000000000Z", 0o700))
+	require.NoError(t, os.WriteFile(path+".19700101T001641.000000000Z/file", nil, 0o600))
+
+	err := sf.Write(&Record{Timestamp: time.Unix(1000, 0), Duration: 50, Name: "timer"})
+
+	assert.Error(t, err)
+}
+
+func TestSampleFileWriteError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	sf := &SampleFile{Path: path, file: f}
+	sf.out = &countingWriter{w: f, n: &sf.size}
+
+	err = sf.Write(&Record{Timestamp: time.Unix(1000, 0), Duration: 50, Name: "timer"})
+
+	assert.Error(t, err)
+	assert.Equal(t, int64(0), sf.size)
+}
+
+func TestSampleFileRotateCloseError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	sf := &SampleFile{Path: path, file: f}
+
+	err = sf.rotate()
+
+	assert.Error(t, err)
+	assert.Nil(t, sf.file)
+}
+
+func TestCompressFileOpenError(t *testing.T) {
+	err := compressFile(filepath.Join(t.TempDir(), "missing"))
+
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCompressFileExists(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "samples")
+	require.NoError(t, os.WriteFile(name, nil, 0o600))
+	require.NoError(t, os.WriteFile(name+".gz", nil, 0o600))
+
+	err := compressFile(name)
+
+	assert.True(t, os.IsExist(err))
+}
+
+func TestSampleFileCloseUnopened(t *testing.T) {
+	sf := &SampleFile{}
+
+	err := sf.Close()
+
+	assert.NoError(t, err)
+}
+
+func TestSampleFileWriteLockCompressedError(t *testing.T) {
+	sf := &SampleFile{Path: "samples.gz", Lock: true, out: errWriter{}}
+
+	err := sf.write(testRecord.frame())
+
+	assert.ErrorIs(t, err, assert.AnError)
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package timeit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampleFileWriteLockShared(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples")
+	sf1 := &SampleFile{Path: path, Lock: true}
+	sf2 := &SampleFile{Path: path, Lock: true}
+
+	require.NoError(t, sf1.Write(testRecord))
+	require.NoError(t, sf2.Write(testRecord))
+	require.NoError(t, sf1.Write(testRecord))
+
+	assert.Equal(t, int64(3*len(testRecord.frame())), sf1.size)
+	assert.NoError(t, sf1.Close())
+	assert.NoError(t, sf2.Close())
+	assert.Equal(t, int64(3), replayFile(t, path)["timer"].Samples)
+}
+
+func TestSampleFileWriteLockRotated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples")
+	sf1 := &SampleFile{
+		Path:    path,
+		MaxSize: 30,
+		Lock:    true,
+		Clock:   &stepClock{now: time.Unix(1000, 0).UTC(), step: time.Second},
+	}
+	sf2 := &SampleFile{Path: path, MaxSize: 30, Lock: true}
+
+	require.NoError(t, sf1.Write(testRecord))
+	require.NoError(t, sf2.Write(testRecord))
+	require.NoError(t, sf1.Write(testRecord))
+	require.NoError(t, sf2.Write(testRecord))
+	assert.NoError(t, sf1.Close())
+	assert.NoError(t, sf2.Close())
+
+	rotated := rotatedFiles(t, path)
+	require.Equal(t, []string{path + ".19700101T001641.000000000Z"}, rotated)
+	assert.Equal(t, int64(2), replayFile(t, rotated[0])["timer"].Samples)
+	assert.Equal(t, int64(2), replayFile(t, path)["timer"].Samples)
+}
+
+func TestSampleFileWriteLockRemoved(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples")
+	sf := &SampleFile{Path: path, Lock: true}
+	require.NoError(t, sf.Write(testRecord))
+	require.NoError(t, os.Remove(path))
+
+	err := sf.Write(testRecord)
+
+	assert.NoError(t, err)
+	assert.NoError(t, sf.Close())
+	assert.Equal(t, int64(1), replayFile(t, path)["timer"].Samples)
+}
+
+func TestSampleFileWriteLockCompressed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples.gz")
+	sf1 := &SampleFile{Path: path, Lock: true}
+	sf2 := &SampleFile{Path: path, Lock: true}
+
+	require.NoError(t, sf1.Write(testRecord))
+	require.NoError(t, sf2.Write(testRecord))
+	require.NoError(t, sf1.Write(testRecord))
+
+	assert.Nil(t, sf1.gz)
+	assert.Equal(t, int64(3), replayFile(t, path)["timer"].Samples)
+	assert.NoError(t, sf1.Close())
+	assert.NoError(t, sf2.Close())
+	assert.Equal(t, int64(3), replayFile(t, path)["timer"].Samples)
+}
+
+func TestSampleFileWriteLockOpenError(t *testing.T) {
+	sf := &SampleFile{Path: filepath.Join(t.TempDir(), "missing", "samples"), Lock: true}
+
+	err := sf.Write(testRecord)
+
+	assert.True(t, os.IsNotExist(err))
+	assert.Nil(t, sf.file)
+}
+
+func TestSampleFileRotateLockRenameError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples")
+	sf := &SampleFile{Path: path, Lock: true}
+	require.NoError(t, sf.Write(testRecord))
+	require.NoError(t, os.Remove(path))
+
+	err := sf.rotate()
+
+	assert.True(t, os.IsNotExist(err))
+	assert.NotNil(t, sf.file)
+	assert.NoError(t, sf.Close())
+}
+
+func TestSampleFileUnlockError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	sf := &SampleFile{Path: path, Lock: true, file: f}
+
+	sf.unlock()
+
+	assert.Nil(t, sf.file)
+}
+
+func TestSampleFileLockError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	sf := &SampleFile{Path: path, Lock: true, file: f}
+
+	err = sf.lock()
+
+	assert.Error(t, err)
+	assert.Nil(t, sf.file)
+}
+
+func TestSampleFileRotateLockCloseError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	sf := &SampleFile{
+		Path:  path,
+		Lock:  true,
+		Clock: &stepClock{now: time.Unix(1000, 0).UTC()},
+		file:  f,
+	}
+
+	err = sf.rotate()
+
+	assert.Error(t, err)
+	assert.Nil(t, sf.file)
+	assert.Equal(t, []string{path + ".19700101T001640.000000000Z"}, rotatedFiles(t, path))
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// samplerWindow is the interval over which a Sampler measures the
+// update rate.
+const samplerWindow = time.Second
+
+// Sampler is an adaptive sampler that bounds the overhead of
+// recording samples under load.  The update rate is measured over
+// each one second window, and if it exceeds the Budget, only the
+// fraction of samples that fits within the budget is recorded in the
+// Data during the next window.  Ratio reports the effective sampling
+// ratio, so that counts derived from the Data can be scaled; the
+// mean, minimum, and maximum are estimates from the recorded samples.
+// A Sampler is safe for concurrent use, and the Data is updated while
+// the Sampler is locked, so the Data need not be synchronized as long
+// as it is only updated through the Sampler.
+type Sampler struct {
+	Budget   float64 // Samples to record per second; 0 for no limit
+	Data     *Data   // Data to update with the recorded samples
+	Clock    Clock   // Source of time; nil for WallClock
+	mu       sync.Mutex
+	start    time.Time
+	count    int64
+	fraction float64
+	acc      float64
+	seen     int64
+	recorded int64
+}
+
+// clock returns the Clock to use for the Sampler.
+func (s *Sampler) clock() Clock {
+	if s.Clock == nil {
+		return WallClock
+	}
+
+	return s.Clock
+}
+
+// adjust starts a new window if the current one has ended, computing
+// the sampling fraction from the update rate seen in the ended window.
+func (s *Sampler) adjust() {
+	now := s.clock().Now()
+	if s.start.IsZero() {
+		s.start = now
+		s.fraction = 1
+		return
+	}
+
+	elapsed := now.Sub(s.start)
+	if elapsed < 0 {
+		// The clock was stepped backwards; restart the window
+		corrected(elapsed)
+		s.start = now
+		s.count = 0
+		return
+	} else if elapsed < samplerWindow {
+		return
+	}
+	s.fraction = 1
+	if rate := float64(s.count) / elapsed.Seconds(); s.Budget > 0 && rate > s.Budget {
+		s.fraction = s.Budget / rate
+	}
+	s.start = now
+	s.count = 0
+}
+
+// Update records the sample in the Data if it is selected by the
+// sampler, returning true if it was recorded.  Samples are selected
+// deterministically, evenly spaced at the current sampling fraction.
+func (s *Sampler) Update(sample time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.adjust()
+	s.count++
+	s.seen++
+
+	s.acc += s.fraction
+	if s.acc < 1 {
+		return false
+	}
+	s.acc = math.Mod(s.acc, 1)
+
+	s.recorded++
+	s.Data.Update(sample)
+
+	return true
+}
+
+// Seen returns the number of samples passed to Update.
+func (s *Sampler) Seen() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.seen
+}
+
+// Ratio returns the effective sampling ratio: the fraction of the
+// samples passed to Update that were recorded.  Dividing a count
+// derived from the Data by the ratio estimates the count for all the
+// samples.  Returns 1 if no samples have been seen.
+func (s *Sampler) Ratio() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.seen == 0 {
+		return 1
+	}
+
+	return float64(s.recorded) / float64(s.seen)
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build !timeitnoop
+// +build !timeitnoop
+
+package timeit
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSamplerClockDefault(t *testing.T) {
+	s := &Sampler{}
+
+	assert.Equal(t, WallClock, s.clock())
+}
+
+func TestSamplerRatioEmpty(t *testing.T) {
+	s := &Sampler{}
+
+	assert.Equal(t, 1.0, s.Ratio())
+}
+
+func TestSamplerUpdateUnderBudget(t *testing.T) {
+	d := &Data{}
+	s := &Sampler{
+		Budget: 100,
+		Data:   d,
+		Clock:  &FakeClock{Time: time.Unix(1000, 0), Step: 100 * time.Millisecond},
+	}
+
+	for i := 0; i < 30; i++ {
+		assert.True(t, s.Update(10))
+	}
+
+	assert.Equal(t, int64(30), d.Samples)
+	assert.Equal(t, int64(30), s.Seen())
+	assert.Equal(t, 1.0, s.Ratio())
+}
+
+func TestSamplerUpdateOverBudget(t *testing.T) {
+	d := &Data{}
+	s := &Sampler{
+		Budget: 25,
+		Data:   d,
+		Clock:  &FakeClock{Time: time.Unix(1000, 0), Step: 10 * time.Millisecond},
+	}
+
+	// The first window records everything
+	for i := 0; i < 100; i++ {
+		s.Update(10)
+	}
+	first := d.Samples
+
+	// Subsequent windows record a quarter of the samples
+	for i := 0; i < 200; i++ {
+		s.Update(10)
+	}
+
+	assert.Equal(t, int64(100), first)
+	assert.Equal(t, int64(150), d.Samples)
+	assert.Equal(t, int64(300), s.Seen())
+	assert.Equal(t, 0.5, s.Ratio())
+}
+
+func TestSamplerUpdateNoBudget(t *testing.T) {
+	d := &Data{}
+	s := &Sampler{
+		Data:  d,
+		Clock: &FakeClock{Time: time.Unix(1000, 0), Step: time.Millisecond},
+	}
+
+	for i := 0; i < 3000; i++ {
+		s.Update(10)
+	}
+
+	assert.Equal(t, int64(3000), d.Samples)
+	assert.Equal(t, 1.0, s.Ratio())
+}
+
+func TestSamplerUpdateClockStepped(t *testing.T) {
+	before := SkewCorrections()
+	clock := &FakeClock{Time: time.Unix(1000, 0)}
+	s := &Sampler{Budget: 1, Data: &Data{}, Clock: clock}
+	s.Update(10)
+	s.Update(10)
+
+	clock.Time = time.Unix(900, 0)
+	s.Update(10)
+	s.Update(10)
+	clock.Time = time.Unix(901, 0)
+	s.Update(10)
+
+	assert.Equal(t, time.Unix(901, 0), s.start)
+	assert.Equal(t, int64(1), s.count)
+	assert.Equal(t, 0.5, s.fraction)
+	assert.Equal(t, before+1, SkewCorrections())
+}
+
+func TestSamplerUpdateConcurrent(t *testing.T) {
+	d := &Data{}
+	s := &Sampler{
+		Data:  d,
+		Clock: &FakeClock{Time: time.Unix(1000, 0), Step: time.Millisecond},
+	}
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				s.Update(10)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, int64(1000), s.Seen())
+	assert.Equal(t, int64(1000), d.Samples)
+	assert.Equal(t, 1.0, s.Ratio())
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Mutex is a sync.Mutex that records the time spent waiting to
+// acquire the lock.  The Data is updated while the lock is held, so
+// it is safe for the Data to be shared only by callers holding the
+// lock.
+type Mutex struct {
+	sync.Mutex
+	Wait *Data // Data to update with lock wait times; may be nil
+}
+
+// Lock locks the mutex, recording the time spent waiting for it.
+func (m *Mutex) Lock() {
+	if m.Wait == nil {
+		m.Mutex.Lock()
+		return
+	}
+
+	clock := m.Wait.clock()
+	start := clock.Now()
+	m.Mutex.Lock()
+	m.Wait.Update(elapsed(clock, start))
+}
+
+// RWMutex is a sync.RWMutex that records the time spent waiting to
+// acquire the lock.  Since multiple readers may hold the lock
+// simultaneously, updates to the Data are serialized by an internal
+// mutex.
+type RWMutex struct {
+	sync.RWMutex
+	Wait  *Data // Data to update with write lock wait times; may be nil
+	RWait *Data // Data to update with read lock wait times; may be nil
+	mu    sync.Mutex
+}
+
+// update updates the specified data.
+func (rw *RWMutex) update(d *Data, sample time.Duration) {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	d.Update(sample)
+}
+
+// Lock locks the mutex for writing, recording the time spent waiting
+// for it.
+func (rw *RWMutex) Lock() {
+	if rw.Wait == nil {
+		rw.RWMutex.Lock()
+		return
+	}
+
+	clock := rw.Wait.clock()
+	start := clock.Now()
+	rw.RWMutex.Lock()
+	rw.update(rw.Wait, elapsed(clock, start))
+}
+
+// RLock locks the mutex for reading, recording the time spent
+// waiting for it.
+func (rw *RWMutex) RLock() {
+	if rw.RWait == nil {
+		rw.RWMutex.RLock()
+		return
+	}
+
+	clock := rw.RWait.clock()
+	start := clock.Now()
+	rw.RWMutex.RLock()
+	rw.update(rw.RWait, elapsed(clock, start))
+}
+
+// TimeSend sends a value on a channel, updating the data with the
+// time spent waiting for the send to complete.  The ch parameter must
+// be a channel to which value can be sent; TimeSend panics otherwise.
+// As with TimeIt, it returns the time the send took.
+func (d *Data) TimeSend(ch, value interface{}) time.Duration {
+	chV := reflect.ValueOf(ch)
+	valueV := reflect.ValueOf(value)
+	if value == nil {
+		valueV = reflect.Zero(chV.Type().Elem())
+	}
+
+	return d.TimeIt(func() { chV.Send(valueV) })
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build !timeitnoop
+// +build !timeitnoop
+
+package timeit
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMutexLockNoData(t *testing.T) {
+	m := &Mutex{}
+
+	m.Lock()
+	m.Unlock() //nolint:staticcheck
+}
+
+func TestMutexLock(t *testing.T) {
+	m := &Mutex{
+		Wait: &Data{Clock: &testClock{delta: 50}},
+	}
+
+	m.Lock()
+	m.Unlock() //nolint:staticcheck
+
+	assert.Equal(t, int64(1), m.Wait.Samples)
+	assert.Equal(t, time.Duration(50), m.Wait.Mean)
+}
+
+func TestMutexLockContended(t *testing.T) {
+	m := &Mutex{Wait: &Data{}}
+	m.Lock()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.Lock()
+		m.Unlock() //nolint:staticcheck
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	m.Unlock()
+	<-done
+
+	assert.Equal(t, int64(2), m.Wait.Samples)
+	assert.GreaterOrEqual(t, int64(m.Wait.Max), int64(10*time.Millisecond))
+}
+
+func TestRWMutexLockNoData(t *testing.T) {
+	rw := &RWMutex{}
+
+	rw.Lock()
+	rw.Unlock() //nolint:staticcheck
+}
+
+func TestRWMutexLock(t *testing.T) {
+	rw := &RWMutex{
+		Wait: &Data{Clock: &testClock{delta: 50}},
+	}
+
+	rw.Lock()
+	rw.Unlock() //nolint:staticcheck
+
+	assert.Equal(t, int64(1), rw.Wait.Samples)
+	assert.Equal(t, time.Duration(50), rw.Wait.Mean)
+}
+
+func TestRWMutexRLockNoData(t *testing.T) {
+	rw := &RWMutex{}
+
+	rw.RLock()
+	rw.RUnlock() //nolint:staticcheck
+}
+
+func TestRWMutexRLock(t *testing.T) {
+	rw := &RWMutex{
+		RWait: &Data{Clock: &testClock{delta: 50}},
+	}
+
+	rw.RLock()
+	rw.RUnlock() //nolint:staticcheck
+
+	assert.Equal(t, int64(1), rw.RWait.Samples)
+	assert.Equal(t, time.Duration(50), rw.RWait.Mean)
+}
+
+func TestRWMutexRLockConcurrent(t *testing.T) {
+	rw := &RWMutex{RWait: &Data{}}
+	wg := &sync.WaitGroup{}
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rw.RLock()
+			defer rw.RUnlock()
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(10), rw.RWait.Samples)
+}
+
+func TestDataTimeSend(t *testing.T) {
+	d := &Data{Clock: &testClock{delta: 50}}
+	ch := make(chan int, 1)
+
+	result := d.TimeSend(ch, 5)
+
+	assert.Equal(t, time.Duration(50), result)
+	assert.Equal(t, 5, <-ch)
+	assert.Equal(t, int64(1), d.Samples)
+}
+
+func TestDataTimeSendNil(t *testing.T) {
+	d := &Data{Clock: &testClock{delta: 50}}
+	ch := make(chan error, 1)
+
+	d.TimeSend(ch, nil)
+
+	assert.Nil(t, <-ch)
+	assert.Equal(t, int64(1), d.Samples)
+}
+
+func TestDataTimeSendNotChannel(t *testing.T) {
+	d := &Data{}
+
+	assert.Panics(t, func() {
+		d.TimeSend(5, 5)
+	})
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// maxTestJSONLine is the maximum length of a line of "go test -json"
+// output; output lines may be long, so this is larger than the
+// bufio.Scanner default.
+const maxTestJSONLine = 1024 * 1024
+
+// testEvent is an event emitted by "go test -json"; only the fields
+// of interest are decoded.
+type testEvent struct {
+	Action  string  `json:"Action"`
+	Package string  `json:"Package"`
+	Test    string  `json:"Test"`
+	Elapsed float64 `json:"Elapsed"`
+}
+
+// name returns the name of the Data the event applies to.  Tests are
+// named by qualifying the test name with the package, e.g.,
+// "example.com/pkg.TestFoo/subtest"; packages are named by their
+// import path.
+func (ev *testEvent) name() string {
+	if ev.Test == "" {
+		return ev.Package
+	}
+
+	return ev.Package + "." + ev.Test
+}
+
+// ReadTestJSON reads the output of "go test -json" (or "go tool
+// test2json") and updates the Data in the dataset with the durations
+// of each test and package that passed or failed; skipped tests are
+// ignored.  As with ReplayJSON, Data not already present in the
+// dataset will be created.  Lines that are not JSON, such as build
+// failure messages, are ignored.
+func ReadTestJSON(r io.Reader, dataset map[string]*Data) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxTestJSONLine)
+	for line := 1; scanner.Scan(); line++ {
+		// Skip lines that aren't JSON objects
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 || text[0] != '{' {
+			continue
+		}
+
+		// Decode the event
+		ev := &testEvent{}
+		if err := json.Unmarshal(text, ev); err != nil {
+			return fmt.Errorf("%w: line %d: %s", ErrBadRecord, line, err)
+		}
+		if ev.Action != "pass" && ev.Action != "fail" {
+			continue
+		}
+
+		(&Record{
+			Duration: time.Duration(ev.Elapsed * float64(time.Second)),
+			Name:     ev.name(),
+		}).replay(dataset)
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestEventNamePackage(t *testing.T) {
+	ev := &testEvent{Package: "example.com/pkg"}
+
+	assert.Equal(t, "example.com/pkg", ev.name())
+}
+
+func TestTestEventNameTest(t *testing.T) {
+	ev := &testEvent{Package: "example.com/pkg", Test: "TestFoo/sub"}
+
+	assert.Equal(t, "example.com/pkg.TestFoo/sub", ev.name())
+}
+
+func TestReadTestJSONBase(t *testing.T) {
+	text := strings.NewReader(`# example.com/broken
+{"Action":"run","Package":"example.com/pkg","Test":"TestFoo"}
+{"Action":"output","Package":"example.com/pkg","Test":"TestFoo","Output":"=== RUN   TestFoo\n"}
+{"Action":"pass","Package":"example.com/pkg","Test":"TestFoo","Elapsed":0.5}
+
+{"Action":"skip","Package":"example.com/pkg","Test":"TestBar","Elapsed":0}
+{"Action":"fail","Package":"example.com/pkg","Test":"TestBaz","Elapsed":0.25}
+{"Action":"pass","Package":"example.com/pkg","Elapsed":1.5}
+`)
+	dataset := map[string]*Data{}
+
+	err := ReadTestJSON(text, dataset)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*Data{
+		"example.com/pkg.TestFoo": {
+			Samples: 1,
+			Mean:    500 * time.Millisecond,
+			Max:     500 * time.Millisecond,
+			Min:     500 * time.Millisecond,
+		},
+		"example.com/pkg.TestBaz": {
+			Samples: 1,
+			Mean:    250 * time.Millisecond,
+			Max:     250 * time.Millisecond,
+			Min:     250 * time.Millisecond,
+		},
+		"example.com/pkg": {
+			Samples: 1,
+			Mean:    1500 * time.Millisecond,
+			Max:     1500 * time.Millisecond,
+			Min:     1500 * time.Millisecond,
+		},
+	}, dataset)
+}
+
+func TestReadTestJSONBadEvent(t *testing.T) {
+	text := strings.NewReader(`{"Action":"pass","Package":"example.com/pkg","Elapsed":"1.5"}
+`)
+	dataset := map[string]*Data{}
+
+	err := ReadTestJSON(text, dataset)
+
+	assert.ErrorIs(t, err, ErrBadRecord)
+	assert.Contains(t, err.Error(), "line 1")
+	assert.Equal(t, map[string]*Data{}, dataset)
+}
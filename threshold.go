@@ -0,0 +1,131 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"sort"
+	"time"
+)
+
+// overCounts maps thresholds to the counts of samples over them, for
+// marshaling.
+type overCounts map[time.Duration]int64
+
+// counts returns the counts of samples over each of the Thresholds,
+// allocating them if necessary.  Changing the Thresholds resets the
+// counts.
+func (d *Data) counts() []int64 {
+	if len(d.over) != len(d.Thresholds) {
+		d.over = make([]int64, len(d.Thresholds))
+	}
+
+	return d.over
+}
+
+// countOver counts the sample against each of the Thresholds it
+// exceeds.
+func (d *Data) countOver(sample time.Duration) {
+	if len(d.Thresholds) == 0 {
+		return
+	}
+
+	over := d.counts()
+	for i, threshold := range d.Thresholds {
+		if sample > threshold {
+			over[i]++
+		}
+	}
+}
+
+// Over returns the number of samples that exceeded the specified
+// threshold, which must be one of the Thresholds, and true; if the
+// threshold is not one of the Thresholds, it returns 0 and false.
+func (d *Data) Over(threshold time.Duration) (int64, bool) {
+	for i, t := range d.Thresholds {
+		if t == threshold {
+			if i < len(d.over) {
+				return d.over[i], true
+			}
+			return 0, true
+		}
+	}
+
+	return 0, false
+}
+
+// PercentOver returns the percentage of the samples that exceeded the
+// specified threshold, answering questions such as "what fraction of
+// calls were slower than 100ms" without the need for a histogram or
+// retained samples.  Samples are only counted against the thresholds
+// listed in Thresholds when they are recorded; if the threshold is
+// not one of the Thresholds, it returns 0 and false.
+func (d *Data) PercentOver(threshold time.Duration) (float64, bool) {
+	count, ok := d.Over(threshold)
+	if !ok || d.Samples <= 0 {
+		return 0, ok
+	}
+
+	return 100 * float64(count) / float64(d.Samples), true
+}
+
+// overMarshaled returns the counts of samples over each of the
+// Thresholds, or nil if there are no Thresholds.
+func (d *Data) overMarshaled() overCounts {
+	if len(d.Thresholds) == 0 {
+		return nil
+	}
+
+	result := make(overCounts, len(d.Thresholds))
+	for _, threshold := range d.Thresholds {
+		result[threshold], _ = d.Over(threshold)
+	}
+
+	return result
+}
+
+// setOver sets the Thresholds and the counts of samples over them.
+func (d *Data) setOver(over overCounts) {
+	if len(over) == 0 {
+		return
+	}
+
+	d.Thresholds = make([]time.Duration, 0, len(over))
+	for threshold := range over {
+		d.Thresholds = append(d.Thresholds, threshold)
+	}
+	sort.Slice(d.Thresholds, func(i, j int) bool { return d.Thresholds[i] < d.Thresholds[j] })
+
+	d.over = make([]int64, len(d.Thresholds))
+	for i, threshold := range d.Thresholds {
+		d.over[i] = over[threshold]
+	}
+}
+
+// mergeOver merges the counts of samples over the Thresholds of
+// another Data.  If this Data has no Thresholds, those of the other
+// Data are adopted; otherwise, counts for thresholds the other Data
+// does not track are left unchanged, and so undercount.
+func (d *Data) mergeOver(other *Data) {
+	if len(d.Thresholds) == 0 {
+		d.setOver(other.overMarshaled())
+		return
+	}
+
+	over := d.counts()
+	for i, threshold := range d.Thresholds {
+		count, _ := other.Over(threshold)
+		over[i] += count
+	}
+}
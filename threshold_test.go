@@ -0,0 +1,198 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build !timeitnoop
+// +build !timeitnoop
+
+package timeit
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestDataCountOverNoThresholds(t *testing.T) {
+	d := &Data{}
+
+	d.countOver(10)
+
+	assert.Nil(t, d.over)
+}
+
+func TestDataCountOverBase(t *testing.T) {
+	d := &Data{Thresholds: []time.Duration{10, 20}}
+
+	for _, s := range []time.Duration{5, 10, 15, 25} {
+		d.Update(s)
+	}
+
+	assert.Equal(t, []int64{2, 1}, d.over)
+}
+
+func TestDataCountOverReset(t *testing.T) {
+	d := &Data{Thresholds: []time.Duration{10}}
+	d.Update(15)
+
+	d.Thresholds = []time.Duration{10, 20}
+	d.Update(25)
+
+	assert.Equal(t, []int64{1, 1}, d.over)
+}
+
+func TestDataOverBase(t *testing.T) {
+	d := &Data{Thresholds: []time.Duration{10, 20}, over: []int64{3, 1}}
+
+	result, ok := d.Over(20)
+
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), result)
+}
+
+func TestDataOverNoSamples(t *testing.T) {
+	d := &Data{Thresholds: []time.Duration{10, 20}}
+
+	result, ok := d.Over(20)
+
+	assert.True(t, ok)
+	assert.Equal(t, int64(0), result)
+	assert.Nil(t, d.over)
+}
+
+func TestDataOverUnknown(t *testing.T) {
+	d := &Data{Thresholds: []time.Duration{10, 20}, over: []int64{3, 1}}
+
+	result, ok := d.Over(15)
+
+	assert.False(t, ok)
+	assert.Equal(t, int64(0), result)
+}
+
+func TestDataPercentOverBase(t *testing.T) {
+	d := &Data{Thresholds: []time.Duration{100 * time.Millisecond}}
+	for _, s := range []time.Duration{50, 150, 80, 250} {
+		d.Update(s * time.Millisecond)
+	}
+
+	result, ok := d.PercentOver(100 * time.Millisecond)
+
+	assert.True(t, ok)
+	assert.Equal(t, 50.0, result)
+}
+
+func TestDataPercentOverNoSamples(t *testing.T) {
+	d := &Data{Thresholds: []time.Duration{10}}
+
+	result, ok := d.PercentOver(10)
+
+	assert.True(t, ok)
+	assert.Equal(t, 0.0, result)
+}
+
+func TestDataPercentOverUnknown(t *testing.T) {
+	d := &Data{Samples: 4, Thresholds: []time.Duration{10}, over: []int64{2}}
+
+	result, ok := d.PercentOver(20)
+
+	assert.False(t, ok)
+	assert.Equal(t, 0.0, result)
+}
+
+func TestDataUpdateCheckedThresholds(t *testing.T) {
+	d := &Data{Thresholds: []time.Duration{10}}
+
+	err := d.UpdateChecked(20)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1}, d.over)
+}
+
+func TestDataOverMarshaledEmpty(t *testing.T) {
+	d := &Data{}
+
+	assert.Nil(t, d.overMarshaled())
+}
+
+func TestDataThresholdsMarshalJSON(t *testing.T) {
+	d := &Data{Flags: StdDev, Thresholds: []time.Duration{10, 20}}
+	d.Update(15)
+
+	text, err := json.Marshal(d)
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+	"samples": 1,
+	"mean": 15,
+	"max": 15,
+	"min": 15,
+	"std_dev": 0,
+	"over": {"10": 1, "20": 0}
+}`, string(text))
+	result := &Data{}
+	err = json.Unmarshal(text, result)
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{10, 20}, result.Thresholds)
+	assert.Equal(t, []int64{1, 0}, result.over)
+}
+
+func TestDataThresholdsMarshalYAML(t *testing.T) {
+	d := &Data{Flags: StdDev, Thresholds: []time.Duration{time.Millisecond}}
+	d.Update(2 * time.Millisecond)
+
+	text, err := yaml.Marshal(d)
+
+	require.NoError(t, err)
+	assert.Contains(t, string(text), "over:\n  1ms: 1\n")
+	result := &Data{}
+	err = yaml.Unmarshal(text, result)
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{time.Millisecond}, result.Thresholds)
+	assert.Equal(t, []int64{1}, result.over)
+}
+
+func TestDataMergeOverAdopt(t *testing.T) {
+	d := &Data{}
+	other := &Data{Thresholds: []time.Duration{20, 10}}
+	other.Update(15)
+
+	d.Merge(other)
+
+	assert.Equal(t, []time.Duration{10, 20}, d.Thresholds)
+	assert.Equal(t, []int64{1, 0}, d.over)
+}
+
+func TestDataMergeOverCommon(t *testing.T) {
+	d := &Data{Thresholds: []time.Duration{10, 30}}
+	d.Update(35)
+	other := &Data{Thresholds: []time.Duration{10, 20}}
+	other.Update(25)
+
+	d.Merge(other)
+
+	assert.Equal(t, []time.Duration{10, 30}, d.Thresholds)
+	assert.Equal(t, []int64{2, 1}, d.over)
+}
+
+func TestDataCheckInvariantsOver(t *testing.T) {
+	d := &Data{Samples: 1, Mean: 10, Max: 10, Min: 10, Thresholds: []time.Duration{5}, over: []int64{2}}
+
+	err := d.CheckInvariants()
+
+	assert.ErrorIs(t, err, ErrInvariant)
+	assert.EqualError(t, err, "invariant violated: count 2 over threshold 5ns")
+}
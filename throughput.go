@@ -0,0 +1,99 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import "time"
+
+// Rate contains accumulated statistics for a rate, such as the items
+// or bytes processed per second, computed from each sample.  Rates
+// are accumulated alongside the durations in a Data by
+// UpdateThroughput; they are the natural unit for I/O and codec
+// benchmarks, and averaging the per-sample rates gives a different,
+// and usually more useful, answer than dividing the total count by
+// the mean duration.  The Flags of a Rate are not used; the sample
+// standard deviation is always included when the Data is marshaled.
+type Rate = Value
+
+// rateMarshaled contains the Rate, along with its standard deviation,
+// which will then be marshaled into either JSON or YAML.
+type rateMarshaled struct {
+	Samples      int64   `json:"samples" yaml:"samples"`
+	Mean         float64 `json:"mean" yaml:"mean"`
+	Max          float64 `json:"max" yaml:"max"`
+	Min          float64 `json:"min" yaml:"min"`
+	SampleStdDev float64 `json:"sample_std_dev" yaml:"sample_std_dev"`
+}
+
+// newRateMarshaled constructs a rateMarshaled structure from the
+// Rate, or returns nil if the Rate has no samples.
+func newRateMarshaled(r *Rate) *rateMarshaled {
+	if r.Samples == 0 {
+		return nil
+	}
+
+	return &rateMarshaled{
+		Samples:      r.Samples,
+		Mean:         r.Mean,
+		Max:          r.Max,
+		Min:          r.Min,
+		SampleStdDev: r.SampleStdDev(),
+	}
+}
+
+// toRate converts a rateMarshaled instance back into a Rate instance.
+func (rm *rateMarshaled) toRate(r *Rate) {
+	if rm == nil {
+		return
+	}
+
+	*r = Rate{
+		Samples: rm.Samples,
+		Mean:    rm.Mean,
+		Max:     rm.Max,
+		Min:     rm.Min,
+	}
+	if rm.Samples > 1 {
+		r.m2 = rm.SampleStdDev * rm.SampleStdDev * float64(rm.Samples-1)
+	}
+}
+
+// updateRates updates the item and byte rates of the Data from a
+// validated sample.  Counts that are not positive, and samples of
+// zero duration, do not contribute to the rates.
+func (d *Data) updateRates(s Sample) {
+	if s.Duration <= 0 {
+		return
+	}
+
+	secs := s.Duration.Seconds()
+	if s.Items > 0 {
+		d.ItemRate.Update(float64(s.Items) / secs)
+	}
+	if s.Bytes > 0 {
+		d.ByteRate.Update(float64(s.Bytes) / secs)
+	}
+}
+
+// UpdateThroughput is similar to Update, but also records the number
+// of items and bytes processed during the sample, accumulating the
+// rates in ItemRate and ByteRate.  Either count may be 0 if it is not
+// meaningful for the workload.
+func (d *Data) UpdateThroughput(sample time.Duration, items, bytes int64) {
+	if !Enabled() {
+		return
+	}
+
+	d.updateSample(Sample{Duration: sample, Items: items, Bytes: bytes})
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build !timeitnoop
+// +build !timeitnoop
+
+package timeit
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataUpdateThroughput(t *testing.T) {
+	d := &Data{Retain: 1, Clock: &testClock{now: time.Unix(1000, 0)}}
+
+	d.UpdateThroughput(time.Second, 100, 1000)
+	d.UpdateThroughput(2*time.Second, 100, 0)
+	d.UpdateThroughput(0, 100, 1000)
+
+	assert.Equal(t, int64(3), d.Samples)
+	assert.Equal(t, Rate{Samples: 2, Mean: 75, Max: 100, Min: 50, m2: 1250}, d.ItemRate)
+	assert.Equal(t, Rate{Samples: 1, Mean: 1000, Max: 1000, Min: 1000}, d.ByteRate)
+	assert.Equal(t, []Sample{
+		{At: time.Unix(1000, 0), Items: 100, Bytes: 1000},
+	}, d.Retained())
+}
+
+func TestDataUpdateThroughputNext(t *testing.T) {
+	next := &Data{}
+	d := &Data{Next: next, Limit: time.Millisecond, Invalid: ClampInvalid}
+
+	d.UpdateThroughput(time.Second, 100, 0)
+
+	assert.Equal(t, Rate{Samples: 1, Mean: 100000, Max: 100000, Min: 100000}, d.ItemRate)
+	assert.Equal(t, Rate{Samples: 1, Mean: 100, Max: 100, Min: 100}, next.ItemRate)
+}
+
+func TestDataMergeRates(t *testing.T) {
+	d := &Data{}
+	d.UpdateThroughput(time.Second, 10, 0)
+	other := &Data{}
+	other.UpdateThroughput(time.Second, 30, 0)
+
+	d.Merge(other)
+
+	assert.Equal(t, Rate{Samples: 2, Mean: 20, Max: 30, Min: 10, m2: 200}, d.ItemRate)
+}
+
+func TestDataRatesRoundTrip(t *testing.T) {
+	d := &Data{Flags: SampleStdDev}
+	d.UpdateThroughput(time.Second, 10, 1000)
+	d.UpdateThroughput(time.Second, 30, 1000)
+
+	text, err := json.Marshal(d)
+	require.NoError(t, err)
+	result := &Data{}
+	err = json.Unmarshal(text, result)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"samples": 2, "mean": 1000000000, "max": 1000000000, "min": 1000000000,
+		"sample_std_dev": 0,
+		"item_rate": {"samples": 2, "mean": 20, "max": 30, "min": 10, "sample_std_dev": 14.142135623730951},
+		"byte_rate": {"samples": 2, "mean": 1000, "max": 1000, "min": 1000, "sample_std_dev": 0}
+	}`, string(text))
+	assert.Equal(t, d.ItemRate.Samples, result.ItemRate.Samples)
+	assert.InDelta(t, d.ItemRate.m2, result.ItemRate.m2, 1e-9)
+	assert.Equal(t, d.ByteRate, result.ByteRate)
+}
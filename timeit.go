@@ -33,19 +33,111 @@ const (
 	SampleStdDev                            // Include SampleStdDev
 )
 
-// Data contains the accumulated timing data.
+// Data contains the accumulated timing data.  A Data is not safe for
+// concurrent use: callers sharing one among goroutines must guard it,
+// including any changes to its configuration, such as the Flags or
+// Thresholds, or give each goroutine its own Data and combine them
+// with Merge.
 type Data struct {
-	Samples int64         // The number of samples developed so far
-	Mean    time.Duration // The current running mean
-	Max     time.Duration // Maximum sample seen so far
-	Min     time.Duration // Minimum sample seen so far
-	Flags   MarshalFlags  // Bitmask of computed fields to marshal
-	Next    *Data         // Another Data instance to update
-	m2      time.Duration // Sum of square differences
+	Samples        int64           // The number of samples developed so far
+	Mean           time.Duration   // The current running mean
+	Max            time.Duration   // Maximum sample seen so far
+	Min            time.Duration   // Minimum sample seen so far
+	MinAt          time.Time       // When the minimum was taken; see Extremes
+	MaxAt          time.Time       // When the maximum was taken; see Extremes
+	InvalidSamples int64           // The number of invalid samples seen
+	Rejected       int64           // The number of samples rejected by Filter
+	Overruns       int64           // The number of overrunning runs; see Periodic
+	Skipped        int64           // The number of skipped runs; see Periodic
+	Overflowed     bool            // Set if the statistics overflowed
+	Partial        bool            // Set if the measurement was interrupted
+	Flags          MarshalFlags    // Bitmask of computed fields to marshal
+	Next           *Data           // Another Data instance to update
+	Clock          Clock           // Source of time for TimeIt; nil for WallClock
+	Invalid        InvalidPolicy   // How to handle invalid samples
+	Limit          time.Duration   // Largest valid sample; 0 for no limit
+	Filter         FilterFunc      // Selects samples to record; nil for all
+	Retain         int             // Number of raw samples to retain; 0 for none
+	Extremes       bool            // Set to record when the extremes were taken
+	Maxima         int             // Number of previous maxima to keep with Extremes
+	Thresholds     []time.Duration // Thresholds to count samples over; see PercentOver
+	ItemRate       Rate            // Items processed per second; see UpdateThroughput
+	ByteRate       Rate            // Bytes processed per second; see UpdateThroughput
+	m2             time.Duration   // Sum of square differences
+	frozen         bool            // Set if the Data is read-only
+	retained       []Sample        // Buffer of retained samples
+	retainPos      int             // Position of the oldest retained sample
+	maxima         []Sample        // Previous maxima, oldest first
+	over           []int64         // Counts of samples over the Thresholds
+	cost           time.Duration   // Cached estimate of the UpdateCost
 }
 
-// Update adds another sample to the Data structure.
+// Update adds another sample to the Data structure.  Invalid samples
+// are handled as described by the Data's InvalidPolicy, and samples
+// are discarded if the Data is frozen or instrumentation is disabled
+// (see Enable).  The original sample is passed on to Next, which
+// applies its own policy.
 func (d *Data) Update(sample time.Duration) {
+	d.UpdateAt(time.Time{}, sample)
+}
+
+// UpdateAt is similar to Update, but allows the time at which the
+// sample was taken to be specified; this is used by retention (see
+// Retained), and is useful when replaying recorded samples.  If the
+// time is the zero time, the current time from the Data's Clock is
+// used.
+func (d *Data) UpdateAt(at time.Time, sample time.Duration) {
+	if !Enabled() {
+		return
+	}
+
+	d.updateSample(Sample{At: at, Duration: sample})
+}
+
+// UpdateTagged is similar to Update, but allows an opaque tag, such
+// as a request ID, to be associated with the sample.  The tag is
+// recorded with the sample if samples are being retained (see
+// Retained), allowing outliers to be joined back to logs and traces.
+func (d *Data) UpdateTagged(tag interface{}, sample time.Duration) {
+	if !Enabled() {
+		return
+	}
+
+	d.updateSample(Sample{Duration: sample, Tag: tag})
+}
+
+// updateSample adds a sample, with its timestamp and tag, to the
+// Data structure, then passes it on to Next.  Unlike Update, it
+// ignores Enable, so that the importers and analyses, which build
+// Data from recorded samples, work even if instrumentation is
+// disabled.
+func (d *Data) updateSample(s Sample) {
+	if !d.checkFrozen() {
+		if valid, ok := d.validate(s.Duration); ok && d.accept(valid) {
+			rec := s
+			rec.Duration = valid
+			d.record(rec)
+		}
+	}
+
+	// Pass the sample on to Next
+	if d.Next != nil {
+		d.Next.updateSample(s)
+	}
+}
+
+// record adds a sample that has been validated and accepted by the
+// Filter to the Data structure.
+func (d *Data) record(s Sample) {
+	d.extremes(s)
+	d.update(s.Duration)
+	d.countOver(s.Duration)
+	d.updateRates(s)
+	d.retain(s)
+}
+
+// update adds a validated sample to the Data structure.
+func (d *Data) update(sample time.Duration) {
 	// Keep track of minimum and maximum
 	if d.Samples == 0 || sample < d.Min {
 		d.Min = sample
@@ -54,16 +146,24 @@ func (d *Data) Update(sample time.Duration) {
 		d.Max = sample
 	}
 
-	// Update the sample count, mean, and m2 values
+	// Update the sample count; if it would overflow, the
+	// statistics are left as they are
+	if d.Samples == math.MaxInt64 {
+		d.Overflowed = true
+		return
+	}
 	d.Samples++
+
+	// Update the mean and m2 values; m2 saturates rather than
+	// wrapping, which can happen with samples of a few seconds
 	delta1 := sample - d.Mean
 	d.Mean = d.Mean + delta1/time.Duration(d.Samples)
 	delta2 := sample - d.Mean
-	d.m2 = d.m2 + delta1*delta2
-
-	// Pass the sample on to Next
-	if d.Next != nil {
-		d.Next.Update(sample)
+	prod, mulOver := mulSat(delta1, delta2)
+	m2, addOver := addSat(d.m2, prod)
+	d.m2 = m2
+	if mulOver || addOver {
+		d.Overflowed = true
 	}
 }
 
@@ -105,15 +205,72 @@ func (d *Data) SampleStdDev() time.Duration {
 	return time.Duration(math.Sqrt(float64(d.SampleVariance())))
 }
 
+// clock returns the Clock to use for the Data.
+func (d *Data) clock() Clock {
+	if d.Clock == nil {
+		return WallClock
+	}
+
+	return d.Clock
+}
+
 // TimeIt runs a function and updates the data with the time it took
 // for the function to execute.  It returns the time it took for the
 // function to execute.
-func (d *Data) TimeIt(fn func()) (delta time.Duration) {
+func (d *Data) TimeIt(fn func()) time.Duration {
+	return d.TimeItWith(d.clock(), fn)
+}
+
+// TimeItWith is similar to TimeIt, but uses the specified Clock to
+// measure the time it took for the function to execute, rather than
+// the Clock configured on the Data.
+func (d *Data) TimeItWith(clock Clock, fn func()) time.Duration {
+	return d.timeIt(clock, nil, fn)
+}
+
+// TimeItTagged is similar to TimeIt, but allows an opaque tag, such
+// as a request ID, to be associated with the sample; see
+// UpdateTagged.
+func (d *Data) TimeItTagged(tag interface{}, fn func()) time.Duration {
+	return d.timeIt(d.clock(), tag, fn)
+}
+
+// Measure starts timing a region, returning a function that stops
+// timing it, updates the data with the time it took, and returns
+// that time.  This allows a region to be timed with a single line:
+//
+//	defer d.Measure()()
+//
+// The returned function should be called only once.
+func (d *Data) Measure() func() time.Duration {
+	if !Enabled() {
+		return func() time.Duration { return 0 }
+	}
+
+	clock := d.clock()
+	start := clock.Now()
+
+	return func() time.Duration {
+		delta := elapsed(clock, start)
+		d.updateSample(Sample{At: start, Duration: delta})
+
+		return delta
+	}
+}
+
+// timeIt runs a function and updates the data with the time it took
+// for the function to execute, as measured by the specified Clock.
+func (d *Data) timeIt(clock Clock, tag interface{}, fn func()) (delta time.Duration) {
+	if !Enabled() {
+		fn()
+		return 0
+	}
+
 	// Get the current time and arrange to update the data
-	curr := time.Now()
+	curr := clock.Now()
 	defer func() {
-		delta = time.Since(curr)
-		d.Update(delta)
+		delta = elapsed(clock, curr)
+		d.updateSample(Sample{At: curr, Duration: delta, Tag: tag})
 	}()
 
 	// Invoke the function
@@ -129,10 +286,21 @@ type dataMarshaled struct {
 	Mean           *time.Duration `json:"mean" yaml:"mean"`
 	Max            *time.Duration `json:"max" yaml:"max"`
 	Min            *time.Duration `json:"min" yaml:"min"`
+	MinAt          *time.Time     `json:"min_at,omitempty" yaml:"min_at,omitempty"`
+	MaxAt          *time.Time     `json:"max_at,omitempty" yaml:"max_at,omitempty"`
 	Variance       *time.Duration `json:"variance,omitempty" yaml:"variance,omitempty"`
 	SampleVariance *time.Duration `json:"sample_variance,omitempty" yaml:"sample_variance,omitempty"`
 	StdDev         *time.Duration `json:"std_dev,omitempty" yaml:"std_dev,omitempty"`
 	SampleStdDev   *time.Duration `json:"sample_std_dev,omitempty" yaml:"sample_std_dev,omitempty"`
+	InvalidSamples *int64         `json:"invalid_samples,omitempty" yaml:"invalid_samples,omitempty"`
+	Rejected       *int64         `json:"rejected,omitempty" yaml:"rejected,omitempty"`
+	Overruns       *int64         `json:"overruns,omitempty" yaml:"overruns,omitempty"`
+	Skipped        *int64         `json:"skipped,omitempty" yaml:"skipped,omitempty"`
+	Overflowed     bool           `json:"overflowed,omitempty" yaml:"overflowed,omitempty"`
+	Partial        bool           `json:"partial,omitempty" yaml:"partial,omitempty"`
+	Over           overCounts     `json:"over,omitempty" yaml:"over,omitempty"`
+	ItemRate       *rateMarshaled `json:"item_rate,omitempty" yaml:"item_rate,omitempty"`
+	ByteRate       *rateMarshaled `json:"byte_rate,omitempty" yaml:"byte_rate,omitempty"`
 }
 
 // toData converts a dataMarshaled instance back into a Data instance.
@@ -151,6 +319,29 @@ func (dm *dataMarshaled) toData(d *Data) {
 	if dm.Min != nil {
 		d.Min = *dm.Min
 	}
+	if dm.MinAt != nil {
+		d.MinAt = *dm.MinAt
+	}
+	if dm.MaxAt != nil {
+		d.MaxAt = *dm.MaxAt
+	}
+	if dm.InvalidSamples != nil {
+		d.InvalidSamples = *dm.InvalidSamples
+	}
+	if dm.Rejected != nil {
+		d.Rejected = *dm.Rejected
+	}
+	if dm.Overruns != nil {
+		d.Overruns = *dm.Overruns
+	}
+	if dm.Skipped != nil {
+		d.Skipped = *dm.Skipped
+	}
+	d.Overflowed = dm.Overflowed
+	d.Partial = dm.Partial
+	d.setOver(dm.Over)
+	dm.ItemRate.toRate(&d.ItemRate)
+	dm.ByteRate.toRate(&d.ByteRate)
 
 	// Now handle the calculated values; go from the hardest to
 	// recover m2 to the easiest, to attempt to be as accurate as
@@ -180,10 +371,40 @@ func (dm *dataMarshaled) toData(d *Data) {
 // marshaler constructs a dataMarshaled structure from Data.
 func (d *Data) marshaler() *dataMarshaled {
 	obj := &dataMarshaled{
-		Samples: &d.Samples,
-		Mean:    &d.Mean,
-		Max:     &d.Max,
-		Min:     &d.Min,
+		Samples:    &d.Samples,
+		Mean:       &d.Mean,
+		Max:        &d.Max,
+		Min:        &d.Min,
+		Overflowed: d.Overflowed,
+		Partial:    d.Partial,
+		Over:       d.overMarshaled(),
+		ItemRate:   newRateMarshaled(&d.ItemRate),
+		ByteRate:   newRateMarshaled(&d.ByteRate),
+	}
+
+	// Only include the times of the extremes if they are known
+	if !d.MinAt.IsZero() {
+		obj.MinAt = &d.MinAt
+	}
+	if !d.MaxAt.IsZero() {
+		obj.MaxAt = &d.MaxAt
+	}
+
+	// Only include the invalid and rejected sample counts if there
+	// were any
+	if d.InvalidSamples != 0 {
+		obj.InvalidSamples = &d.InvalidSamples
+	}
+	if d.Rejected != 0 {
+		obj.Rejected = &d.Rejected
+	}
+
+	// Likewise for the counts of overrunning and skipped runs
+	if d.Overruns != 0 {
+		obj.Overruns = &d.Overruns
+	}
+	if d.Skipped != 0 {
+		obj.Skipped = &d.Skipped
 	}
 
 	// Add requested computed fields
@@ -217,6 +438,11 @@ func (d *Data) MarshalYAML() (interface{}, error) {
 // deserialized intelligibly from YAML.  Note that round-tripping
 // results in some inaccuracies in the calculations.
 func (d *Data) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	// Refuse to modify a frozen Data
+	if d.frozen {
+		return ErrFrozen
+	}
+
 	// Unmarshal into a dataMarshaled struct
 	dm := &dataMarshaled{}
 	if err := unmarshal(dm); err != nil {
@@ -244,6 +470,11 @@ func (d *Data) UnmarshalJSON(text []byte) error {
 		return nil
 	}
 
+	// Refuse to modify a frozen Data
+	if d.frozen {
+		return ErrFrozen
+	}
+
 	// Unmarshal into a dataMarshaled struct
 	dm := &dataMarshaled{}
 	if err := json.Unmarshal(text, dm); err != nil {
@@ -12,10 +12,14 @@
 // implied.  See the License for the specific language governing
 // permissions and limitations under the License.
 
+//go:build !timeitnoop
+// +build !timeitnoop
+
 package timeit
 
 import (
 	"encoding/json"
+	"math"
 	"testing"
 	"time"
 
@@ -101,6 +105,111 @@ func TestDataUpdateNext(t *testing.T) {
 	}, d)
 }
 
+func TestDataUpdateAtRetained(t *testing.T) {
+	d := &Data{
+		Retain: 2,
+		Next:   &Data{Retain: 2},
+	}
+
+	d.UpdateAt(time.Unix(1000, 0), time.Duration(50))
+
+	assert.Equal(t, []Sample{
+		{At: time.Unix(1000, 0), Duration: 50},
+	}, d.Retained())
+	assert.Equal(t, []Sample{
+		{At: time.Unix(1000, 0), Duration: 50},
+	}, d.Next.Retained())
+}
+
+func TestDataUpdateTagged(t *testing.T) {
+	clock := &stepClock{now: time.Unix(1000, 0), step: time.Second}
+	d := &Data{
+		Clock:  clock,
+		Retain: 2,
+		Next:   &Data{Clock: clock, Retain: 2},
+	}
+
+	d.UpdateTagged("req1", time.Duration(50))
+
+	assert.Equal(t, int64(1), d.Samples)
+	assert.Equal(t, []Sample{
+		{At: time.Unix(1000, 0), Duration: 50, Tag: "req1"},
+	}, d.Retained())
+	assert.Equal(t, []Sample{
+		{At: time.Unix(1001, 0), Duration: 50, Tag: "req1"},
+	}, d.Next.Retained())
+}
+
+func TestDataUpdateRetainedDropped(t *testing.T) {
+	d := &Data{
+		Retain:  2,
+		Invalid: DropInvalid,
+	}
+
+	d.UpdateAt(time.Unix(1000, 0), time.Duration(-50))
+
+	assert.Equal(t, []Sample{}, d.Retained())
+}
+
+func TestDataUpdateClamped(t *testing.T) {
+	d := &Data{}
+
+	d.Update(time.Duration(-50))
+
+	assert.Equal(t, &Data{
+		Samples:        1,
+		InvalidSamples: 1,
+	}, d)
+}
+
+func TestDataUpdateDropped(t *testing.T) {
+	d := &Data{
+		Invalid: DropInvalid,
+		Next:    &Data{},
+	}
+
+	d.Update(time.Duration(-50))
+
+	assert.Equal(t, &Data{
+		InvalidSamples: 1,
+		Invalid:        DropInvalid,
+		Next: &Data{
+			Samples:        1,
+			InvalidSamples: 1,
+		},
+	}, d)
+}
+
+func TestDataUpdateSamplesOverflow(t *testing.T) {
+	d := &Data{
+		Samples: math.MaxInt64,
+		Mean:    time.Duration(50),
+		Max:     time.Duration(50),
+		Min:     time.Duration(50),
+	}
+
+	d.Update(time.Duration(25))
+
+	assert.Equal(t, &Data{
+		Samples:    math.MaxInt64,
+		Mean:       time.Duration(50),
+		Max:        time.Duration(50),
+		Min:        time.Duration(25),
+		Overflowed: true,
+	}, d)
+}
+
+func TestDataUpdateM2Overflow(t *testing.T) {
+	d := &Data{}
+
+	d.Update(0)
+	d.Update(20 * time.Second)
+
+	assert.True(t, d.Overflowed)
+	assert.Equal(t, time.Duration(math.MaxInt64), d.m2)
+	assert.Equal(t, 10*time.Second, d.Mean)
+}
+
 func TestDataVarianceSamples0(t *testing.T) {
 	d := &Data{
 		m2: time.Duration(50),
@@ -212,6 +321,130 @@ func TestDataTimeIt(t *testing.T) {
 	}, d)
 }
 
+func TestDataTimeItClock(t *testing.T) {
+	clock := &testClock{
+		now:   time.Unix(1000, 0),
+		delta: 50 * time.Millisecond,
+	}
+	d := &Data{Clock: clock}
+
+	result := d.TimeIt(func() {})
+
+	assert.Equal(t, 50*time.Millisecond, result)
+	assert.Equal(t, &Data{
+		Samples: 1,
+		Mean:    50 * time.Millisecond,
+		Max:     50 * time.Millisecond,
+		Min:     50 * time.Millisecond,
+		Clock:   clock,
+		m2:      time.Duration(0),
+	}, d)
+}
+
+func TestDataTimeItWith(t *testing.T) {
+	clock := &testClock{
+		now:   time.Unix(1000, 0),
+		delta: 50 * time.Millisecond,
+	}
+	d := &Data{}
+
+	result := d.TimeItWith(clock, func() {})
+
+	assert.Equal(t, 50*time.Millisecond, result)
+	assert.Equal(t, &Data{
+		Samples: 1,
+		Mean:    50 * time.Millisecond,
+		Max:     50 * time.Millisecond,
+		Min:     50 * time.Millisecond,
+		m2:      time.Duration(0),
+	}, d)
+}
+
+func TestDataTimeItRetained(t *testing.T) {
+	clock := &testClock{
+		now:   time.Unix(1000, 0),
+		delta: 50 * time.Millisecond,
+	}
+	d := &Data{Clock: clock, Retain: 1}
+
+	d.TimeIt(func() {})
+
+	assert.Equal(t, []Sample{
+		{At: time.Unix(1000, 0), Duration: 50 * time.Millisecond},
+	}, d.Retained())
+}
+
+func TestDataTimeItTagged(t *testing.T) {
+	clock := &testClock{
+		now:   time.Unix(1000, 0),
+		delta: 50 * time.Millisecond,
+	}
+	d := &Data{Clock: clock, Retain: 1}
+
+	result := d.TimeItTagged("req1", func() {})
+
+	assert.Equal(t, 50*time.Millisecond, result)
+	assert.Equal(t, []Sample{
+		{At: time.Unix(1000, 0), Duration: 50 * time.Millisecond, Tag: "req1"},
+	}, d.Retained())
+}
+
+func TestDataTimeItSkewed(t *testing.T) {
+	before := SkewCorrections()
+	d := &Data{Clock: &stepClock{now: time.Unix(1000, 0), step: -time.Second}}
+
+	result := d.TimeIt(func() {})
+
+	assert.Equal(t, -time.Second, result)
+	assert.Equal(t, int64(1), d.Samples)
+	assert.Equal(t, time.Duration(0), d.Max)
+	assert.Equal(t, int64(1), d.InvalidSamples)
+	assert.Equal(t, before+1, SkewCorrections())
+}
+
+func TestDataTimeItSkewedDrop(t *testing.T) {
+	before := SkewCorrections()
+	d := &Data{
+		Clock:   &stepClock{now: time.Unix(1000, 0), step: -time.Second},
+		Invalid: DropInvalid,
+	}
+
+	result := d.TimeIt(func() {})
+
+	assert.Equal(t, -time.Second, result)
+	assert.Equal(t, int64(0), d.Samples)
+	assert.Equal(t, int64(1), d.InvalidSamples)
+	assert.Equal(t, before+1, SkewCorrections())
+}
+
+func TestDataMeasureBase(t *testing.T) {
+	clock := &testClock{
+		now:   time.Unix(1000, 0),
+		delta: 50 * time.Millisecond,
+	}
+	d := &Data{Clock: clock, Retain: 1}
+
+	stop := d.Measure()
+	assert.Equal(t, int64(0), d.Samples)
+	result := stop()
+
+	assert.Equal(t, 50*time.Millisecond, result)
+	assert.Equal(t, []Sample{
+		{At: time.Unix(1000, 0), Duration: 50 * time.Millisecond},
+	}, d.Retained())
+}
+
+func TestDataMeasureDefer(t *testing.T) {
+	d := &Data{Clock: &FakeClock{Time: time.Unix(1000, 0), Step: 10}}
+
+	func() {
+		defer d.Measure()()
+	}()
+
+	assert.Equal(t, int64(1), d.Samples)
+	assert.Equal(t, time.Duration(10), d.Mean)
+}
+
 func TestDataMarshaledToData(t *testing.T) {
 	samples := int64(3)
 	mean := time.Duration(50)
@@ -245,6 +478,193 @@ func TestDataMarshaledToData(t *testing.T) {
 	}, result)
 }
 
+func TestDataMarshaledToDataInvalidSamples(t *testing.T) {
+	samples := int64(3)
+	invalidSamples := int64(2)
+	dm := &dataMarshaled{
+		Samples:        &samples,
+		InvalidSamples: &invalidSamples,
+	}
+	result := &Data{}
+
+	dm.toData(result)
+
+	assert.Equal(t, &Data{
+		Samples:        3,
+		InvalidSamples: 2,
+	}, result)
+}
+
+func TestDataMarshalerInvalidSamples(t *testing.T) {
+	d := &Data{
+		Samples:        3,
+		InvalidSamples: 2,
+		Flags:          Variance,
+	}
+
+	result := d.marshaler()
+
+	samples := int64(3)
+	invalidSamples := int64(2)
+	mean := time.Duration(0)
+	variance := time.Duration(0)
+	assert.Equal(t, &dataMarshaled{
+		Samples:        &samples,
+		Mean:           &mean,
+		Max:            &mean,
+		Min:            &mean,
+		Variance:       &variance,
+		InvalidSamples: &invalidSamples,
+	}, result)
+}
+
+func TestDataMarshaledToDataRejected(t *testing.T) {
+	samples := int64(3)
+	rejected := int64(2)
+	dm := &dataMarshaled{
+		Samples:  &samples,
+		Rejected: &rejected,
+	}
+	result := &Data{}
+
+	dm.toData(result)
+
+	assert.Equal(t, &Data{
+		Samples:  3,
+		Rejected: 2,
+	}, result)
+}
+
+func TestDataMarshalerRejected(t *testing.T) {
+	d := &Data{
+		Samples:  3,
+		Rejected: 2,
+		Flags:    Variance,
+	}
+
+	result := d.marshaler()
+
+	samples := int64(3)
+	rejected := int64(2)
+	mean := time.Duration(0)
+	variance := time.Duration(0)
+	assert.Equal(t, &dataMarshaled{
+		Samples:  &samples,
+		Mean:     &mean,
+		Max:      &mean,
+		Min:      &mean,
+		Variance: &variance,
+		Rejected: &rejected,
+	}, result)
+}
+
+func TestDataMarshaledToDataOverflowed(t *testing.T) {
+	dm := &dataMarshaled{
+		Overflowed: true,
+	}
+	result := &Data{}
+
+	dm.toData(result)
+
+	assert.Equal(t, &Data{
+		Overflowed: true,
+	}, result)
+}
+
+func TestDataMarshalerOverflowed(t *testing.T) {
+	d := &Data{
+		Overflowed: true,
+		Flags:      Variance,
+	}
+
+	result := d.marshaler()
+
+	samples := int64(0)
+	mean := time.Duration(0)
+	assert.Equal(t, &dataMarshaled{
+		Samples:    &samples,
+		Mean:       &mean,
+		Max:        &mean,
+		Min:        &mean,
+		Variance:   &mean,
+		Overflowed: true,
+	}, result)
+}
+
+func TestDataMarshaledToDataRuns(t *testing.T) {
+	overruns := int64(2)
+	skipped := int64(5)
+	dm := &dataMarshaled{
+		Overruns: &overruns,
+		Skipped:  &skipped,
+	}
+	result := &Data{}
+
+	dm.toData(result)
+
+	assert.Equal(t, &Data{
+		Overruns: 2,
+		Skipped:  5,
+	}, result)
+}
+
+func TestDataMarshalerRuns(t *testing.T) {
+	d := &Data{
+		Overruns: 2,
+		Skipped:  5,
+		Flags:    Variance,
+	}
+
+	result := d.marshaler()
+
+	samples := int64(0)
+	mean := time.Duration(0)
+	overruns := int64(2)
+	skipped := int64(5)
+	assert.Equal(t, &dataMarshaled{
+		Samples:  &samples,
+		Mean:     &mean,
+		Max:      &mean,
+		Min:      &mean,
+		Variance: &mean,
+		Overruns: &overruns,
+		Skipped:  &skipped,
+	}, result)
+}
+
+func TestDataMarshaledToDataPartial(t *testing.T) {
+	dm := &dataMarshaled{
+		Partial: true,
+	}
+	result := &Data{}
+
+	dm.toData(result)
+
+	assert.Equal(t, &Data{
+		Partial: true,
+	}, result)
+}
+
+func TestDataMarshalerPartial(t *testing.T) {
+	d := &Data{
+		Partial: true,
+		Flags:   Variance,
+	}
+
+	result := d.marshaler()
+
+	samples := int64(0)
+	mean := time.Duration(0)
+	assert.Equal(t, &dataMarshaled{
+		Samples:  &samples,
+		Mean:     &mean,
+		Max:      &mean,
+		Min:      &mean,
+		Variance: &mean,
+		Partial:  true,
+	}, result)
+}
+
 func TestDataMarshalerBase(t *testing.T) {
 	d := &Data{
 		Samples: 3,
@@ -514,6 +934,19 @@ func TestDataUnmarshalJSONBase(t *testing.T) {
 	}, result)
 }
 
+func TestDataUnmarshalJSONPartial(t *testing.T) {
+	d := &Data{Samples: 2, Mean: 50, Max: 75, Min: 25, Partial: true, Flags: Variance}
+	text, err := json.Marshal(d)
+	require.NoError(t, err)
+	result := &Data{}
+
+	err = json.Unmarshal(text, result)
+
+	assert.NoError(t, err)
+	assert.Contains(t, string(text), `"partial":true`)
+	assert.True(t, result.Partial)
+}
+
 func TestDataUnmarshalJSONNull(t *testing.T) {
 	text := []byte(`null`)
 	result := &Data{}
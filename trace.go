@@ -0,0 +1,236 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// ClientTrace contains Data for each phase of HTTP client requests,
+// giving a latency waterfall of the requests.  Updates to the Data
+// are serialized by an internal mutex; the Data should only be read
+// once the traced requests have completed.
+type ClientTrace struct {
+	DNS     Data // Time spent on DNS lookups
+	Connect Data // Time spent establishing connections
+	TLS     Data // Time spent on TLS handshakes
+	TTFB    Data // Time from request start to first response byte
+	Body    Data // Time spent reading response bodies
+	mu      sync.Mutex
+}
+
+// update updates the specified data.
+func (ct *ClientTrace) update(d *Data, sample time.Duration) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	d.Update(sample)
+}
+
+// requestTrace tracks the phases of a single request.
+type requestTrace struct {
+	ct        *ClientTrace
+	clock     Clock
+	start     time.Time
+	mu        sync.Mutex
+	dnsStart  time.Time
+	tlsStart  time.Time
+	connStart map[string]time.Time
+}
+
+// newRequestTrace constructs a requestTrace for a request starting
+// now.
+func (ct *ClientTrace) newRequestTrace(clock Clock) *requestTrace {
+	return &requestTrace{
+		ct:        ct,
+		clock:     clock,
+		start:     clock.Now(),
+		connStart: map[string]time.Time{},
+	}
+}
+
+// mark records the start time of a phase.
+func (rt *requestTrace) mark(start *time.Time) {
+	now := rt.clock.Now()
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	*start = now
+}
+
+// record records the duration of a phase.
+func (rt *requestTrace) record(d *Data, start *time.Time) {
+	rt.mu.Lock()
+	begin := *start
+	rt.mu.Unlock()
+
+	rt.ct.update(d, elapsed(rt.clock, begin))
+}
+
+// connectStart records the start of a connection attempt.  There may
+// be several simultaneous attempts, so these are tracked by address.
+func (rt *requestTrace) connectStart(network, addr string) {
+	now := rt.clock.Now()
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	rt.connStart[network+":"+addr] = now
+}
+
+// connectDone records the duration of a connection attempt.
+func (rt *requestTrace) connectDone(network, addr string, _ error) {
+	rt.mu.Lock()
+	start, ok := rt.connStart[network+":"+addr]
+	rt.mu.Unlock()
+
+	if ok {
+		rt.ct.update(&rt.ct.Connect, elapsed(rt.clock, start))
+	}
+}
+
+// clientTrace constructs the httptrace.ClientTrace hooks for the
+// request.
+func (rt *requestTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			rt.mark(&rt.dnsStart)
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			rt.record(&rt.ct.DNS, &rt.dnsStart)
+		},
+		ConnectStart: rt.connectStart,
+		ConnectDone:  rt.connectDone,
+		TLSHandshakeStart: func() {
+			rt.mark(&rt.tlsStart)
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			rt.record(&rt.ct.TLS, &rt.tlsStart)
+		},
+		GotFirstResponseByte: func() {
+			rt.record(&rt.ct.TTFB, &rt.start)
+		},
+	}
+}
+
+// WithContext returns a context that will record the phases of an
+// HTTP client request made with it into the ClientTrace.  The time
+// to first byte is measured from the call to WithContext.  Response
+// body reads are not timed; use a Transport for that.
+func (ct *ClientTrace) WithContext(ctx context.Context) context.Context {
+	rt := ct.newRequestTrace(WallClock)
+
+	return httptrace.WithClientTrace(ctx, rt.clientTrace())
+}
+
+// timedBody wraps a response body to record the time taken to read
+// it.
+type timedBody struct {
+	io.ReadCloser
+	rt    *requestTrace
+	start time.Time
+	once  sync.Once
+}
+
+// done records the time taken to read the body.
+func (tb *timedBody) done() {
+	tb.once.Do(func() {
+		tb.rt.record(&tb.rt.ct.Body, &tb.start)
+	})
+}
+
+// Read reads from the body, recording the time taken to read it once
+// the end of the body is reached.
+func (tb *timedBody) Read(p []byte) (int, error) {
+	n, err := tb.ReadCloser.Read(p)
+	if err == io.EOF {
+		tb.done()
+	}
+
+	return n, err
+}
+
+// Close closes the body, recording the time taken to read it if the
+// end of the body was not reached.
+func (tb *timedBody) Close() error {
+	tb.done()
+
+	return tb.ReadCloser.Close()
+}
+
+// Transport is an http.RoundTripper that records the phases of each
+// request into a ClientTrace for the request's host.
+type Transport struct {
+	Base  http.RoundTripper // Underlying transport; nil for the default
+	Clock Clock             // Source of time; nil for WallClock
+	mu    sync.Mutex
+	hosts map[string]*ClientTrace
+}
+
+// Host returns the ClientTrace for the specified host, which should
+// be in the form used by the URL.Host field of requests.
+func (t *Transport) Host(host string) *ClientTrace {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.hosts == nil {
+		t.hosts = map[string]*ClientTrace{}
+	}
+	ct, ok := t.hosts[host]
+	if !ok {
+		ct = &ClientTrace{}
+		t.hosts[host] = ct
+	}
+
+	return ct
+}
+
+// RoundTrip implements http.RoundTripper.  It performs the request
+// using the base transport, recording the phases of the request.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Select the base transport and the clock
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	clock := t.Clock
+	if clock == nil {
+		clock = WallClock
+	}
+
+	// Set up the trace and perform the request
+	rt := t.Host(req.URL.Host).newRequestTrace(clock)
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), rt.clientTrace()))
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Time the body
+	resp.Body = &timedBody{
+		ReadCloser: resp.Body,
+		rt:         rt,
+		start:      clock.Now(),
+	}
+
+	return resp, nil
+}
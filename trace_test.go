@@ -0,0 +1,232 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build !timeitnoop
+// +build !timeitnoop
+
+package timeit
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestClientTraceUpdate(t *testing.T) {
+	ct := &ClientTrace{}
+
+	ct.update(&ct.DNS, 50)
+
+	assert.Equal(t, int64(1), ct.DNS.Samples)
+}
+
+func TestClientTraceNewRequestTrace(t *testing.T) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	ct := &ClientTrace{}
+
+	result := ct.newRequestTrace(clock)
+
+	assert.Equal(t, &requestTrace{
+		ct:        ct,
+		clock:     clock,
+		start:     time.Unix(1000, 0),
+		connStart: map[string]time.Time{},
+	}, result)
+}
+
+func TestRequestTraceMarkRecord(t *testing.T) {
+	clock := &stepClock{now: time.Unix(1000, 0), step: 10}
+	ct := &ClientTrace{}
+	rt := ct.newRequestTrace(clock)
+
+	rt.mark(&rt.dnsStart)
+	rt.record(&ct.DNS, &rt.dnsStart)
+
+	assert.Equal(t, time.Unix(1000, 10), rt.dnsStart)
+	assert.Equal(t, int64(1), ct.DNS.Samples)
+	assert.Equal(t, time.Duration(10), ct.DNS.Mean)
+}
+
+func TestRequestTraceConnect(t *testing.T) {
+	clock := &stepClock{now: time.Unix(1000, 0), step: 10}
+	ct := &ClientTrace{}
+	rt := ct.newRequestTrace(clock)
+
+	rt.connectStart("tcp", "10.0.0.1:80")
+	rt.connectStart("tcp", "10.0.0.2:80")
+	rt.connectDone("tcp", "10.0.0.1:80", nil)
+	rt.connectDone("tcp", "10.0.0.3:80", nil)
+
+	assert.Equal(t, int64(1), ct.Connect.Samples)
+	assert.Equal(t, time.Duration(20), ct.Connect.Mean)
+}
+
+func TestRequestTraceClientTrace(t *testing.T) {
+	clock := &stepClock{now: time.Unix(1000, 0), step: 10}
+	ct := &ClientTrace{}
+	rt := ct.newRequestTrace(clock)
+
+	result := rt.clientTrace()
+	result.DNSStart(httptrace.DNSStartInfo{})
+	result.DNSDone(httptrace.DNSDoneInfo{})
+	result.ConnectStart("tcp", "10.0.0.1:80")
+	result.ConnectDone("tcp", "10.0.0.1:80", nil)
+	result.TLSHandshakeStart()
+	result.TLSHandshakeDone(tls.ConnectionState{}, nil)
+	result.GotFirstResponseByte()
+
+	assert.Equal(t, time.Duration(10), ct.DNS.Mean)
+	assert.Equal(t, time.Duration(10), ct.Connect.Mean)
+	assert.Equal(t, time.Duration(10), ct.TLS.Mean)
+	assert.Equal(t, time.Duration(70), ct.TTFB.Mean)
+}
+
+func TestClientTraceWithContext(t *testing.T) {
+	ct := &ClientTrace{}
+
+	result := ct.WithContext(context.Background())
+
+	assert.NotNil(t, httptrace.ContextClientTrace(result))
+}
+
+func TestTimedBodyReadEOF(t *testing.T) {
+	clock := &stepClock{now: time.Unix(1000, 0), step: 10}
+	ct := &ClientTrace{}
+	tb := &timedBody{
+		ReadCloser: ioutil.NopCloser(strings.NewReader("body")),
+		rt:         ct.newRequestTrace(clock),
+		start:      time.Unix(1000, 0),
+	}
+
+	result, err := ioutil.ReadAll(tb)
+	require.NoError(t, err)
+	err = tb.Close()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("body"), result)
+	assert.Equal(t, int64(1), ct.Body.Samples)
+	assert.Equal(t, time.Duration(10), ct.Body.Mean)
+}
+
+func TestTimedBodyCloseEarly(t *testing.T) {
+	clock := &stepClock{now: time.Unix(1000, 0), step: 10}
+	ct := &ClientTrace{}
+	tb := &timedBody{
+		ReadCloser: ioutil.NopCloser(strings.NewReader("body")),
+		rt:         ct.newRequestTrace(clock),
+		start:      time.Unix(1000, 0),
+	}
+
+	n, err := tb.Read(make([]byte, 2))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	err = tb.Close()
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), ct.Body.Samples)
+}
+
+func TestTransportHostNew(t *testing.T) {
+	tr := &Transport{}
+
+	result := tr.Host("example.com")
+
+	assert.Equal(t, map[string]*ClientTrace{
+		"example.com": result,
+	}, tr.hosts)
+}
+
+func TestTransportHostExisting(t *testing.T) {
+	ct := &ClientTrace{}
+	tr := &Transport{
+		hosts: map[string]*ClientTrace{"example.com": ct},
+	}
+
+	result := tr.Host("example.com")
+
+	assert.Same(t, ct, result)
+}
+
+func TestTransportRoundTrip(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "body")
+	}))
+	defer srv.Close()
+	tr := &Transport{}
+	client := &http.Client{Transport: tr}
+
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, []byte("body"), body)
+	ct := tr.Host(strings.TrimPrefix(srv.URL, "http://"))
+	assert.Equal(t, int64(1), ct.Connect.Samples)
+	assert.Equal(t, int64(0), ct.TLS.Samples)
+	assert.Equal(t, int64(1), ct.TTFB.Samples)
+	assert.Equal(t, int64(1), ct.Body.Samples)
+}
+
+func TestTransportRoundTripTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "body")
+	}))
+	defer srv.Close()
+	tr := &Transport{
+		Base:  srv.Client().Transport,
+		Clock: WallClock,
+	}
+	client := &http.Client{Transport: tr}
+
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	ct := tr.Host(strings.TrimPrefix(srv.URL, "https://"))
+	assert.Equal(t, int64(1), ct.TLS.Samples)
+}
+
+func TestTransportRoundTripError(t *testing.T) {
+	tr := &Transport{
+		Base: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			assert.NotNil(t, httptrace.ContextClientTrace(req.Context()))
+			return nil, assert.AnError
+		}),
+	}
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	require.NoError(t, err)
+
+	resp, err := tr.RoundTrip(req)
+
+	assert.Same(t, assert.AnError, err)
+	assert.Nil(t, resp)
+}
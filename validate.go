@@ -0,0 +1,133 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"fmt"
+	"time"
+)
+
+// InvalidPolicy describes how a Data handles invalid samples.  A
+// sample is invalid if it is negative, which time.Since may yield if
+// the clock misbehaves, or if it exceeds the Data's Limit, if one is
+// set.
+type InvalidPolicy uint8
+
+// Recognized invalid sample policies.  All policies count invalid
+// samples in InvalidSamples.
+const (
+	ClampInvalid InvalidPolicy = iota // Clamp to 0 or the Limit
+	DropInvalid                       // Drop the sample
+	PanicInvalid                      // Panic; intended for debugging
+)
+
+// valid checks whether a sample is valid.
+func (d *Data) valid(sample time.Duration) bool {
+	return sample >= 0 && (d.Limit <= 0 || sample <= d.Limit)
+}
+
+// validate checks whether a sample is valid, applying the Data's
+// InvalidPolicy if it is not.  It returns the sample to record and
+// whether it should be recorded at all.
+func (d *Data) validate(sample time.Duration) (time.Duration, bool) {
+	// Check the sample
+	if d.valid(sample) {
+		return sample, true
+	}
+	d.InvalidSamples++
+	clamped := time.Duration(0)
+	if sample > 0 {
+		clamped = d.Limit
+	}
+
+	// Apply the policy
+	switch d.Invalid {
+	case DropInvalid:
+		return 0, false
+
+	case PanicInvalid:
+		panic(fmt.Errorf("%w: %s", ErrInvalidSample, sample))
+
+	case ClampInvalid:
+	}
+
+	return clamped, true
+}
+
+// FilterFunc is the type of a predicate selecting the samples to be
+// recorded by a Data.  A filter allows a Data to record a segment of
+// the samples, e.g., only those slower than 100ms, without the need
+// for a histogram.  Samples not selected are counted in Rejected, and
+// are still passed on to Next.
+type FilterFunc func(sample time.Duration) bool
+
+// accept checks whether a valid sample is selected by the Data's
+// Filter, counting it in Rejected if it is not.
+func (d *Data) accept(sample time.Duration) bool {
+	if d.Filter == nil || d.Filter(sample) {
+		return true
+	}
+	d.Rejected++
+
+	return false
+}
+
+// updateChecked adds a sample to the Data structure, returning an
+// error instead of applying the InvalidPolicy.
+func (d *Data) updateChecked(s Sample) error {
+	if d.frozen {
+		return ErrFrozen
+	}
+	if !d.valid(s.Duration) {
+		d.InvalidSamples++
+		return fmt.Errorf("%w: %s", ErrInvalidSample, s.Duration)
+	}
+	if !d.accept(s.Duration) {
+		return nil
+	}
+
+	d.record(s)
+	if d.Overflowed {
+		return ErrOverflow
+	}
+
+	return nil
+}
+
+// UpdateChecked is an alternative to Update for applications that
+// prefer explicit error handling.  Rather than being handled by the
+// InvalidPolicy, an invalid sample is counted but not recorded, and
+// an error wrapping ErrInvalidSample is returned.  ErrFrozen is
+// returned if the Data is frozen, and ErrOverflow is returned if the
+// statistics have overflowed and are no longer accurate.  As with
+// Update, the sample is passed on to Next; the first error
+// encountered is returned.  If instrumentation is disabled (see
+// Enable), the sample is discarded and nil is returned.
+func (d *Data) UpdateChecked(sample time.Duration) error {
+	if !Enabled() {
+		return nil
+	}
+
+	err := d.updateChecked(Sample{Duration: sample})
+
+	// Pass the sample on to Next
+	if d.Next != nil {
+		if nextErr := d.Next.UpdateChecked(sample); err == nil {
+			err = nextErr
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,244 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build !timeitnoop
+// +build !timeitnoop
+
+package timeit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataValidateValid(t *testing.T) {
+	d := &Data{Limit: 100}
+
+	result, ok := d.validate(50)
+
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(50), result)
+	assert.Equal(t, int64(0), d.InvalidSamples)
+}
+
+func TestDataValidateNoLimit(t *testing.T) {
+	d := &Data{}
+
+	result, ok := d.validate(time.Hour)
+
+	assert.True(t, ok)
+	assert.Equal(t, time.Hour, result)
+	assert.Equal(t, int64(0), d.InvalidSamples)
+}
+
+func TestDataValidateClampNegative(t *testing.T) {
+	d := &Data{}
+
+	result, ok := d.validate(-50)
+
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), result)
+	assert.Equal(t, int64(1), d.InvalidSamples)
+}
+
+func TestDataValidateClampLimit(t *testing.T) {
+	d := &Data{Limit: 100}
+
+	result, ok := d.validate(150)
+
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(100), result)
+	assert.Equal(t, int64(1), d.InvalidSamples)
+}
+
+func TestDataValidateDrop(t *testing.T) {
+	d := &Data{Invalid: DropInvalid}
+
+	_, ok := d.validate(-50)
+
+	assert.False(t, ok)
+	assert.Equal(t, int64(1), d.InvalidSamples)
+}
+
+func TestDataValidatePanic(t *testing.T) {
+	d := &Data{Invalid: PanicInvalid}
+
+	assert.PanicsWithError(t, "invalid sample: -50ns", func() {
+		d.validate(-50)
+	})
+	assert.Equal(t, int64(1), d.InvalidSamples)
+}
+
+func TestDataValid(t *testing.T) {
+	d := &Data{}
+	assert.True(t, d.valid(0))
+	assert.True(t, d.valid(time.Hour))
+	assert.False(t, d.valid(-1))
+
+	d.Limit = 100
+	assert.True(t, d.valid(100))
+	assert.False(t, d.valid(101))
+}
+
+func TestDataUpdateCheckedBase(t *testing.T) {
+	d := &Data{}
+
+	err := d.UpdateChecked(50)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Data{
+		Samples: 1,
+		Mean:    50,
+		Max:     50,
+		Min:     50,
+	}, d)
+}
+
+func TestDataUpdateCheckedFrozen(t *testing.T) {
+	d := &Data{frozen: true}
+
+	err := d.UpdateChecked(50)
+
+	assert.Same(t, ErrFrozen, err)
+	assert.Equal(t, &Data{frozen: true}, d)
+}
+
+func TestDataUpdateCheckedInvalid(t *testing.T) {
+	d := &Data{Limit: 100}
+
+	err := d.UpdateChecked(150)
+
+	assert.ErrorIs(t, err, ErrInvalidSample)
+	assert.EqualError(t, err, "invalid sample: 150ns")
+	assert.Equal(t, &Data{
+		Limit:          100,
+		InvalidSamples: 1,
+	}, d)
+}
+
+func TestDataUpdateCheckedOverflow(t *testing.T) {
+	d := &Data{}
+
+	err1 := d.UpdateChecked(0)
+	err2 := d.UpdateChecked(20 * time.Second)
+
+	assert.NoError(t, err1)
+	assert.Same(t, ErrOverflow, err2)
+	assert.Equal(t, int64(2), d.Samples)
+}
+
+func TestDataUpdateCheckedNext(t *testing.T) {
+	d := &Data{
+		Next: &Data{Limit: 10},
+	}
+
+	err := d.UpdateChecked(50)
+
+	assert.ErrorIs(t, err, ErrInvalidSample)
+	assert.Equal(t, int64(1), d.Samples)
+	assert.Equal(t, int64(1), d.Next.InvalidSamples)
+}
+
+func TestDataUpdateCheckedNextFirstError(t *testing.T) {
+	d := &Data{
+		Next:   &Data{Limit: 10},
+		frozen: true,
+	}
+
+	err := d.UpdateChecked(50)
+
+	assert.Same(t, ErrFrozen, err)
+	assert.Equal(t, int64(1), d.Next.InvalidSamples)
+}
+
+func TestDataUpdateCheckedRetained(t *testing.T) {
+	clock := &stepClock{now: time.Unix(1000, 0), step: time.Second}
+	d := &Data{Clock: clock, Retain: 1}
+
+	err := d.UpdateChecked(50)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Sample{
+		{At: time.Unix(1000, 0), Duration: 50},
+	}, d.Retained())
+}
+
+func TestDataAcceptNoFilter(t *testing.T) {
+	d := &Data{}
+
+	result := d.accept(10)
+
+	assert.True(t, result)
+	assert.Equal(t, int64(0), d.Rejected)
+}
+
+func TestDataAcceptSelected(t *testing.T) {
+	d := &Data{Filter: func(sample time.Duration) bool { return sample > 100 }}
+
+	result := d.accept(150)
+
+	assert.True(t, result)
+	assert.Equal(t, int64(0), d.Rejected)
+}
+
+func TestDataAcceptRejected(t *testing.T) {
+	d := &Data{Filter: func(sample time.Duration) bool { return sample > 100 }}
+
+	result := d.accept(50)
+
+	assert.False(t, result)
+	assert.Equal(t, int64(1), d.Rejected)
+}
+
+func TestDataUpdateFilter(t *testing.T) {
+	next := &Data{}
+	d := &Data{
+		Filter: func(sample time.Duration) bool { return sample > 100 },
+		Next:   next,
+		Retain: 5,
+	}
+
+	for _, s := range []time.Duration{50, 150, 80, 250} {
+		d.Update(s)
+	}
+
+	assert.Equal(t, int64(2), d.Samples)
+	assert.Equal(t, time.Duration(200), d.Mean)
+	assert.Equal(t, int64(2), d.Rejected)
+	assert.Len(t, d.Retained(), 2)
+	assert.Equal(t, int64(4), next.Samples)
+}
+
+func TestDataUpdateCheckedRates(t *testing.T) {
+	d := &Data{}
+
+	err := d.updateChecked(Sample{Duration: time.Second, Items: 100, Bytes: 1000})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), d.Samples)
+	assert.Equal(t, Rate{Samples: 1, Mean: 100, Max: 100, Min: 100}, d.ItemRate)
+	assert.Equal(t, Rate{Samples: 1, Mean: 1000, Max: 1000, Min: 1000}, d.ByteRate)
+}
+
+func TestDataUpdateCheckedFilter(t *testing.T) {
+	d := &Data{Filter: func(sample time.Duration) bool { return sample > 100 }}
+
+	err := d.UpdateChecked(50)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), d.Samples)
+	assert.Equal(t, int64(1), d.Rejected)
+}
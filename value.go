@@ -0,0 +1,243 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// Value contains accumulated statistics for an arbitrary numeric
+// metric, such as a queue depth, a size in bytes, or a score, using
+// the same math as Data.  Integer metrics may be recorded exactly as
+// long as they do not exceed 2^53 in magnitude.  As with Data, the
+// Flags control which of the computed fields are marshaled, and a
+// Value is not safe for concurrent use.
+type Value struct {
+	Samples int64        // The number of samples developed so far
+	Mean    float64      // The current running mean
+	Max     float64      // Maximum sample seen so far
+	Min     float64      // Minimum sample seen so far
+	Flags   MarshalFlags // Bitmask of computed fields to marshal
+	m2      float64      // Sum of square differences
+}
+
+// Update adds another sample to the Value.  If the sample count
+// would overflow, only the minimum and maximum are updated.
+func (v *Value) Update(sample float64) {
+	if v.Samples == 0 || sample < v.Min {
+		v.Min = sample
+	}
+	if v.Samples == 0 || sample > v.Max {
+		v.Max = sample
+	}
+	if v.Samples == math.MaxInt64 {
+		return
+	}
+	v.Samples++
+
+	delta1 := sample - v.Mean
+	v.Mean += delta1 / float64(v.Samples)
+	v.m2 += delta1 * (sample - v.Mean)
+}
+
+// Merge combines the statistics of another Value into this one, as
+// if all the samples of the other Value had been passed to Update;
+// see Data.Merge.
+func (v *Value) Merge(other *Value) {
+	if other.Samples == 0 {
+		return
+	} else if v.Samples == 0 {
+		flags := v.Flags
+		*v = *other
+		v.Flags = flags
+		return
+	}
+
+	if other.Min < v.Min {
+		v.Min = other.Min
+	}
+	if other.Max > v.Max {
+		v.Max = other.Max
+	}
+
+	na, nb := float64(v.Samples), float64(other.Samples)
+	n := na + nb
+	delta := other.Mean - v.Mean
+	v.Mean += delta * nb / n
+	v.m2 += other.m2 + delta*delta*na*nb/n
+	if v.Samples > math.MaxInt64-other.Samples {
+		v.Samples = math.MaxInt64
+	} else {
+		v.Samples += other.Samples
+	}
+}
+
+// Variance returns the variance of the samples.  If no samples have
+// been collected so far, this value will be 0.
+func (v *Value) Variance() float64 {
+	if v.Samples <= 0 {
+		return 0
+	}
+
+	return v.m2 / float64(v.Samples)
+}
+
+// SampleVariance returns the sample variance of the samples.  If
+// only one sample has been collected so far, this value will be 0.
+func (v *Value) SampleVariance() float64 {
+	if v.Samples <= 1 {
+		return 0
+	}
+
+	return v.m2 / float64(v.Samples-1)
+}
+
+// StdDev returns the standard deviation of the samples.  If no
+// samples have been collected so far, this value will be 0.
+func (v *Value) StdDev() float64 {
+	return math.Sqrt(v.Variance())
+}
+
+// SampleStdDev returns the sample standard deviation of the samples.
+// If only one sample has been collected so far, this value will be 0.
+func (v *Value) SampleStdDev() float64 {
+	return math.Sqrt(v.SampleVariance())
+}
+
+// valueMarshaled contains the Value, along with the requested
+// computed fields, which will then be marshaled into either JSON or
+// YAML.
+type valueMarshaled struct {
+	Samples        *int64   `json:"samples" yaml:"samples"`
+	Mean           *float64 `json:"mean" yaml:"mean"`
+	Max            *float64 `json:"max" yaml:"max"`
+	Min            *float64 `json:"min" yaml:"min"`
+	Variance       *float64 `json:"variance,omitempty" yaml:"variance,omitempty"`
+	SampleVariance *float64 `json:"sample_variance,omitempty" yaml:"sample_variance,omitempty"`
+	StdDev         *float64 `json:"std_dev,omitempty" yaml:"std_dev,omitempty"`
+	SampleStdDev   *float64 `json:"sample_std_dev,omitempty" yaml:"sample_std_dev,omitempty"`
+}
+
+// toValue converts a valueMarshaled instance back into a Value
+// instance.  It guesses the Flags value based on the available data.
+func (vm *valueMarshaled) toValue(v *Value) {
+	if vm.Samples != nil {
+		v.Samples = *vm.Samples
+	}
+	if vm.Mean != nil {
+		v.Mean = *vm.Mean
+	}
+	if vm.Max != nil {
+		v.Max = *vm.Max
+	}
+	if vm.Min != nil {
+		v.Min = *vm.Min
+	}
+
+	// Recover m2 from the computed values, going from the hardest
+	// to the easiest, as for Data
+	if vm.SampleStdDev != nil {
+		v.Flags |= SampleStdDev
+		if v.Samples > 1 {
+			v.m2 = *vm.SampleStdDev * *vm.SampleStdDev * float64(v.Samples-1)
+		}
+	}
+	if vm.StdDev != nil {
+		v.Flags |= StdDev
+		v.m2 = *vm.StdDev * *vm.StdDev * float64(v.Samples)
+	}
+	if vm.SampleVariance != nil {
+		v.Flags |= SampleVariance
+		if v.Samples > 1 {
+			v.m2 = *vm.SampleVariance * float64(v.Samples-1)
+		}
+	}
+	if vm.Variance != nil {
+		v.Flags |= Variance
+		v.m2 = *vm.Variance * float64(v.Samples)
+	}
+}
+
+// marshaler constructs a valueMarshaled structure from the Value.
+func (v *Value) marshaler() *valueMarshaled {
+	obj := &valueMarshaled{
+		Samples: &v.Samples,
+		Mean:    &v.Mean,
+		Max:     &v.Max,
+		Min:     &v.Min,
+	}
+
+	// Add requested computed fields
+	if v.Flags == 0 || (v.Flags&Variance) != 0 {
+		tmp := v.Variance()
+		obj.Variance = &tmp
+	}
+	if v.Flags == 0 || (v.Flags&SampleVariance) != 0 {
+		tmp := v.SampleVariance()
+		obj.SampleVariance = &tmp
+	}
+	if v.Flags == 0 || (v.Flags&StdDev) != 0 {
+		tmp := v.StdDev()
+		obj.StdDev = &tmp
+	}
+	if v.Flags == 0 || (v.Flags&SampleStdDev) != 0 {
+		tmp := v.SampleStdDev()
+		obj.SampleStdDev = &tmp
+	}
+
+	return obj
+}
+
+// MarshalYAML implements yaml.Marshaler and allows a Value to be
+// serialized intelligibly as YAML.
+func (v *Value) MarshalYAML() (interface{}, error) {
+	return v.marshaler(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler and allows a Value to be
+// deserialized intelligibly from YAML.
+func (v *Value) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	vm := &valueMarshaled{}
+	if err := unmarshal(vm); err != nil {
+		return err
+	}
+	vm.toValue(v)
+
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler and allows a Value to be
+// serialized intelligibly as JSON.
+func (v *Value) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.marshaler())
+}
+
+// UnmarshalJSON implements json.Unmarshaler and allows a Value to be
+// deserialized intelligibly from JSON.
+func (v *Value) UnmarshalJSON(text []byte) error {
+	// Implement the noop convention
+	if string(text) == "null" {
+		return nil
+	}
+
+	vm := &valueMarshaled{}
+	if err := json.Unmarshal(text, vm); err != nil {
+		return err
+	}
+	vm.toValue(v)
+
+	return nil
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeit
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestValueUpdate(t *testing.T) {
+	r := &Value{}
+
+	r.Update(10)
+	r.Update(30)
+	r.Update(20)
+
+	assert.Equal(t, &Value{Samples: 3, Mean: 20, Max: 30, Min: 10, m2: 200}, r)
+}
+
+func TestValueUpdateOverflow(t *testing.T) {
+	r := &Value{Samples: math.MaxInt64, Mean: 20, Max: 30, Min: 10}
+
+	r.Update(40)
+
+	assert.Equal(t, &Value{Samples: math.MaxInt64, Mean: 20, Max: 40, Min: 10}, r)
+}
+
+func TestValueMergeBase(t *testing.T) {
+	r := &Value{}
+	r.Update(10)
+	r.Update(30)
+	other := &Value{}
+	other.Update(20)
+	other.Update(40)
+	expected := &Value{}
+	for _, rate := range []float64{10, 30, 20, 40} {
+		expected.Update(rate)
+	}
+
+	r.Merge(other)
+
+	assert.Equal(t, expected.Samples, r.Samples)
+	assert.InDelta(t, expected.Mean, r.Mean, 1e-9)
+	assert.Equal(t, expected.Max, r.Max)
+	assert.Equal(t, expected.Min, r.Min)
+	assert.InDelta(t, expected.m2, r.m2, 1e-9)
+}
+
+func TestValueMergeEmptyOther(t *testing.T) {
+	r := &Value{Samples: 1, Mean: 10, Max: 10, Min: 10}
+
+	r.Merge(&Value{})
+
+	assert.Equal(t, &Value{Samples: 1, Mean: 10, Max: 10, Min: 10}, r)
+}
+
+func TestValueMergeEmpty(t *testing.T) {
+	r := &Value{}
+
+	r.Merge(&Value{Samples: 2, Mean: 20, Max: 30, Min: 10, m2: 200})
+
+	assert.Equal(t, &Value{Samples: 2, Mean: 20, Max: 30, Min: 10, m2: 200}, r)
+}
+
+func TestValueMergeSaturate(t *testing.T) {
+	r := &Value{Samples: math.MaxInt64, Mean: 10, Max: 10, Min: 10}
+
+	r.Merge(&Value{Samples: 2, Mean: 10, Max: 20, Min: 5})
+
+	assert.Equal(t, int64(math.MaxInt64), r.Samples)
+	assert.Equal(t, 20.0, r.Max)
+	assert.Equal(t, 5.0, r.Min)
+}
+
+func TestValueSampleStdDevBase(t *testing.T) {
+	r := &Value{Samples: 3, Mean: 20, Max: 30, Min: 10, m2: 200}
+
+	assert.Equal(t, 10.0, r.SampleStdDev())
+}
+
+func TestValueSampleStdDevOneSample(t *testing.T) {
+	r := &Value{Samples: 1, Mean: 20, Max: 20, Min: 20}
+
+	assert.Equal(t, 0.0, r.SampleStdDev())
+}
+
+func TestValueVariance(t *testing.T) {
+	v := &Value{Samples: 4, Mean: 5, Max: 8, Min: 2, m2: 20}
+
+	assert.Equal(t, 5.0, v.Variance())
+	assert.InDelta(t, 20.0/3, v.SampleVariance(), 1e-9)
+	assert.InDelta(t, math.Sqrt(5), v.StdDev(), 1e-9)
+	assert.InDelta(t, math.Sqrt(20.0/3), v.SampleStdDev(), 1e-9)
+}
+
+func TestValueVarianceEmpty(t *testing.T) {
+	v := &Value{}
+
+	assert.Equal(t, 0.0, v.Variance())
+	assert.Equal(t, 0.0, v.SampleVariance())
+	assert.Equal(t, 0.0, v.StdDev())
+}
+
+func TestValueMergeEmptyKeepsFlags(t *testing.T) {
+	v := &Value{Flags: StdDev}
+
+	v.Merge(&Value{Samples: 1, Mean: 3, Max: 3, Min: 3, Flags: Variance})
+
+	assert.Equal(t, &Value{Samples: 1, Mean: 3, Max: 3, Min: 3, Flags: StdDev}, v)
+}
+
+func TestValueMarshalJSONBase(t *testing.T) {
+	v := &Value{Samples: 3, Mean: 50, Max: 75, Min: 25, m2: 1250}
+
+	result, err := json.Marshal(v)
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"samples": 3, "mean": 50, "max": 75, "min": 25, "variance": 416.6666666666667, "sample_variance": 625, "std_dev": 20.412414523193153, "sample_std_dev": 25}`, string(result))
+}
+
+func TestValueMarshalJSONFlags(t *testing.T) {
+	v := &Value{Samples: 3, Mean: 50, Max: 75, Min: 25, Flags: SampleStdDev, m2: 1250}
+
+	result, err := json.Marshal(v)
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"samples": 3, "mean": 50, "max": 75, "min": 25, "sample_std_dev": 25}`, string(result))
+}
+
+func TestValueUnmarshalJSONBase(t *testing.T) {
+	result := &Value{}
+
+	err := json.Unmarshal([]byte(`{"samples": 3, "mean": 50, "max": 75, "min": 25, "sample_std_dev": 25}`), result)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Value{Samples: 3, Mean: 50, Max: 75, Min: 25, Flags: SampleStdDev, m2: 1250}, result)
+}
+
+func TestValueUnmarshalJSONAllFields(t *testing.T) {
+	result := &Value{}
+
+	err := json.Unmarshal([]byte(`{"samples": 3, "mean": 50, "max": 75, "min": 25, "variance": 400, "sample_variance": 625, "std_dev": 20, "sample_std_dev": 25}`), result)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Value{Samples: 3, Mean: 50, Max: 75, Min: 25, Flags: Variance | SampleVariance | StdDev | SampleStdDev, m2: 1200}, result)
+}
+
+func TestValueUnmarshalJSONNull(t *testing.T) {
+	result := &Value{Samples: 1}
+
+	err := json.Unmarshal([]byte(`null`), result)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Value{Samples: 1}, result)
+}
+
+func TestValueUnmarshalJSONError(t *testing.T) {
+	result := &Value{}
+
+	err := result.UnmarshalJSON([]byte(`{"samples": "three"}`))
+
+	assert.Error(t, err)
+}
+
+func TestValueMarshalYAML(t *testing.T) {
+	v := &Value{Samples: 2, Mean: 1.5, Max: 2, Min: 1, Flags: Variance, m2: 0.5}
+
+	result, err := yaml.Marshal(v)
+
+	require.NoError(t, err)
+	assert.Equal(t, `samples: 2
+mean: 1.5
+max: 2
+min: 1
+variance: 0.25
+`, string(result))
+}
+
+func TestValueUnmarshalYAMLBase(t *testing.T) {
+	result := &Value{}
+
+	err := yaml.Unmarshal([]byte("samples: 2\nmean: 1.5\nmax: 2\nmin: 1\nvariance: 0.25\n"), result)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Value{Samples: 2, Mean: 1.5, Max: 2, Min: 1, Flags: Variance, m2: 0.5}, result)
+}
+
+func TestValueUnmarshalYAMLError(t *testing.T) {
+	result := &Value{}
+
+	err := yaml.Unmarshal([]byte("samples: three\n"), result)
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2020 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package timeit
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVCSSettings(t *testing.T) {
+	revision, modified := vcsSettings([]debug.BuildSetting{
+		{Key: "-compiler", Value: "gc"},
+		{Key: "vcs.revision", Value: "1234abcd"},
+		{Key: "vcs.modified", Value: "true"},
+	})
+
+	assert.Equal(t, "1234abcd", revision)
+	assert.True(t, modified)
+}